@@ -24,12 +24,32 @@ export namespace LSPClient {
     }),
   )
 
+  const Position = z.object({
+    line: z.number(),
+    character: z.number(),
+  })
+
+  export const DiagnosticInfo = z
+    .object({
+      range: z.object({
+        start: Position,
+        end: Position,
+      }),
+      severity: z.number().optional(),
+      source: z.string().optional(),
+      message: z.string(),
+    })
+    .openapi({
+      ref: "Diagnostic",
+    })
+
   export const Event = {
     Diagnostics: Bus.event(
       "lsp.client.diagnostics",
       z.object({
         serverID: z.string(),
         path: z.string(),
+        diagnostics: z.array(DiagnosticInfo),
       }),
     ),
   }
@@ -53,7 +73,16 @@ export namespace LSPClient {
       const exists = diagnostics.has(path)
       diagnostics.set(path, params.diagnostics)
       if (!exists && input.serverID === "typescript") return
-      Bus.publish(Event.Diagnostics, { path, serverID: input.serverID })
+      Bus.publish(Event.Diagnostics, {
+        path,
+        serverID: input.serverID,
+        diagnostics: params.diagnostics.map((diagnostic) => ({
+          range: diagnostic.range,
+          severity: diagnostic.severity,
+          source: diagnostic.source,
+          message: diagnostic.message,
+        })),
+      })
     })
     connection.onRequest("window/workDoneProgress/create", (params) => {
       l.info("window/workDoneProgress/create", params)
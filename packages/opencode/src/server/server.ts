@@ -17,6 +17,7 @@ import { File } from "../file"
 import { LSP } from "../lsp"
 import { MessageV2 } from "../session/message-v2"
 import { Mode } from "../session/mode"
+import { Permission } from "../permission"
 
 const ERRORS = {
   400: {
@@ -219,6 +220,69 @@ export namespace Server {
           return c.json(session)
         },
       )
+      .get(
+        "/session/:id",
+        describeRoute({
+          description: "Get a session",
+          responses: {
+            ...ERRORS,
+            200: {
+              description: "Session info",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        async (c) => {
+          const session = await Session.get(c.req.valid("param").id)
+          return c.json(session)
+        },
+      )
+      .patch(
+        "/session/:id",
+        describeRoute({
+          description: "Update session properties",
+          responses: {
+            200: {
+              description: "Successfully updated session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            title: z.string().optional(),
+          }),
+        ),
+        async (c) => {
+          const sessionID = c.req.valid("param").id
+          const updates = c.req.valid("json")
+          const session = await Session.update(sessionID, (session) => {
+            if (updates.title !== undefined) session.title = updates.title
+          })
+          return c.json(session)
+        },
+      )
       .delete(
         "/session/:id",
         describeRoute({
@@ -306,6 +370,44 @@ export namespace Server {
           return c.json(Session.abort(c.req.valid("param").id))
         },
       )
+      .post(
+        "/session/:id/permissions/:permissionID",
+        describeRoute({
+          description: "Respond to a pending permission request",
+          responses: {
+            200: {
+              description: "Permission processed successfully",
+              content: {
+                "application/json": {
+                  schema: resolver(z.boolean()),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string(),
+            permissionID: z.string(),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            response: z.enum(["once", "always", "reject"]),
+          }),
+        ),
+        async (c) => {
+          const params = c.req.valid("param")
+          Permission.respond({
+            sessionID: params.id,
+            permissionID: params.permissionID,
+            response: c.req.valid("json").response,
+          })
+          return c.json(true)
+        },
+      )
       .post(
         "/session/:id/share",
         describeRoute({
@@ -397,6 +499,104 @@ export namespace Server {
           return c.json(true)
         },
       )
+      .post(
+        "/session/:id/revert",
+        describeRoute({
+          description: "Revert the session to before a message, restoring files from its snapshot",
+          responses: {
+            200: {
+              description: "Reverted session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string().openapi({ description: "Session ID" }),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            messageID: z.string(),
+            part: z.number(),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id
+          const body = c.req.valid("json")
+          await Session.revert({ ...body, sessionID: id })
+          const session = await Session.get(id)
+          return c.json(session)
+        },
+      )
+      .post(
+        "/session/:id/fork",
+        describeRoute({
+          description: "Create a new session with the messages up to and including a message",
+          responses: {
+            200: {
+              description: "Forked session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string().openapi({ description: "Session ID" }),
+          }),
+        ),
+        zValidator(
+          "json",
+          z.object({
+            messageID: z.string(),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id
+          const body = c.req.valid("json")
+          const session = await Session.fork({ ...body, sessionID: id })
+          return c.json(session)
+        },
+      )
+      .post(
+        "/session/:id/unrevert",
+        describeRoute({
+          description: "Undo a revert and restore the files it replaced",
+          responses: {
+            200: {
+              description: "Unreverted session",
+              content: {
+                "application/json": {
+                  schema: resolver(Session.Info),
+                },
+              },
+            },
+          },
+        }),
+        zValidator(
+          "param",
+          z.object({
+            id: z.string().openapi({ description: "Session ID" }),
+          }),
+        ),
+        async (c) => {
+          const id = c.req.valid("param").id
+          await Session.unrevert(id)
+          const session = await Session.get(id)
+          return c.json(session)
+        },
+      )
       .get(
         "/session/:id/message",
         describeRoute({
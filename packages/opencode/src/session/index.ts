@@ -68,6 +68,12 @@ export namespace Session {
           snapshot: z.string().optional(),
         })
         .optional(),
+      fork: z
+        .object({
+          sessionID: z.string(),
+          messageID: z.string(),
+        })
+        .optional(),
     })
     .openapi({
       ref: "Session",
@@ -504,7 +510,8 @@ export namespace Session {
         synthetic: true,
       })
 
-    if (msgs.length === 0 && !session.parentID) {
+    // keep titles the user already chose
+    if (msgs.length === 0 && !session.parentID && session.title.startsWith("New Session - ")) {
       const small = (await Provider.getSmallModel(input.providerID)) ?? model
       generateText({
         maxOutputTokens: input.providerID === "google" ? 1024 : 20,
@@ -936,37 +943,47 @@ export namespace Session {
     }
   }
 
-  export async function revert(_input: { sessionID: string; messageID: string; part: number }) {
-    // TODO
-    /*
-    const message = await getMessage(input.sessionID, input.messageID)
-    if (!message) return
-    const part = message.parts[input.part]
-    if (!part) return
+  export async function revert(input: { sessionID: string; messageID: string; part: number }) {
     const session = await get(input.sessionID)
-    const snapshot =
-      session.revert?.snapshot ?? (await Snapshot.create(input.sessionID))
-    const old = (() => {
-      if (message.role === "assistant") {
-        const lastTool = message.parts.findLast(
-          (part, index) =>
-            part.type === "tool-invocation" && index < input.part,
-        )
-        if (lastTool && lastTool.type === "tool-invocation")
-          return message.metadata.tool[lastTool.toolInvocation.toolCallId]
-            .snapshot
-      }
-      return message.metadata.snapshot
-    })()
-    if (old) await Snapshot.restore(input.sessionID, old)
-    await update(input.sessionID, (draft) => {
+    if (!session) return
+    const msgs = await messages(input.sessionID)
+    // the first snapshot taken at or after the revert point holds the files as
+    // they were before anything from that point on touched them
+    const target = msgs
+      .filter((msg) => msg.info.id >= input.messageID)
+      .flatMap((msg) => (msg.info.id === input.messageID ? msg.parts.slice(input.part) : msg.parts))
+      .find((part): part is MessageV2.SnapshotPart => part.type === "snapshot")
+    const snapshot = session.revert?.snapshot ?? (await Snapshot.create(input.sessionID))
+    if (target) await Snapshot.restore(input.sessionID, target.snapshot)
+    return update(input.sessionID, (draft) => {
       draft.revert = {
         messageID: input.messageID,
         part: input.part,
         snapshot,
       }
     })
-    */
+  }
+
+  export async function fork(input: { sessionID: string; messageID: string }) {
+    const source = await get(input.sessionID)
+    const result = await create()
+    for (const msg of await messages(input.sessionID)) {
+      if (msg.info.id > input.messageID) break
+      const messageID = Identifier.ascending("message")
+      await updateMessage({ ...msg.info, id: messageID, sessionID: result.id })
+      for (const part of msg.parts) {
+        // snapshots live in the source session's snapshot repository
+        if (part.type === "snapshot") continue
+        await updatePart({ ...part, id: Identifier.ascending("part"), messageID, sessionID: result.id })
+      }
+    }
+    return update(result.id, (draft) => {
+      draft.title = "Fork of " + source.title
+      draft.fork = {
+        sessionID: input.sessionID,
+        messageID: input.messageID,
+      }
+    })
   }
 
   export async function unrevert(sessionID: string) {
@@ -974,7 +991,7 @@ export namespace Session {
     if (!session) return
     if (!session.revert) return
     if (session.revert.snapshot) await Snapshot.restore(sessionID, session.revert.snapshot)
-    update(sessionID, (draft) => {
+    return update(sessionID, (draft) => {
       draft.revert = undefined
     })
   }
@@ -18,6 +18,14 @@ export const TaskTool = Tool.define({
     const msg = await Session.getMessage(ctx.sessionID, ctx.messageID)
     if (msg.role !== "assistant") throw new Error("Not an assistant message")
 
+    ctx.metadata({
+      title: params.description,
+      metadata: {
+        sessionID: session.id,
+        summary: [],
+      },
+    })
+
     const messageID = Identifier.ascending("message")
     const parts: Record<string, MessageV2.ToolPart> = {}
     const unsub = Bus.subscribe(MessageV2.Event.PartUpdated, async (evt) => {
@@ -28,6 +36,7 @@ export const TaskTool = Tool.define({
       ctx.metadata({
         title: params.description,
         metadata: {
+          sessionID: session.id,
           summary: Object.values(parts).sort((a, b) => a.id?.localeCompare(b.id)),
         },
       })
@@ -55,6 +64,7 @@ export const TaskTool = Tool.define({
     return {
       title: params.description,
       metadata: {
+        sessionID: session.id,
         summary: result.parts.filter((x) => x.type === "tool"),
       },
       output: result.parts.findLast((x) => x.type === "text")!.text,
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -15,6 +17,7 @@ import (
 	"github.com/sst/opencode-sdk-go/option"
 	"github.com/sst/opencode/internal/app"
 	"github.com/sst/opencode/internal/clipboard"
+	"github.com/sst/opencode/internal/headless"
 	"github.com/sst/opencode/internal/tui"
 	"github.com/sst/opencode/internal/util"
 )
@@ -30,6 +33,8 @@ func main() {
 	var model *string = flag.String("model", "", "model to begin with")
 	var prompt *string = flag.String("prompt", "", "prompt to begin with")
 	var mode *string = flag.String("mode", "", "mode to begin with")
+	var headlessMode *bool = flag.Bool("headless", false, "run the prompt without the TUI and print the response")
+	var format *string = flag.String("format", "text", "output format in headless mode: text or json")
 	flag.Parse()
 
 	url := os.Getenv("OPENCODE_SERVER")
@@ -60,6 +65,10 @@ func main() {
 
 	slog.Debug("TUI launched", "app", appInfoStr, "modes", modesStr)
 
+	if *headlessMode {
+		os.Exit(runHeadless(version, appInfo, modes, httpClient, model, prompt, mode, *format))
+	}
+
 	go func() {
 		err = clipboard.Init()
 		if err != nil {
@@ -75,6 +84,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	app_.LoadPromptState()
 
 	program := tea.NewProgram(
 		tui.NewModel(app_),
@@ -87,17 +97,7 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
 
-	go func() {
-		stream := httpClient.Event.ListStreaming(ctx)
-		for stream.Next() {
-			evt := stream.Current().AsUnion()
-			program.Send(evt)
-		}
-		if err := stream.Err(); err != nil {
-			slog.Error("Error streaming events", "error", err)
-			program.Send(err)
-		}
-	}()
+	go app.SubscribeEvents(ctx, httpClient, program.Send)
 
 	// Handle signals in a separate goroutine
 	go func() {
@@ -111,6 +111,59 @@ func main() {
 	if err != nil {
 		slog.Error("TUI error", "error", err)
 	}
+	if result != nil {
+		tui.SaveDraft(result)
+	}
 
 	slog.Info("TUI exited", "result", result)
 }
+
+// runHeadless sends the prompt without starting the TUI and returns the
+// process exit code. The prompt is read from stdin when --prompt is empty.
+func runHeadless(
+	version string,
+	appInfo opencode.App,
+	modes []opencode.Mode,
+	httpClient *opencode.Client,
+	model *string,
+	prompt *string,
+	mode *string,
+	format string,
+) int {
+	outputFormat, err := headless.ParseFormat(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	text := *prompt
+	if text == "" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to read prompt:", err)
+			return 2
+		}
+		text = strings.TrimSpace(string(input))
+	}
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "a prompt is required in headless mode, pass --prompt or write it to stdin")
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	headlessApp, err := app.New(ctx, version, appInfo, modes, httpClient, model, nil, mode)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	err = headless.Run(ctx, headlessApp, text, outputFormat, os.Stdout)
+	if err != nil {
+		slog.Error("Headless run failed", "error", err)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
@@ -27,6 +27,16 @@ type Message struct {
 	Parts []opencode.PartUnion
 }
 
+func (m Message) ID() string {
+	switch casted := m.Info.(type) {
+	case opencode.UserMessage:
+		return casted.ID
+	case opencode.AssistantMessage:
+		return casted.ID
+	}
+	return ""
+}
+
 type App struct {
 	Info             opencode.App
 	Modes            []opencode.Mode
@@ -36,18 +46,34 @@ type App struct {
 	Config           *opencode.Config
 	Client           *opencode.Client
 	State            *config.State
+	History          *config.PromptHistory
 	ModeIndex        int
 	Mode             *opencode.Mode
 	Provider         *opencode.Provider
 	Model            *opencode.Model
 	Session          *opencode.Session
 	Messages         []Message
+	ImportedFrom     string
+	ForkSource       *opencode.Session
+	Permissions      []Permission
+	Queues           map[string]*PromptQueue
+	Tabs             []*SessionTab
+	Comparison       *Comparison
+	Diagnostics      *Diagnostics
+	SessionIndex     *SessionIndex
 	Commands         commands.CommandRegistry
 	InitialModel     *string
 	InitialPrompt    *string
 	IntitialMode     *string
 	compactCancel    context.CancelFunc
+	compactID        int
+	compaction       *Compaction
+	contextWarned    string
+	notifiedError    string
 	IsLeaderSequence bool
+	// Unfocused is set while the terminal reports it lost focus, during
+	// which finished sessions are notified
+	Unfocused bool
 }
 
 type SessionCreatedMsg = struct {
@@ -64,6 +90,9 @@ type CompactSessionMsg struct{}
 type SendMsg struct {
 	Text        string
 	Attachments []opencode.FilePartParam
+	// Revert is the prompt this message replaces. The session is reverted
+	// to before it first.
+	Revert string
 }
 type SetEditorContentMsg struct {
 	Text string
@@ -168,6 +197,9 @@ func New(
 		Mode:          mode,
 		Session:       &opencode.Session{},
 		Messages:      []Message{},
+		Permissions:   []Permission{},
+		Diagnostics:   NewDiagnostics(),
+		SessionIndex:  NewSessionIndex(),
 		Commands:      commands.LoadFromConfig(configInfo),
 		InitialModel:  initialModel,
 		InitialPrompt: initialPrompt,
@@ -177,6 +209,22 @@ func New(
 	return app, nil
 }
 
+// LoadPromptState loads the prompt history of the project and purges old
+// drafts. Only the TUI keeps either, so headless runs leave them alone.
+func (a *App) LoadPromptState() {
+	history, err := config.LoadPromptHistory(a.Info.Path.State, a.Info.Path.Root)
+	if err != nil {
+		slog.Warn("Failed to load prompt history", "error", err)
+	}
+	a.History = history
+
+	if purged, err := config.PurgeDrafts(a.Info.Path.State, a.State.DraftMaxAge()); err != nil {
+		slog.Warn("Failed to purge drafts", "error", err)
+	} else if purged > 0 {
+		slog.Debug("Purged old drafts", "count", purged)
+	}
+}
+
 func (a *App) Key(commandName commands.CommandName) string {
 	t := theme.CurrentTheme()
 	base := styles.NewStyle().Background(t.Background()).Foreground(t.Text()).Bold(true).Render
@@ -186,12 +234,21 @@ func (a *App) Key(commandName commands.CommandName) string {
 		Faint(true).
 		Render
 	command := a.Commands[commandName]
+	return base(a.Keybinding(commandName)) + muted(" "+command.Description)
+}
+
+// Keybinding returns the first key bound to a command as typed, including
+// the leader key, or an empty string when the command has no binding
+func (a *App) Keybinding(commandName commands.CommandName) string {
+	command := a.Commands[commandName]
+	if len(command.Keybindings) == 0 {
+		return ""
+	}
 	kb := command.Keybindings[0]
-	key := kb.Key
 	if kb.RequiresLeader {
-		key = a.Config.Keybinds.Leader + " " + kb.Key
+		return a.Config.Keybinds.Leader + " " + kb.Key
 	}
-	return base(key) + muted(" "+command.Description)
+	return kb.Key
 }
 
 func (a *App) SetClipboard(text string) tea.Cmd {
@@ -260,12 +317,32 @@ func (a *App) SwitchModeReverse() (*App, tea.Cmd) {
 }
 
 func (a *App) InitializeProvider() tea.Cmd {
-	providersResponse, err := a.Client.App.Providers(context.Background())
+	currentProvider, currentModel, err := a.ResolveModel(context.Background())
 	if err != nil {
-		slog.Error("Failed to list providers", "error", err)
+		slog.Error("Failed to resolve model", "error", err)
 		// TODO: notify user
 		return nil
 	}
+
+	var cmds []tea.Cmd
+	cmds = append(cmds, util.CmdHandler(ModelSelectedMsg{
+		Provider: *currentProvider,
+		Model:    *currentModel,
+	}))
+	if a.InitialPrompt != nil && *a.InitialPrompt != "" {
+		cmds = append(cmds, util.CmdHandler(SendMsg{Text: *a.InitialPrompt}))
+	}
+	return tea.Sequence(cmds...)
+}
+
+// ResolveModel loads the configured providers and picks the model to start
+// with: the --model flag, then the model last used in the current mode, then
+// the server default
+func (a *App) ResolveModel(ctx context.Context) (*opencode.Provider, *opencode.Model, error) {
+	providersResponse, err := a.Client.App.Providers(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list providers: %w", err)
+	}
 	providers := providersResponse.Providers
 	var defaultProvider *opencode.Provider
 	var defaultModel *opencode.Model
@@ -291,8 +368,7 @@ func (a *App) InitializeProvider() tea.Cmd {
 		providers = append(providers, provider)
 	}
 	if len(providers) == 0 {
-		slog.Error("No providers configured")
-		return nil
+		return nil, nil, fmt.Errorf("no providers configured")
 	}
 
 	a.Providers = providers
@@ -343,15 +419,7 @@ func (a *App) InitializeProvider() tea.Cmd {
 		currentModel = initialModel
 	}
 
-	var cmds []tea.Cmd
-	cmds = append(cmds, util.CmdHandler(ModelSelectedMsg{
-		Provider: *currentProvider,
-		Model:    *currentModel,
-	}))
-	if a.InitialPrompt != nil && *a.InitialPrompt != "" {
-		cmds = append(cmds, util.CmdHandler(SendMsg{Text: *a.InitialPrompt}))
-	}
-	return tea.Sequence(cmds...)
+	return currentProvider, currentModel, nil
 }
 
 func getDefaultModel(
@@ -370,11 +438,16 @@ func getDefaultModel(
 }
 
 func (a *App) IsBusy() bool {
-	if len(a.Messages) == 0 {
+	return isBusy(a.Messages)
+}
+
+// isBusy reports whether the agent is still answering the last message
+func isBusy(messages []Message) bool {
+	if len(messages) == 0 {
 		return false
 	}
 
-	lastMessage := a.Messages[len(a.Messages)-1]
+	lastMessage := messages[len(messages)-1]
 	if casted, ok := lastMessage.Info.(opencode.AssistantMessage); ok {
 		return casted.Time.Completed == 0
 	}
@@ -415,34 +488,40 @@ func (a *App) InitializeProject(ctx context.Context) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-func (a *App) CompactSession(ctx context.Context) tea.Cmd {
+// CompactSession summarizes the session on the server, replacing its
+// context with the summary. The returned command reports the outcome with a
+// SessionCompactedMsg; compaction runs until then and can be cancelled
+// through Cancel.
+func (a *App) CompactSession(ctx context.Context, auto bool) tea.Cmd {
 	if a.compactCancel != nil {
 		a.compactCancel()
 	}
 
 	compactCtx, cancel := context.WithCancel(ctx)
 	a.compactCancel = cancel
-
-	go func() {
-		defer func() {
-			a.compactCancel = nil
-		}()
-
-		_, err := a.Client.Session.Summarize(
-			compactCtx,
-			a.Session.ID,
-			opencode.SessionSummarizeParams{
-				ProviderID: opencode.F(a.Provider.ID),
-				ModelID:    opencode.F(a.Model.ID),
-			},
-		)
-		if err != nil {
-			if compactCtx.Err() != context.Canceled {
-				slog.Error("Failed to compact session", "error", err)
-			}
+	a.compactID++
+	a.compaction = &Compaction{
+		SessionID: a.Session.ID,
+		Started:   time.Now(),
+		Auto:      auto,
+	}
+
+	compactID := a.compactID
+	sessionID := a.Session.ID
+	params := opencode.SessionSummarizeParams{
+		ProviderID: opencode.F(a.Provider.ID),
+		ModelID:    opencode.F(a.Model.ID),
+	}
+	return tea.Batch(a.CompactionTick(CompactionTickMsg{id: compactID}), func() tea.Msg {
+		_, err := a.Client.Session.Summarize(compactCtx, sessionID, params)
+		if compactCtx.Err() == context.Canceled {
+			err = context.Canceled
+		} else if err != nil {
+			slog.Error("Failed to compact session", "error", err)
 		}
-	}()
-	return nil
+		cancel()
+		return SessionCompactedMsg{SessionID: sessionID, Auto: auto, Err: err, id: compactID}
+	})
 }
 
 func (a *App) MarkProjectInitialized(ctx context.Context) error {
@@ -477,9 +556,51 @@ func (a *App) SendChatMessage(
 		cmds = append(cmds, util.CmdHandler(SessionCreatedMsg{Session: session}))
 	}
 
+	message := a.newUserMessage(text, attachments)
+	a.Messages = append(a.Messages, message)
+	cmds = append(cmds, util.CmdHandler(OptimisticMessageAddedMsg{Message: message.Info}))
+
+	cmds = append(cmds, func() tea.Msg {
+		err := a.chat(ctx, message)
+		if err != nil {
+			errormsg := fmt.Sprintf("failed to send message: %v", err)
+			slog.Error(errormsg)
+			return toast.NewErrorToast(errormsg)()
+		}
+		return nil
+	})
+
+	// The actual response will come through SSE
+	// For now, just return success
+	return a, tea.Batch(cmds...)
+}
+
+// NewPrompt builds the user message sending a prompt to the current session.
+// Its ID tells the prompt apart from the messages answering it.
+func (a *App) NewPrompt(text string, attachments []opencode.FilePartParam) Message {
+	return a.newUserMessage(text, attachments)
+}
+
+// Prompt sends a message built by NewPrompt to the current session and
+// blocks until the server has finished responding. The response itself is
+// delivered through the event stream.
+func (a *App) Prompt(ctx context.Context, message Message) error {
+	a.Messages = append(a.Messages, message)
+	if err := a.chat(ctx, message); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+func (a *App) newUserMessage(text string, attachments []opencode.FilePartParam) Message {
+	return newPromptMessage(a.Session.ID, text, attachments)
+}
+
+// newPromptMessage builds the user message sending a prompt to a session
+func newPromptMessage(sessionID string, text string, attachments []opencode.FilePartParam) Message {
 	message := opencode.UserMessage{
 		ID:        id.Ascending(id.Message),
-		SessionID: a.Session.ID,
+		SessionID: sessionID,
 		Role:      opencode.UserMessageRoleUser,
 		Time: opencode.UserMessageTime{
 			Created: float64(time.Now().UnixMilli()),
@@ -489,7 +610,7 @@ func (a *App) SendChatMessage(
 	parts := []opencode.PartUnion{opencode.TextPart{
 		ID:        id.Ascending(id.Part),
 		MessageID: message.ID,
-		SessionID: a.Session.ID,
+		SessionID: sessionID,
 		Type:      opencode.TextPartTypeText,
 		Text:      text,
 	}}
@@ -498,7 +619,7 @@ func (a *App) SendChatMessage(
 			parts = append(parts, opencode.FilePart{
 				ID:        id.Ascending(id.Part),
 				MessageID: message.ID,
-				SessionID: a.Session.ID,
+				SessionID: sessionID,
 				Type:      opencode.FilePartTypeFile,
 				Filename:  attachment.Filename.Value,
 				Mime:      attachment.Mime.Value,
@@ -506,58 +627,57 @@ func (a *App) SendChatMessage(
 			})
 		}
 	}
+	return Message{Info: message, Parts: parts}
+}
 
-	a.Messages = append(a.Messages, Message{Info: message, Parts: parts})
-	cmds = append(cmds, util.CmdHandler(OptimisticMessageAddedMsg{Message: message}))
+func (a *App) chat(ctx context.Context, message Message) error {
+	return a.chatWith(ctx, a.Provider.ID, a.Model.ID, message)
+}
 
-	cmds = append(cmds, func() tea.Msg {
-		partsParam := []opencode.SessionChatParamsPartUnion{}
-		for _, part := range parts {
-			switch casted := part.(type) {
-			case opencode.TextPart:
-				partsParam = append(partsParam, opencode.TextPartParam{
-					ID:        opencode.F(casted.ID),
-					MessageID: opencode.F(casted.MessageID),
-					SessionID: opencode.F(casted.SessionID),
-					Type:      opencode.F(casted.Type),
-					Text:      opencode.F(casted.Text),
-				})
-			case opencode.FilePart:
-				partsParam = append(partsParam, opencode.FilePartParam{
-					ID:        opencode.F(casted.ID),
-					Mime:      opencode.F(casted.Mime),
-					MessageID: opencode.F(casted.MessageID),
-					SessionID: opencode.F(casted.SessionID),
-					Type:      opencode.F(casted.Type),
-					URL:       opencode.F(casted.URL),
-					Filename:  opencode.F(casted.Filename),
-				})
-			}
+// chatWith sends a user message to its session, answered by the given model
+func (a *App) chatWith(ctx context.Context, providerID, modelID string, message Message) error {
+	sessionID := ""
+	if user, ok := message.Info.(opencode.UserMessage); ok {
+		sessionID = user.SessionID
+	}
+	partsParam := []opencode.SessionChatParamsPartUnion{}
+	for _, part := range message.Parts {
+		switch casted := part.(type) {
+		case opencode.TextPart:
+			partsParam = append(partsParam, opencode.TextPartParam{
+				ID:        opencode.F(casted.ID),
+				MessageID: opencode.F(casted.MessageID),
+				SessionID: opencode.F(casted.SessionID),
+				Type:      opencode.F(casted.Type),
+				Text:      opencode.F(casted.Text),
+				Synthetic: opencode.F(casted.Synthetic),
+			})
+		case opencode.FilePart:
+			partsParam = append(partsParam, opencode.FilePartParam{
+				ID:        opencode.F(casted.ID),
+				Mime:      opencode.F(casted.Mime),
+				MessageID: opencode.F(casted.MessageID),
+				SessionID: opencode.F(casted.SessionID),
+				Type:      opencode.F(casted.Type),
+				URL:       opencode.F(casted.URL),
+				Filename:  opencode.F(casted.Filename),
+			})
 		}
+	}
 
-		_, err := a.Client.Session.Chat(ctx, a.Session.ID, opencode.SessionChatParams{
-			Parts:      opencode.F(partsParam),
-			MessageID:  opencode.F(message.ID),
-			ProviderID: opencode.F(a.Provider.ID),
-			ModelID:    opencode.F(a.Model.ID),
-			Mode:       opencode.F(a.Mode.Name),
-		})
-		if err != nil {
-			errormsg := fmt.Sprintf("failed to send message: %v", err)
-			slog.Error(errormsg)
-			return toast.NewErrorToast(errormsg)()
-		}
-		return nil
+	_, err := a.Client.Session.Chat(ctx, sessionID, opencode.SessionChatParams{
+		Parts:      opencode.F(partsParam),
+		MessageID:  opencode.F(message.ID()),
+		ProviderID: opencode.F(providerID),
+		ModelID:    opencode.F(modelID),
+		Mode:       opencode.F(a.Mode.Name),
 	})
-
-	// The actual response will come through SSE
-	// For now, just return success
-	return a, tea.Batch(cmds...)
+	return err
 }
 
 func (a *App) Cancel(ctx context.Context, sessionID string) error {
-	// Cancel any running compact operation
-	if a.compactCancel != nil {
+	// Cancel any running compact operation of the session
+	if a.compactCancel != nil && a.compaction != nil && a.compaction.SessionID == sessionID {
 		a.compactCancel()
 		a.compactCancel = nil
 	}
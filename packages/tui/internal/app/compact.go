@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// Compaction describes the session summary in progress
+type Compaction struct {
+	SessionID string
+	Started   time.Time
+	// Auto is set when the summary was started by the auto-compact policy
+	Auto bool
+}
+
+// SessionCompactedMsg is sent when a compaction started by CompactSession
+// ends. Err is context.Canceled when it was cancelled.
+type SessionCompactedMsg struct {
+	SessionID string
+	Auto      bool
+	Err       error
+	id        int
+}
+
+// CompactionTickMsg redraws the elapsed time of a running compaction
+type CompactionTickMsg struct {
+	id int
+}
+
+// Compaction returns the compaction in progress in any session, or nil
+func (a *App) Compaction() *Compaction {
+	return a.compaction
+}
+
+// IsCompacting reports whether the current session is being summarized,
+// during which new prompts are held back
+func (a *App) IsCompacting() bool {
+	return a.compaction != nil && a.compaction.SessionID == a.Session.ID
+}
+
+// CompactionTick schedules the next redraw of the compaction started as id,
+// or returns nil once it has ended
+func (a *App) CompactionTick(msg CompactionTickMsg) tea.Cmd {
+	if a.compaction == nil || msg.id != a.compactID {
+		return nil
+	}
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return msg
+	})
+}
+
+// FinishCompaction clears the compaction reported by msg and returns the
+// toast describing its outcome. Reports of compactions replaced by a newer
+// one are ignored.
+func (a *App) FinishCompaction(msg SessionCompactedMsg) tea.Cmd {
+	if msg.id != a.compactID {
+		return nil
+	}
+	a.compaction = nil
+	a.compactCancel = nil
+	switch {
+	case errors.Is(msg.Err, context.Canceled):
+		return toast.NewInfoToast("Compaction cancelled")
+	case msg.Err != nil:
+		return toast.NewErrorToast("Failed to compact session: " + msg.Err.Error())
+	case msg.Auto:
+		return toast.NewSuccessToast(
+			"The context window was summarized to make room",
+			toast.WithTitle("Session compacted"),
+		)
+	default:
+		return toast.NewSuccessToast("Session compacted")
+	}
+}
+
+// CheckAutoCompact starts a compaction when auto-compact is enabled and the
+// conversation uses more than the auto_compact_threshold share of the
+// context window. It runs once the agent is idle, after a message completes.
+func (a *App) CheckAutoCompact() tea.Cmd {
+	if !a.State.AutoCompact || a.compaction != nil || a.IsBusy() || a.ReadOnly() ||
+		a.Session.ID == "" || a.Provider == nil || a.Model == nil {
+		return nil
+	}
+	usage := Usage(a.Messages)
+	if len(usage.Messages) == 0 || usage.Messages[len(usage.Messages)-1].Summary {
+		return nil
+	}
+	share := a.ContextShare(usage)
+	if share < a.State.AutoCompactShare() {
+		return nil
+	}
+	return tea.Batch(
+		toast.NewInfoToast(fmt.Sprintf("%.0f%% of the context window is used, summarizing the session", share*100)),
+		a.CompactSession(context.Background(), true),
+	)
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func TestAutoCompact(t *testing.T) {
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatalf("Failed to load themes: %v", err)
+	}
+	theme.SetTheme("opencode")
+	a := &App{
+		Session:  &opencode.Session{ID: "ses_1"},
+		State:    &config.State{AutoCompactThreshold: 0.5},
+		Provider: &opencode.Provider{ID: "provider"},
+		Model:    &opencode.Model{ID: "model", Limit: opencode.ModelLimit{Context: 1000}},
+		Messages: []Message{{Info: opencode.AssistantMessage{
+			Tokens: opencode.AssistantMessageTokens{Input: 600, Output: 10},
+			Time:   opencode.AssistantMessageTime{Completed: 1},
+		}}},
+	}
+
+	if a.CheckAutoCompact() != nil || a.IsCompacting() {
+		t.Fatal("auto-compact should be opt-in")
+	}
+	a.State.AutoCompact = true
+	if a.CheckAutoCompact() == nil || !a.IsCompacting() || !a.Compaction().Auto {
+		t.Fatal("expected a compaction past the threshold")
+	}
+	if a.CheckAutoCompact() != nil {
+		t.Error("expected a single compaction at a time")
+	}
+
+	if a.CompactionTick(CompactionTickMsg{id: a.compactID}) == nil {
+		t.Error("expected the elapsed time to be redrawn while compacting")
+	}
+	a.Session = &opencode.Session{ID: "ses_2"}
+	if a.IsCompacting() {
+		t.Error("compacting one session should not hold back prompts in another")
+	}
+	a.Session = &opencode.Session{ID: "ses_1"}
+
+	stale := SessionCompactedMsg{SessionID: "ses_1", Err: context.Canceled, id: a.compactID - 1}
+	if a.FinishCompaction(stale); !a.IsCompacting() {
+		t.Error("a replaced compaction should not end the current one")
+	}
+	if a.FinishCompaction(SessionCompactedMsg{SessionID: "ses_1", Auto: true, id: a.compactID}) == nil || a.IsCompacting() {
+		t.Error("expected the compaction to end with a toast")
+	}
+	if a.CompactionTick(CompactionTickMsg{id: a.compactID}) != nil {
+		t.Error("expected the redraws to stop with the compaction")
+	}
+
+	a.Messages = append(a.Messages, Message{Info: opencode.AssistantMessage{
+		Summary: true,
+		Tokens:  opencode.AssistantMessageTokens{Input: 600, Output: 100},
+		Time:    opencode.AssistantMessageTime{Completed: 2},
+	}})
+	if a.CheckAutoCompact() != nil {
+		t.Error("a summary should not trigger another compaction")
+	}
+}
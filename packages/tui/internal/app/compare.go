@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// CompareMsg sends a prompt to several models side by side
+type CompareMsg struct {
+	Prompt      string
+	Attachments []opencode.FilePartParam
+	Models      []ModelSelectedMsg
+}
+
+// ComparisonForkedMsg is sent once a session was forked for every model of
+// a comparison
+type ComparisonForkedMsg struct {
+	Comparison *Comparison
+	// Messages holds the messages copied into each fork, by session
+	Messages map[string][]Message
+}
+
+// Comparison sends one prompt to several models, each in a session forked
+// from the one it was started in. The forks are open as background tabs,
+// which keep their messages current.
+type Comparison struct {
+	// SourceID is the session the forks were made from
+	SourceID    string
+	Prompt      string
+	Attachments []opencode.FilePartParam
+	Runs        []*ComparisonRun
+}
+
+// ComparisonRun is the answer of one model to the compared prompt
+type ComparisonRun struct {
+	Provider opencode.Provider
+	Model    opencode.Model
+	Session  opencode.Session
+	// PromptID is the user message sending the prompt to the fork
+	PromptID string
+	Sent     time.Time
+}
+
+// ComparisonResult summarizes the answer of a run so far
+type ComparisonResult struct {
+	Text    string
+	Tokens  TokenUsage
+	Cost    float64
+	Latency time.Duration
+	Done    bool
+	Error   *ErrorInfo
+}
+
+// StartComparison forks the current session once per model, up to its last
+// message, or creates empty sessions when there is nothing to fork yet. When
+// a fork fails the ones already made are deleted again.
+func (a *App) StartComparison(ctx context.Context, msg CompareMsg) tea.Cmd {
+	sourceID := a.Session.ID
+	lastID := ""
+	if len(a.Messages) > 0 {
+		lastID = a.Messages[len(a.Messages)-1].ID()
+	}
+	return func() tea.Msg {
+		comparison := &Comparison{SourceID: sourceID, Prompt: msg.Prompt, Attachments: msg.Attachments}
+		forked := map[string][]Message{}
+		for _, model := range msg.Models {
+			var session *opencode.Session
+			var err error
+			if lastID == "" {
+				session, err = a.CreateSession(ctx)
+			} else {
+				session, err = a.Client.Session.Fork(ctx, sourceID, opencode.SessionForkParams{
+					MessageID: opencode.F(lastID),
+				})
+			}
+			if err != nil {
+				slog.Error("Failed to fork session for comparison", "model", model.Model.ID, "error", err)
+				for _, run := range comparison.Runs {
+					a.DeleteSession(ctx, run.Session.ID)
+				}
+				return toast.NewErrorToast("Failed to fork the session for " + model.Model.Name)()
+			}
+			if lastID != "" {
+				messages, err := a.ListMessages(ctx, session.ID)
+				if err != nil {
+					slog.Error("Failed to list messages of fork", "session", session.ID, "error", err)
+				}
+				forked[session.ID] = messages
+			}
+			comparison.Runs = append(comparison.Runs, &ComparisonRun{
+				Provider: model.Provider,
+				Model:    model.Model,
+				Session:  *session,
+			})
+		}
+		return ComparisonForkedMsg{Comparison: comparison, Messages: forked}
+	}
+}
+
+// BeginComparison opens a background tab for every fork of the comparison
+// and sends the prompt to each with its model
+func (a *App) BeginComparison(ctx context.Context, msg ComparisonForkedMsg) tea.Cmd {
+	comparison := msg.Comparison
+	a.Comparison = comparison
+	cmds := []tea.Cmd{}
+	for _, run := range comparison.Runs {
+		messages := slices.Clone(msg.Messages[run.Session.ID])
+		message := newPromptMessage(run.Session.ID, comparison.Prompt, comparison.Attachments)
+		run.PromptID = message.ID()
+		run.Sent = time.Now()
+		if a.tabIndex(run.Session.ID) == -1 {
+			a.Tabs = append(a.Tabs, &SessionTab{Session: run.Session})
+		}
+		tab := a.Tab(run.Session.ID)
+		tab.Messages = append(messages, message)
+
+		providerID, modelID := run.Provider.ID, run.Model.ID
+		cmds = append(cmds, func() tea.Msg {
+			if err := a.chatWith(ctx, providerID, modelID, message); err != nil {
+				slog.Error("Failed to send compared prompt", "model", modelID, "error", err)
+				return toast.NewErrorToast(fmt.Sprintf("Failed to send the prompt to %s: %v", modelID, err))()
+			}
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// Result summarizes the answer of a run from the messages of its tab. The
+// latency runs from sending the prompt to the last completed step.
+func (a *App) Result(run *ComparisonRun) ComparisonResult {
+	result := ComparisonResult{Latency: time.Since(run.Sent)}
+	messages := a.runMessages(run)
+	index := slices.IndexFunc(messages, func(message Message) bool {
+		return message.ID() == run.PromptID
+	})
+	if index == -1 {
+		return result
+	}
+	prompt, _ := messages[index].Info.(opencode.UserMessage)
+	answer := messages[index+1:]
+	usage := Usage(answer)
+	result.Tokens = usage.Tokens
+	result.Cost = usage.Cost
+
+	texts := []string{}
+	for _, message := range answer {
+		assistant, ok := message.Info.(opencode.AssistantMessage)
+		if !ok {
+			continue
+		}
+		if text := MessageText(message); text != "" {
+			texts = append(texts, text)
+		}
+		result.Error = MessageErrorInfo(assistant)
+		if assistant.Time.Completed > 0 {
+			result.Done = !isBusy(messages)
+			if prompt.Time.Created > 0 {
+				result.Latency = time.Duration(assistant.Time.Completed-prompt.Time.Created) * time.Millisecond
+			}
+		}
+	}
+	result.Text = strings.Join(texts, "\n\n")
+	if tab := a.Tab(run.Session.ID); tab != nil && tab.Error != nil && result.Error == nil {
+		result.Error = tab.Error
+		result.Done = true
+	}
+	return result
+}
+
+// runMessages returns the messages of a run, from its tab or from the
+// current session when the run is the one shown
+func (a *App) runMessages(run *ComparisonRun) []Message {
+	if a.Session.ID == run.Session.ID {
+		return a.Messages
+	}
+	if tab := a.Tab(run.Session.ID); tab != nil {
+		return tab.Messages
+	}
+	return nil
+}
+
+// AdoptComparison ends the comparison keeping the run at index as the
+// continuation. The other forks are closed but stay in the session list.
+// It returns the session to switch to.
+func (a *App) AdoptComparison(index int) *opencode.Session {
+	comparison := a.Comparison
+	if comparison == nil || index < 0 || index >= len(comparison.Runs) {
+		return nil
+	}
+	for i, run := range comparison.Runs {
+		if i != index {
+			a.CloseTab(run.Session.ID)
+		}
+	}
+	a.Comparison = nil
+	session := comparison.Runs[index].Session
+	if tab := a.Tab(session.ID); tab != nil {
+		session = tab.Session
+	}
+	return &session
+}
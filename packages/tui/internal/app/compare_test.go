@@ -0,0 +1,71 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+)
+
+func TestComparisonResults(t *testing.T) {
+	a := &App{State: &config.State{}}
+	a.ShowSession(&opencode.Session{ID: "ses_0"}, []Message{})
+	attachments := []opencode.FilePartParam{{
+		Type: opencode.F(opencode.FilePartTypeFile),
+		Mime: opencode.F("text/plain"),
+		URL:  opencode.F("file://./main_test.go"),
+	}}
+	comparison := &Comparison{SourceID: "ses_0", Prompt: "fix the tests", Attachments: attachments, Runs: []*ComparisonRun{
+		{Model: opencode.Model{ID: "a"}, Session: opencode.Session{ID: "ses_a"}},
+		{Model: opencode.Model{ID: "b"}, Session: opencode.Session{ID: "ses_b"}},
+	}}
+	a.BeginComparison(t.Context(), ComparisonForkedMsg{Comparison: comparison})
+	if len(a.Tabs) != 3 || a.Session.ID != "ses_0" {
+		t.Fatalf("expected a background tab per fork, got %d tabs", len(a.Tabs))
+	}
+
+	first := comparison.Runs[0]
+	tab := a.Tab("ses_a")
+	if len(tab.Messages) != 1 || tab.Messages[0].ID() != first.PromptID || MessageText(tab.Messages[0]) != "fix the tests" {
+		t.Fatalf("expected the prompt in the fork, got %+v", tab.Messages)
+	}
+	if parts := tab.Messages[0].Parts; len(parts) != 2 || parts[1].(opencode.FilePart).URL != "file://./main_test.go" {
+		t.Errorf("expected the prompt to keep its attachments, got %+v", parts)
+	}
+	if result := a.Result(first); result.Done || result.Text != "" {
+		t.Errorf("expected an unanswered run to be running, got %+v", result)
+	}
+
+	created := tab.Messages[0].Info.(opencode.UserMessage).Time.Created
+	tab.Messages = append(tab.Messages, Message{
+		Info: opencode.AssistantMessage{
+			ID:        "msg_2",
+			SessionID: "ses_a",
+			Cost:      0.5,
+			Time:      opencode.AssistantMessageTime{Created: created + 100, Completed: created + 2500},
+			Tokens:    opencode.AssistantMessageTokens{Input: 100, Output: 20},
+		},
+		Parts: []opencode.PartUnion{opencode.TextPart{ID: "prt_1", Text: "done"}},
+	})
+	result := a.Result(first)
+	if !result.Done || result.Text != "done" || result.Cost != 0.5 || result.Tokens.Output != 20 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if result.Latency != 2500*time.Millisecond {
+		t.Errorf("expected the latency from the prompt to completion, got %s", result.Latency)
+	}
+
+	a.UpdateTabs(decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_b","error":`+errorVariants[0].raw+`}}`))
+	if result := a.Result(comparison.Runs[1]); !result.Done || result.Error == nil {
+		t.Errorf("expected the failed run to report its error, got %+v", result)
+	}
+
+	session := a.AdoptComparison(1)
+	if session == nil || session.ID != "ses_b" || a.Comparison != nil {
+		t.Fatalf("expected the second fork to be adopted, got %+v", session)
+	}
+	if a.Tab("ses_a") != nil || a.Tab("ses_b") == nil {
+		t.Error("expected only the adopted fork to stay open")
+	}
+}
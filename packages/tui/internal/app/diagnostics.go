@@ -0,0 +1,112 @@
+package app
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+// DiagnosticSeverity follows the LSP severity levels, lower is more severe
+type DiagnosticSeverity int
+
+const (
+	DiagnosticSeverityError DiagnosticSeverity = iota + 1
+	DiagnosticSeverityWarning
+	DiagnosticSeverityInformation
+	DiagnosticSeverityHint
+)
+
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case DiagnosticSeverityError:
+		return "error"
+	case DiagnosticSeverityWarning:
+		return "warning"
+	case DiagnosticSeverityInformation:
+		return "info"
+	default:
+		return "hint"
+	}
+}
+
+// Severity of a diagnostic, servers that omit it are treated as reporting
+// errors as recommended by the LSP specification
+func Severity(diagnostic opencode.Diagnostic) DiagnosticSeverity {
+	if diagnostic.Severity == 0 {
+		return DiagnosticSeverityError
+	}
+	return DiagnosticSeverity(diagnostic.Severity)
+}
+
+type FileDiagnostic struct {
+	Path       string
+	Diagnostic opencode.Diagnostic
+}
+
+// Diagnostics tracks the latest diagnostics published by every language
+// server, per file
+type Diagnostics struct {
+	// files maps a path to the diagnostics of each server for it
+	files map[string]map[string][]opencode.Diagnostic
+}
+
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{files: map[string]map[string][]opencode.Diagnostic{}}
+}
+
+// Update replaces the diagnostics a server reported for a file
+func (d *Diagnostics) Update(event opencode.EventListResponseEventLspClientDiagnosticsProperties) {
+	servers, ok := d.files[event.Path]
+	if !ok {
+		servers = map[string][]opencode.Diagnostic{}
+		d.files[event.Path] = servers
+	}
+	if len(event.Diagnostics) == 0 {
+		delete(servers, event.ServerID)
+		if len(servers) == 0 {
+			delete(d.files, event.Path)
+		}
+		return
+	}
+	servers[event.ServerID] = event.Diagnostics
+}
+
+// List returns the diagnostics at or above the given severity, ordered by
+// file and position
+func (d *Diagnostics) List(severity DiagnosticSeverity) []FileDiagnostic {
+	result := []FileDiagnostic{}
+	for path, servers := range d.files {
+		for _, diagnostics := range servers {
+			for _, diagnostic := range diagnostics {
+				if Severity(diagnostic) > severity {
+					continue
+				}
+				result = append(result, FileDiagnostic{Path: path, Diagnostic: diagnostic})
+			}
+		}
+	}
+	slices.SortFunc(result, func(a, b FileDiagnostic) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Diagnostic.Range.Start.Line, b.Diagnostic.Range.Start.Line),
+			cmp.Compare(a.Diagnostic.Range.Start.Character, b.Diagnostic.Range.Start.Character),
+		)
+	})
+	return result
+}
+
+// Count returns the number of diagnostics with exactly the given severity
+func (d *Diagnostics) Count(severity DiagnosticSeverity) int {
+	count := 0
+	for _, servers := range d.files {
+		for _, diagnostics := range servers {
+			for _, diagnostic := range diagnostics {
+				if Severity(diagnostic) == severity {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func diagnostic(line float64, severity DiagnosticSeverity, message string) opencode.Diagnostic {
+	return opencode.Diagnostic{
+		Message:  message,
+		Severity: float64(severity),
+		Range: opencode.DiagnosticRange{
+			Start: opencode.DiagnosticRangeStart{Line: line},
+			End:   opencode.DiagnosticRangeEnd{Line: line},
+		},
+	}
+}
+
+func TestDiagnosticsTrackServersPerFile(t *testing.T) {
+	diagnostics := NewDiagnostics()
+	diagnostics.Update(opencode.EventListResponseEventLspClientDiagnosticsProperties{
+		Path:     "/project/b.go",
+		ServerID: "gopls",
+		Diagnostics: []opencode.Diagnostic{
+			diagnostic(10, DiagnosticSeverityWarning, "unused variable"),
+			diagnostic(2, DiagnosticSeverityError, "undefined: foo"),
+		},
+	})
+	diagnostics.Update(opencode.EventListResponseEventLspClientDiagnosticsProperties{
+		Path:        "/project/b.go",
+		ServerID:    "golangci",
+		Diagnostics: []opencode.Diagnostic{diagnostic(4, DiagnosticSeverityHint, "simplify")},
+	})
+	diagnostics.Update(opencode.EventListResponseEventLspClientDiagnosticsProperties{
+		Path:        "/project/a.go",
+		ServerID:    "gopls",
+		Diagnostics: []opencode.Diagnostic{diagnostic(1, 0, "missing severity")},
+	})
+
+	if errors := diagnostics.Count(DiagnosticSeverityError); errors != 2 {
+		t.Errorf("expected 2 errors, got %d", errors)
+	}
+	if warnings := diagnostics.Count(DiagnosticSeverityWarning); warnings != 1 {
+		t.Errorf("expected 1 warning, got %d", warnings)
+	}
+
+	list := diagnostics.List(DiagnosticSeverityWarning)
+	expected := []string{"missing severity", "undefined: foo", "unused variable"}
+	if len(list) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d", len(expected), len(list))
+	}
+	for i, message := range expected {
+		if list[i].Diagnostic.Message != message {
+			t.Errorf("expected %q at %d, got %q", message, i, list[i].Diagnostic.Message)
+		}
+	}
+
+	// a server publishing an empty list clears only its own diagnostics
+	diagnostics.Update(opencode.EventListResponseEventLspClientDiagnosticsProperties{
+		Path:     "/project/b.go",
+		ServerID: "gopls",
+	})
+	if all := diagnostics.List(DiagnosticSeverityHint); len(all) != 2 {
+		t.Errorf("expected 2 diagnostics after clearing gopls on b.go, got %d", len(all))
+	}
+}
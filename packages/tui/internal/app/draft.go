@@ -0,0 +1,31 @@
+package app
+
+import (
+	"log/slog"
+
+	"github.com/sst/opencode/internal/config"
+)
+
+// SaveDraft keeps the unsent prompt of the current session so it can be
+// restored after switching back or restarting. Read-only sessions have no
+// drafts.
+func (a *App) SaveDraft(draft config.PromptEntry) {
+	if a.ReadOnly() {
+		return
+	}
+	if err := config.SaveDraft(a.Info.Path.State, a.Info.Path.Root, a.Session.ID, draft); err != nil {
+		slog.Error("Failed to save draft", "error", err)
+	}
+}
+
+// LoadDraft returns the unsent prompt of the current session, or nil
+func (a *App) LoadDraft() *config.PromptEntry {
+	if a.ReadOnly() {
+		return nil
+	}
+	draft, err := config.LoadDraft(a.Info.Path.State, a.Info.Path.Root, a.Session.ID)
+	if err != nil {
+		slog.Error("Failed to load draft", "error", err)
+	}
+	return draft
+}
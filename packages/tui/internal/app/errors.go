@@ -0,0 +1,161 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+// ErrorInfo describes an error reported for a session, either on the
+// assistant message that failed or through a session.error event
+type ErrorInfo struct {
+	// Name is the error variant, such as ProviderAuthError
+	Name    string
+	Title   string
+	Message string
+	// Raw is the error as sent by the server
+	Raw string
+}
+
+// Aborted reports whether the error only records an interrupt
+func (e *ErrorInfo) Aborted() bool {
+	return e.Name == "MessageAbortedError"
+}
+
+// String joins the title and the message
+func (e *ErrorInfo) String() string {
+	if e.Message == "" {
+		return e.Title
+	}
+	return e.Title + ": " + e.Message
+}
+
+// MessageErrorInfo describes the error of an assistant message, or returns
+// nil when it did not fail
+func MessageErrorInfo(assistant opencode.AssistantMessage) *ErrorInfo {
+	err := assistant.Error
+	return errorInfo(err.AsUnion(), string(err.Name), err.Data, err.JSON.RawJSON())
+}
+
+// SessionErrorInfo describes the error of a session.error event, or returns
+// nil when it carries none
+func SessionErrorInfo(err opencode.EventListResponseEventSessionErrorPropertiesError) *ErrorInfo {
+	return errorInfo(err.AsUnion(), string(err.Name), err.Data, err.JSON.RawJSON())
+}
+
+// errorInfo describes a variant of the error unions shared by assistant
+// messages and session.error events
+func errorInfo(union any, name string, data any, raw string) *ErrorInfo {
+	if union == nil {
+		return nil
+	}
+	if raw == "" {
+		encoded, _ := json.Marshal(map[string]any{"name": name, "data": data})
+		raw = string(encoded)
+	}
+	info := &ErrorInfo{Name: name, Raw: raw}
+	switch err := union.(type) {
+	case opencode.ProviderAuthError:
+		info.Title = "Authentication with " + err.Data.ProviderID + " failed"
+		info.Message = err.Data.Message
+	case opencode.UnknownError:
+		info.Title = "Error"
+		info.Message = err.Data.Message
+	case opencode.AssistantMessageErrorMessageOutputLengthError,
+		opencode.EventListResponseEventSessionErrorPropertiesErrorMessageOutputLengthError:
+		info.Title = "Output length exceeded"
+		info.Message = "The response hit the output token limit of the model"
+	case opencode.MessageAbortedError:
+		info.Title = "Aborted"
+		info.Message = "The request was interrupted"
+	default:
+		info.Title = name
+	}
+	return info
+}
+
+// LastError returns the error that ended the last turn of the current
+// session: that of the last assistant message, or a session error reported
+// without one. Interrupts are not errors here.
+func (a *App) LastError() *ErrorInfo {
+	if len(a.Messages) > 0 {
+		if assistant, ok := a.Messages[len(a.Messages)-1].Info.(opencode.AssistantMessage); ok {
+			if info := MessageErrorInfo(assistant); info != nil && !info.Aborted() {
+				return info
+			}
+			return nil
+		}
+	}
+	if tab := a.Tab(a.Session.ID); tab != nil && !a.ReadOnly() {
+		return tab.Error
+	}
+	return nil
+}
+
+// MessageError describes the error shown after the message at index: the
+// error of an assistant message, or the session error following the prompt
+// it failed to answer
+func (a *App) MessageError(index int) *ErrorInfo {
+	if index < 0 || index >= len(a.Messages) {
+		return nil
+	}
+	switch casted := a.Messages[index].Info.(type) {
+	case opencode.AssistantMessage:
+		return MessageErrorInfo(casted)
+	case opencode.UserMessage:
+		if index == len(a.Messages)-1 {
+			return a.LastError()
+		}
+	}
+	return nil
+}
+
+// RetryMsg sends the prompt of the last turn again after reverting it, with
+// the given model when one is set
+type RetryMsg struct {
+	Provider *opencode.Provider
+	Model    *opencode.Model
+}
+
+// RetryPrompt returns the message that reverts the session to before the
+// last prompt and sends it again
+func (a *App) RetryPrompt() (SendMsg, bool) {
+	for i := len(a.Messages) - 1; i >= 0; i-- {
+		if _, ok := a.Messages[i].Info.(opencode.UserMessage); !ok {
+			continue
+		}
+		prompt := a.Messages[i]
+		return SendMsg{
+			Text:        MessageText(prompt),
+			Attachments: PromptAttachments(prompt),
+			Revert:      prompt.ID(),
+		}, true
+	}
+	return SendMsg{}, false
+}
+
+// FailedModel returns the provider and model that answered the last turn, or
+// nil when they are no longer available
+func (a *App) FailedModel() (*opencode.Provider, *opencode.Model) {
+	for i := len(a.Messages) - 1; i >= 0; i-- {
+		assistant, ok := a.Messages[i].Info.(opencode.AssistantMessage)
+		if !ok {
+			continue
+		}
+		return a.FindModel(assistant.ProviderID, assistant.ModelID)
+	}
+	return nil, nil
+}
+
+// FindModel looks a model up among the configured providers
+func (a *App) FindModel(providerID, modelID string) (*opencode.Provider, *opencode.Model) {
+	for _, provider := range a.Providers {
+		if provider.ID != providerID {
+			continue
+		}
+		if model, ok := provider.Models[modelID]; ok {
+			return &provider, &model
+		}
+	}
+	return nil, nil
+}
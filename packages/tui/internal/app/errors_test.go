@@ -0,0 +1,120 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+)
+
+var errorVariants = []struct {
+	raw     string
+	name    string
+	title   string
+	message string
+	aborted bool
+}{
+	{
+		raw:     `{"name":"ProviderAuthError","data":{"providerID":"anthropic","message":"invalid x-api-key"}}`,
+		name:    "ProviderAuthError",
+		title:   "Authentication with anthropic failed",
+		message: "invalid x-api-key",
+	},
+	{
+		raw:     `{"name":"UnknownError","data":{"message":"overloaded"}}`,
+		name:    "UnknownError",
+		title:   "Error",
+		message: "overloaded",
+	},
+	{
+		raw:     `{"name":"MessageOutputLengthError","data":{}}`,
+		name:    "MessageOutputLengthError",
+		title:   "Output length exceeded",
+		message: "The response hit the output token limit of the model",
+	},
+	{
+		raw:     `{"name":"MessageAbortedError","data":{}}`,
+		name:    "MessageAbortedError",
+		title:   "Aborted",
+		message: "The request was interrupted",
+		aborted: true,
+	},
+}
+
+func checkErrorInfo(t *testing.T, source string, info *ErrorInfo, want int) {
+	t.Helper()
+	variant := errorVariants[want]
+	if info == nil {
+		t.Fatalf("%s %s: expected an error", source, variant.name)
+	}
+	if info.Name != variant.name || info.Title != variant.title || info.Message != variant.message {
+		t.Errorf("%s %s: unexpected description %+v", source, variant.name, info)
+	}
+	if info.Aborted() != variant.aborted {
+		t.Errorf("%s %s: expected Aborted() to be %v", source, variant.name, variant.aborted)
+	}
+	if !strings.Contains(info.Raw, `"name":"`+variant.name+`"`) {
+		t.Errorf("%s %s: expected the raw error, got %q", source, variant.name, info.Raw)
+	}
+}
+
+func TestMessageErrorInfo(t *testing.T) {
+	for i, variant := range errorVariants {
+		var message opencode.Message
+		raw := `{"id":"msg_1","role":"assistant","sessionID":"ses_1","modelID":"m","providerID":"p",
+			"mode":"build","system":[],"cost":0,"path":{"cwd":"/","root":"/"},"time":{"created":1,"completed":2},
+			"tokens":{"input":0,"output":0,"reasoning":0,"cache":{"read":0,"write":0}},"error":` + variant.raw + `}`
+		if err := json.Unmarshal([]byte(raw), &message); err != nil {
+			t.Fatal(err)
+		}
+		checkErrorInfo(t, "message", MessageErrorInfo(message.AsUnion().(opencode.AssistantMessage)), i)
+	}
+	if MessageErrorInfo(opencode.AssistantMessage{}) != nil {
+		t.Error("a message without an error should not be described")
+	}
+}
+
+func TestSessionErrorInfo(t *testing.T) {
+	for i, variant := range errorVariants {
+		event := decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_1","error":`+variant.raw+`}}`)
+		checkErrorInfo(t, "session", SessionErrorInfo(event.(opencode.EventListResponseEventSessionError).Properties.Error), i)
+	}
+	event := decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_1"}}`)
+	if SessionErrorInfo(event.(opencode.EventListResponseEventSessionError).Properties.Error) != nil {
+		t.Error("an event without an error should not be described")
+	}
+}
+
+func TestLastErrorAndRetry(t *testing.T) {
+	a := &App{State: &config.State{}}
+	a.ShowSession(&opencode.Session{ID: "ses_1"}, []Message{{
+		Info: opencode.UserMessage{ID: "msg_1", SessionID: "ses_1"},
+		Parts: []opencode.PartUnion{
+			opencode.TextPart{ID: "prt_1", Text: "fix the tests"},
+			opencode.FilePart{ID: "prt_2", Mime: "image/png", URL: "file:///tmp/a.png", Filename: "a.png"},
+		},
+	}})
+	if a.LastError() != nil {
+		t.Fatal("expected no error before one is reported")
+	}
+
+	a.UpdateTabs(decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_1","error":`+errorVariants[0].raw+`}}`))
+	if info := a.MessageError(0); info == nil || info.Name != "ProviderAuthError" {
+		t.Errorf("expected the session error after the unanswered prompt, got %+v", info)
+	}
+
+	retry, ok := a.RetryPrompt()
+	if !ok || retry.Text != "fix the tests" || retry.Revert != "msg_1" || len(retry.Attachments) != 1 {
+		t.Errorf("expected the prompt to be reverted and resent, got %+v", retry)
+	}
+
+	a.Messages = append(a.Messages, Message{Info: opencode.AssistantMessage{ID: "msg_2", SessionID: "ses_1"}})
+	if a.LastError() != nil {
+		t.Error("an answered prompt should not show the earlier session error")
+	}
+	if _, ok := (&App{}).RetryPrompt(); ok {
+		t.Error("expected nothing to retry without a prompt")
+	}
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+)
+
+const (
+	eventStreamInitialBackoff = 500 * time.Millisecond
+	eventStreamMaxBackoff     = 30 * time.Second
+)
+
+// EventStreamConnectedMsg is sent whenever the event stream is established.
+// Reconnected is set when a previous connection was lost, in which case any
+// events emitted in between were missed and local state must be resynced.
+type EventStreamConnectedMsg struct {
+	Reconnected bool
+}
+
+// EventStreamReconnectingMsg is sent when the event stream drops and the
+// subscriber is waiting to retry
+type EventStreamReconnectingMsg struct {
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+// SessionResyncedMsg carries the server's view of a session after a resync.
+// Session is nil when the session no longer exists.
+type SessionResyncedMsg struct {
+	SessionID string
+	Session   *opencode.Session
+	Messages  []Message
+	// Requested is set when the resync was asked for with ReconcileMessages
+	Requested bool
+}
+
+// SubscribeEvents keeps the server event stream open until ctx is cancelled,
+// forwarding every event to send. When the stream fails or is closed by the
+// server it reconnects with exponential backoff.
+func SubscribeEvents(ctx context.Context, client *opencode.Client, send func(tea.Msg)) {
+	attempt := 0
+	connected := false
+	for {
+		stream := client.Event.ListStreaming(ctx)
+		err := stream.Err()
+		if err == nil {
+			send(EventStreamConnectedMsg{Reconnected: connected})
+			connected = true
+			attempt = 0
+			for stream.Next() {
+				send(stream.Current().AsUnion())
+			}
+			err = stream.Err()
+			stream.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		attempt++
+		delay := eventStreamBackoff(attempt)
+		slog.Error("Event stream disconnected", "error", err, "attempt", attempt, "delay", delay)
+		send(EventStreamReconnectingMsg{Attempt: attempt, Delay: delay, Err: err})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func eventStreamBackoff(attempt int) time.Duration {
+	delay := eventStreamInitialBackoff
+	for i := 1; i < attempt && delay < eventStreamMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, eventStreamMaxBackoff)
+}
+
+// ResyncSession reloads the current session and its messages from the server,
+// replacing whatever was built up from (possibly incomplete) streamed events
+func (a *App) ResyncSession(ctx context.Context) tea.Cmd {
+	if a.Session == nil || a.Session.ID == "" || a.ReadOnly() {
+		return nil
+	}
+	return a.resync(ctx, a.Session.ID)
+}
+
+// ResyncTabs reloads the sessions of the background tabs, whose messages
+// miss the events sent while the event stream was down
+func (a *App) ResyncTabs(ctx context.Context) tea.Cmd {
+	cmds := []tea.Cmd{}
+	for i, tab := range a.Tabs {
+		if i != a.ActiveTab() {
+			cmds = append(cmds, a.resync(ctx, tab.Session.ID))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// resync loads a session and its messages from the server
+func (a *App) resync(ctx context.Context, sessionID string) tea.Cmd {
+	return func() tea.Msg {
+		sessions, err := a.ListSessions(ctx)
+		if err != nil {
+			slog.Error("Failed to resync session", "error", err)
+			return nil
+		}
+		var session *opencode.Session
+		for _, s := range sessions {
+			if s.ID == sessionID {
+				session = &s
+				break
+			}
+		}
+		if session == nil {
+			return SessionResyncedMsg{SessionID: sessionID}
+		}
+		messages, err := a.ListMessages(ctx, sessionID)
+		if err != nil {
+			slog.Error("Failed to resync messages", "error", err)
+			return nil
+		}
+		return SessionResyncedMsg{
+			SessionID: sessionID,
+			Session:   session,
+			Messages:  TrimReverted(messages, session.Revert),
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+func TestEventStreamBackoff(t *testing.T) {
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 500 * time.Millisecond},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{7, 30 * time.Second},
+		{100, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := eventStreamBackoff(tt.attempt); got != tt.expected {
+			t.Errorf("eventStreamBackoff(%d) = %v, want %v", tt.attempt, got, tt.expected)
+		}
+	}
+}
+
+func TestSubscribeEventsReconnects(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"session.idle\",\"properties\":{\"sessionID\":\"ses_1\"}}\n\n")
+		w.(http.Flusher).Flush()
+		// returning closes the stream, forcing a reconnect
+	}))
+	defer server.Close()
+
+	client := opencode.NewClient(option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msgs := make(chan tea.Msg, 16)
+	done := make(chan struct{})
+	go func() {
+		SubscribeEvents(ctx, client, func(msg tea.Msg) { msgs <- msg })
+		close(done)
+	}()
+
+	var received []tea.Msg
+	timeout := time.After(5 * time.Second)
+	for len(received) < 4 {
+		select {
+		case msg := <-msgs:
+			received = append(received, msg)
+		case <-timeout:
+			t.Fatalf("timed out, received %v", received)
+		}
+	}
+	cancel()
+	<-done
+
+	if msg, ok := received[0].(EventStreamConnectedMsg); !ok || msg.Reconnected {
+		t.Errorf("expected initial connection, got %#v", received[0])
+	}
+	if _, ok := received[1].(opencode.EventListResponseEventSessionIdle); !ok {
+		t.Errorf("expected session.idle event, got %#v", received[1])
+	}
+	if msg, ok := received[2].(EventStreamReconnectingMsg); !ok || msg.Attempt != 1 {
+		t.Errorf("expected first reconnect attempt, got %#v", received[2])
+	}
+	if msg, ok := received[3].(EventStreamConnectedMsg); !ok || !msg.Reconnected {
+		t.Errorf("expected reconnection, got %#v", received[3])
+	}
+	if connections.Load() < 2 {
+		t.Errorf("expected at least 2 connections, got %d", connections.Load())
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// ForkSession creates a new session holding the messages of the current one
+// up to and including the given message, and switches to it
+func (a *App) ForkSession(ctx context.Context, messageID string) tea.Cmd {
+	sessionID := a.Session.ID
+	return func() tea.Msg {
+		session, err := a.Client.Session.Fork(ctx, sessionID, opencode.SessionForkParams{
+			MessageID: opencode.F(messageID),
+		})
+		if err != nil {
+			slog.Error("Failed to fork session", "error", err)
+			return toast.NewErrorToast("Failed to fork session")()
+		}
+		return SessionSelectedMsg(session)
+	}
+}
+
+// ForkSourceLoadedMsg carries the session the current one was forked from
+type ForkSourceLoadedMsg *opencode.Session
+
+// LoadForkSource looks up the session the current one was forked from, so
+// the header can link back to it. The source is cleared until it arrives,
+// and stays cleared for sessions that are not forks or whose source was
+// deleted.
+func (a *App) LoadForkSource(ctx context.Context) tea.Cmd {
+	a.ForkSource = nil
+	sourceID := a.Session.Fork.SessionID
+	if sourceID == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		source, err := a.GetSession(ctx, sourceID)
+		if err != nil {
+			slog.Debug("Fork source is gone", "session", sourceID, "error", err)
+			return nil
+		}
+		return ForkSourceLoadedMsg(source)
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/id"
+	"github.com/sst/opencode/internal/util"
+)
+
+// SessionImportedMsg is sent once an exported transcript has been loaded
+type SessionImportedMsg struct {
+	Path     string
+	Session  opencode.Session
+	Messages []Message
+}
+
+// importedPrefix starts the local ID of an imported transcript, so events of
+// the server session it was exported from do not change it
+const importedPrefix = "imported_"
+
+// Import shows a transcript in place of the current session. The transcript
+// is not backed by a server session, so it stays read-only until another
+// session is opened.
+func (a *App) Import(path string, session opencode.Session, messages []Message) {
+	session.ID = importedPrefix + session.ID
+	a.Session = &session
+	a.Messages = messages
+	a.ImportedFrom = path
+}
+
+// ReadOnly reports whether the current session is an imported transcript
+func (a *App) ReadOnly() bool {
+	return a.ImportedFrom != ""
+}
+
+// SeedSession starts a new server session that continues an imported
+// transcript. The transcript is sent as a synthetic part so the model has
+// the earlier conversation as context without it cluttering the view.
+func (a *App) SeedSession(ctx context.Context, path string, transcript string) (*App, tea.Cmd) {
+	session, err := a.CreateSession(ctx)
+	if err != nil {
+		return a, toast.NewErrorToast(err.Error())
+	}
+	a.Session = session
+	a.Messages = []Message{}
+	a.ImportedFrom = ""
+
+	message := a.newUserMessage(
+		fmt.Sprintf("Continuing the conversation imported from %s.", filepath.Base(path)),
+		nil,
+	)
+	message.Parts = append(message.Parts, opencode.TextPart{
+		ID:        id.Ascending(id.Part),
+		MessageID: message.ID(),
+		SessionID: session.ID,
+		Type:      opencode.TextPartTypeText,
+		Synthetic: true,
+		Text: "The following is a transcript of an earlier conversation. " +
+			"Use it as context and briefly confirm you are ready to continue.\n\n" + transcript,
+	})
+	a.Messages = append(a.Messages, message)
+
+	return a, tea.Batch(
+		util.CmdHandler(SessionCreatedMsg{Session: session}),
+		util.CmdHandler(OptimisticMessageAddedMsg{Message: message.Info}),
+		func() tea.Msg {
+			if err := a.chat(ctx, message); err != nil {
+				errormsg := fmt.Sprintf("failed to seed session: %v", err)
+				slog.Error(errormsg)
+				return toast.NewErrorToast(errormsg)()
+			}
+			return nil
+		},
+	)
+}
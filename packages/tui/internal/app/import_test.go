@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+func TestImportIsReadOnly(t *testing.T) {
+	a := &App{Session: &opencode.Session{}}
+	if a.ReadOnly() {
+		t.Fatal("expected a fresh app to be writable")
+	}
+
+	messages := []Message{{Info: opencode.UserMessage{ID: "msg_1", SessionID: "ses_1"}}}
+	a.Import("/tmp/ses_1.json", opencode.Session{ID: "ses_1"}, messages)
+	if !a.ReadOnly() || len(a.Messages) != 1 {
+		t.Fatalf("unexpected state after import: %+v", a)
+	}
+	if a.Session.ID == "ses_1" {
+		t.Error("expected the import to get its own ID, out of reach of events of the exported session")
+	}
+	if cmd := a.ResyncSession(context.Background()); cmd != nil {
+		t.Error("imported transcripts must not be resynced with the server")
+	}
+}
+
+func TestSeedSession(t *testing.T) {
+	var chat struct {
+		Parts []struct {
+			Text      string `json:"text"`
+			Synthetic bool   `json:"synthetic"`
+		} `json:"parts"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /session", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"ses_2","title":"seeded","version":"dev","time":{"created":1,"updated":1}}`)
+	})
+	mux.HandleFunc("POST /session/ses_2/message", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+			t.Errorf("invalid chat request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_2","role":"assistant","sessionID":"ses_2"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	a := &App{
+		Client:   opencode.NewClient(option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+		Session:  &opencode.Session{},
+		Mode:     &opencode.Mode{Name: "build"},
+		Provider: &opencode.Provider{ID: "p"},
+		Model:    &opencode.Model{ID: "m"},
+	}
+	a.Import("/tmp/ses_1.json", opencode.Session{ID: "ses_1"}, []Message{})
+	_, cmd := a.SeedSession(context.Background(), "/tmp/ses_1.json", "# Transcript")
+	if a.ReadOnly() || a.Session.ID != "ses_2" || len(a.Messages) != 1 {
+		t.Fatalf("expected a writable session with the seed prompt, got %s with %d messages", a.Session.ID, len(a.Messages))
+	}
+	for _, msg := range cmd().(tea.BatchMsg) {
+		msg()
+	}
+	if len(chat.Parts) != 2 || chat.Parts[0].Text != "Continuing the conversation imported from ses_1.json." ||
+		!chat.Parts[1].Synthetic || !strings.HasSuffix(chat.Parts[1].Text, "# Transcript") {
+		t.Errorf("expected the transcript to be sent as a synthetic part, got %+v", chat.Parts)
+	}
+
+	failing := &App{
+		Client:  opencode.NewClient(option.WithBaseURL("http://127.0.0.1:1"), option.WithMaxRetries(0)),
+		Session: &opencode.Session{ID: "imported_ses_1"},
+	}
+	failing.ImportedFrom = "/tmp/ses_1.json"
+	if _, cmd := failing.SeedSession(context.Background(), "/tmp/ses_1.json", ""); cmd == nil || !failing.ReadOnly() {
+		t.Error("expected the import to stay open when no session could be created")
+	}
+}
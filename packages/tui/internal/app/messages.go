@@ -0,0 +1,234 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+)
+
+// MessagesReconciledMsg reports how the local messages of a session differed
+// from the server once they have been replaced
+type MessagesReconciledMsg struct {
+	SessionID string
+	Drift     MessageDrift
+	// Requested is set when the reconciliation was asked for by the user
+	// rather than triggered by a reconnect
+	Requested bool
+}
+
+// MessageDrift lists the IDs of the messages that were missing locally, no
+// longer exist on the server or whose content differs
+type MessageDrift struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (d MessageDrift) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d MessageDrift) String() string {
+	if d.Empty() {
+		return "in sync"
+	}
+	summary := []string{}
+	if len(d.Added) > 0 {
+		summary = append(summary, fmt.Sprintf("%d added", len(d.Added)))
+	}
+	if len(d.Removed) > 0 {
+		summary = append(summary, fmt.Sprintf("%d removed", len(d.Removed)))
+	}
+	if len(d.Changed) > 0 {
+		summary = append(summary, fmt.Sprintf("%d changed", len(d.Changed)))
+	}
+	return strings.Join(summary, ", ")
+}
+
+// MarshalJSON encodes the message as its info and parts, the same shape the
+// server uses for GET /session/:id/message
+func (m Message) MarshalJSON() ([]byte, error) {
+	info, err := json.Marshal(m.Info)
+	if err != nil {
+		return nil, err
+	}
+	// the server leaves the error out of messages that did not fail, an empty
+	// one would decode as a provider error
+	if assistant, ok := m.Info.(opencode.AssistantMessage); ok && assistant.Error.Name == "" {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(info, &fields); err != nil {
+			return nil, err
+		}
+		delete(fields, "error")
+		if info, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(struct {
+		Info  json.RawMessage      `json:"info"`
+		Parts []opencode.PartUnion `json:"parts"`
+	}{info, m.Parts})
+}
+
+// UnmarshalJSON decodes a message encoded by MarshalJSON or returned by the
+// server, resolving the info and parts to their concrete variants
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Info  opencode.Message `json:"info"`
+		Parts []opencode.Part  `json:"parts"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Info = raw.Info.AsUnion()
+	m.Parts = make([]opencode.PartUnion, 0, len(raw.Parts))
+	for _, part := range raw.Parts {
+		m.Parts = append(m.Parts, part.AsUnion())
+	}
+	return nil
+}
+
+// PartID returns the ID of any part variant
+func PartID(part opencode.PartUnion) string {
+	switch casted := part.(type) {
+	case opencode.TextPart:
+		return casted.ID
+	case opencode.FilePart:
+		return casted.ID
+	case opencode.ToolPart:
+		return casted.ID
+	case opencode.StepStartPart:
+		return casted.ID
+	case opencode.StepFinishPart:
+		return casted.ID
+	case opencode.SnapshotPart:
+		return casted.ID
+	}
+	return ""
+}
+
+// MessageText joins the text parts of a message, leaving out synthetic ones
+func MessageText(message Message) string {
+	texts := []string{}
+	for _, part := range message.Parts {
+		if text, ok := part.(opencode.TextPart); ok && !text.Synthetic {
+			texts = append(texts, strings.TrimSpace(text.Text))
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// RemoveMessage drops a message of the current session, reporting whether it
+// was present
+func (a *App) RemoveMessage(messageID string) bool {
+	var removed bool
+	a.Messages, removed = removeMessage(a.Messages, messageID)
+	return removed
+}
+
+func removeMessage(messages []Message, messageID string) ([]Message, bool) {
+	index := slices.IndexFunc(messages, func(m Message) bool {
+		return m.ID() == messageID
+	})
+	if index == -1 {
+		return messages, false
+	}
+	return slices.Delete(messages, index, index+1), true
+}
+
+// UpdateMessage applies a message.updated event to messages, replacing the
+// info of a known message while keeping its parts, or appending a new one
+func UpdateMessage(messages []Message, info opencode.MessageUnion) []Message {
+	message := Message{Info: info}
+	index := slices.IndexFunc(messages, func(m Message) bool {
+		return m.ID() == message.ID()
+	})
+	if index == -1 {
+		message.Parts = []opencode.PartUnion{}
+		return append(messages, message)
+	}
+	message.Parts = messages[index].Parts
+	messages[index] = message
+	return messages
+}
+
+// UpdatePart applies a message.part.updated event to messages, replacing or
+// appending the part. Parts of unknown messages are dropped.
+func UpdatePart(messages []Message, part opencode.Part) []Message {
+	index := slices.IndexFunc(messages, func(m Message) bool {
+		return m.ID() == part.MessageID
+	})
+	if index == -1 {
+		return messages
+	}
+	message := messages[index]
+	partIndex := slices.IndexFunc(message.Parts, func(p opencode.PartUnion) bool {
+		return PartID(p) == part.ID
+	})
+	if partIndex > -1 {
+		message.Parts[partIndex] = part.AsUnion()
+	} else {
+		message.Parts = append(message.Parts, part.AsUnion())
+	}
+	messages[index] = message
+	return messages
+}
+
+// ReconcileMessages reloads the current session from the server so any drift
+// from the streamed state can be detected and fixed
+func (a *App) ReconcileMessages(ctx context.Context) tea.Cmd {
+	resync := a.ResyncSession(ctx)
+	if resync == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := resync()
+		if resynced, ok := msg.(SessionResyncedMsg); ok {
+			resynced.Requested = true
+			return resynced
+		}
+		return msg
+	}
+}
+
+// DiffMessages compares the local messages of a session with the ones the
+// server returned. Messages are compared by their serialized content, which
+// leaves out transport metadata.
+func DiffMessages(local, remote []Message) MessageDrift {
+	drift := MessageDrift{}
+	known := map[string]Message{}
+	for _, message := range local {
+		known[message.ID()] = message
+	}
+	for _, message := range remote {
+		id := message.ID()
+		previous, ok := known[id]
+		delete(known, id)
+		if !ok {
+			drift.Added = append(drift.Added, id)
+			continue
+		}
+		if messageFingerprint(previous) != messageFingerprint(message) {
+			drift.Changed = append(drift.Changed, id)
+		}
+	}
+	for _, message := range local {
+		if _, ok := known[message.ID()]; ok {
+			drift.Removed = append(drift.Removed, message.ID())
+		}
+	}
+	return drift
+}
+
+func messageFingerprint(message Message) string {
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func textMessage(id, text string) Message {
+	return Message{
+		Info: opencode.UserMessage{ID: id, Role: opencode.UserMessageRoleUser},
+		Parts: []opencode.PartUnion{
+			opencode.TextPart{ID: id + "_text", MessageID: id, Type: opencode.TextPartTypeText, Text: text},
+		},
+	}
+}
+
+func TestDiffMessages(t *testing.T) {
+	local := []Message{
+		textMessage("msg_1", "hello"),
+		textMessage("msg_2", "partial"),
+		textMessage("msg_3", "reverted elsewhere"),
+	}
+	remote := []Message{
+		textMessage("msg_1", "hello"),
+		textMessage("msg_2", "partial response"),
+		textMessage("msg_4", "missed while offline"),
+	}
+
+	drift := DiffMessages(local, remote)
+	if !slices.Equal(drift.Added, []string{"msg_4"}) {
+		t.Errorf("unexpected added messages: %v", drift.Added)
+	}
+	if !slices.Equal(drift.Removed, []string{"msg_3"}) {
+		t.Errorf("unexpected removed messages: %v", drift.Removed)
+	}
+	if !slices.Equal(drift.Changed, []string{"msg_2"}) {
+		t.Errorf("unexpected changed messages: %v", drift.Changed)
+	}
+	if !DiffMessages(remote, remote).Empty() {
+		t.Error("expected no drift between identical message lists")
+	}
+}
+
+func TestRemoveMessage(t *testing.T) {
+	a := &App{Messages: []Message{textMessage("msg_1", "a"), textMessage("msg_2", "b")}}
+	if !a.RemoveMessage("msg_1") {
+		t.Fatal("expected msg_1 to be removed")
+	}
+	if a.RemoveMessage("msg_1") {
+		t.Error("expected removing msg_1 twice to report nothing removed")
+	}
+	if len(a.Messages) != 1 || a.Messages[0].ID() != "msg_2" {
+		t.Errorf("unexpected messages after removal: %v", a.Messages)
+	}
+}
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	// messages as the server returns them
+	server := `[
+		{"info":{"id":"msg_1","role":"user","sessionID":"ses_1","time":{"created":1}},
+		 "parts":[{"id":"prt_1","type":"text","messageID":"msg_1","sessionID":"ses_1","text":"list files"}]},
+		{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1","modelID":"model","providerID":"provider",
+		  "cost":0.25,"path":{"cwd":"/","root":"/"},"system":["prompt"],"time":{"created":1,"completed":2},
+		  "tokens":{"input":10,"output":20,"reasoning":0,"cache":{"read":0,"write":0}}},
+		 "parts":[
+		  {"id":"prt_2","type":"tool","messageID":"msg_2","sessionID":"ses_1","callID":"call_1","tool":"bash",
+		   "state":{"status":"completed","input":{"command":"ls"},"output":"README.md","title":"ls",
+		    "metadata":{"stdout":"README.md"},"time":{"start":1,"end":2}}},
+		  {"id":"prt_3","type":"step-finish","messageID":"msg_2","sessionID":"ses_1","cost":0.25,
+		   "tokens":{"input":10,"output":20,"reasoning":0,"cache":{"read":0,"write":0}}}]},
+		{"info":{"id":"msg_3","role":"assistant","sessionID":"ses_1","modelID":"model","providerID":"provider",
+		  "cost":0,"path":{"cwd":"/","root":"/"},"system":[],"time":{"created":3},
+		  "tokens":{"input":0,"output":0,"reasoning":0,"cache":{"read":0,"write":0}},
+		  "error":{"name":"UnknownError","data":{"message":"boom"}}},
+		 "parts":[]}
+	]`
+	var original []Message
+	if err := json.Unmarshal([]byte(server), &original); err != nil {
+		t.Fatalf("failed to decode server messages: %v", err)
+	}
+
+	encoded, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("failed to encode messages: %v", err)
+	}
+	var decoded []Message
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("failed to decode messages: %v", err)
+	}
+	if drift := DiffMessages(original, decoded); !drift.Empty() {
+		t.Errorf("messages changed after a round trip: %s", drift)
+	}
+
+	tool, ok := decoded[1].Parts[0].(opencode.ToolPart)
+	if !ok {
+		t.Fatalf("expected a tool part, got %T", decoded[1].Parts[0])
+	}
+	if tool.State.Output != "README.md" || tool.State.Input.(map[string]any)["command"] != "ls" {
+		t.Errorf("unexpected tool state after a round trip: %+v", tool.State)
+	}
+	if err := decoded[1].Info.(opencode.AssistantMessage).Error.AsUnion(); err != nil {
+		t.Errorf("expected no error on a successful message, got %#v", err)
+	}
+	if _, ok := decoded[2].Info.(opencode.AssistantMessage).Error.AsUnion().(opencode.UnknownError); !ok {
+		t.Errorf("expected the unknown error to survive a round trip")
+	}
+}
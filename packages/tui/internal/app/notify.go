@@ -0,0 +1,190 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+)
+
+// Notification is shown when a session finishes or fails while the terminal
+// is unfocused
+type Notification struct {
+	Title string
+	Body  string
+}
+
+// Notify returns the notification for a session.idle or session.error event
+// of the current session or an open tab. Nothing is sent while the terminal
+// is focused, or for the idle event that follows a notified error.
+func (a *App) Notify(msg tea.Msg) tea.Cmd {
+	notification, ok := a.notification(msg)
+	if !ok || !a.Unfocused {
+		return nil
+	}
+	return tea.Batch(
+		terminalNotification(a.State.NotifyMode(), notification, os.Getenv),
+		notifyCommand(a.State.NotifyCommand, notification),
+	)
+}
+
+func (a *App) notification(msg tea.Msg) (Notification, bool) {
+	title := func(sessionID string) (string, bool) {
+		if a.Session != nil && a.Session.ID == sessionID && !a.ReadOnly() {
+			return a.Session.Title, true
+		}
+		// subagent sessions go idle as their task completes, only the
+		// sessions the user opened are reported
+		if tab := a.Tab(sessionID); tab != nil {
+			return tab.Session.Title, true
+		}
+		return "", false
+	}
+
+	switch msg := msg.(type) {
+	case opencode.EventListResponseEventSessionIdle:
+		sessionID := msg.Properties.SessionID
+		if a.notifiedError == sessionID {
+			a.notifiedError = ""
+			return Notification{}, false
+		}
+		session, ok := title(sessionID)
+		if !ok {
+			return Notification{}, false
+		}
+		return Notification{Title: "opencode", Body: notificationSubject(session) + " is done"}, true
+	case opencode.EventListResponseEventSessionError:
+		info := SessionErrorInfo(msg.Properties.Error)
+		session, ok := title(msg.Properties.SessionID)
+		if !ok || info == nil || info.Aborted() {
+			return Notification{}, false
+		}
+		a.notifiedError = msg.Properties.SessionID
+		return Notification{
+			Title: "opencode error",
+			Body:  notificationSubject(session) + " failed: " + info.Message,
+		}, true
+	}
+	return Notification{}, false
+}
+
+func notificationSubject(title string) string {
+	if title == "" {
+		return "The session"
+	}
+	return fmt.Sprintf("%q", title)
+}
+
+// terminalNotification writes the escape sequences notifying the terminal.
+// OSC 777 is used by the rxvt family, foot and VTE based terminals; the
+// others get the more widely supported OSC 9. Inside tmux the sequence is
+// passed through to the outer terminal.
+func terminalNotification(mode string, notification Notification, getenv func(string) string) tea.Cmd {
+	if mode == config.NotifyOff {
+		return nil
+	}
+	sequence := "\a"
+	if mode == config.NotifyOSC {
+		title := sanitizeNotification(notification.Title)
+		body := sanitizeNotification(notification.Body)
+		term := getenv("TERM")
+		if strings.HasPrefix(term, "rxvt") || strings.HasPrefix(term, "foot") || getenv("VTE_VERSION") != "" {
+			sequence = fmt.Sprintf("\x1b]777;notify;%s;%s\x07", title, body)
+		} else {
+			sequence = fmt.Sprintf("\x1b]9;%s: %s\x07", title, body)
+		}
+		if getenv("TMUX") != "" {
+			sequence = "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+		}
+		sequence += "\a"
+	}
+	return tea.Raw(sequence)
+}
+
+// sanitizeNotification drops the control characters that would end the
+// escape sequence early, and the separators of OSC 777
+func sanitizeNotification(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ';':
+			return ','
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+// notifyCommand runs the notify_command hook, such as notify-send, with the
+// title and body as its last two arguments
+func notifyCommand(command string, notification Notification) tea.Cmd {
+	args, err := splitCommand(command)
+	if err != nil {
+		slog.Error("Failed to parse notify command", "command", command, "error", err)
+		return nil
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		args = append(args, notification.Title, notification.Body)
+		if output, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			slog.Error("Failed to run notify command", "command", command, "error", err, "output", string(output))
+		}
+		return nil
+	}
+}
+
+// splitCommand splits a command line into its arguments the way a shell
+// does, without expanding anything. Single quotes keep their content as is,
+// while a backslash escapes the next character outside quotes and a quote,
+// backslash, dollar sign or backtick inside double quotes.
+func splitCommand(command string) ([]string, error) {
+	args := []string{}
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range command {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune(`"\$`+"`", r) {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped || quote != 0 {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", command)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
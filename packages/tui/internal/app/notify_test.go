@@ -0,0 +1,111 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+)
+
+func TestNotify(t *testing.T) {
+	a := &App{
+		Session: &opencode.Session{ID: "ses_1", Title: "fix tests"},
+		State:   &config.State{},
+	}
+	idle := func(sessionID string) tea.Msg {
+		return decodeEvent(t, `{"type":"session.idle","properties":{"sessionID":"`+sessionID+`"}}`)
+	}
+
+	if a.Notify(idle("ses_1")) != nil {
+		t.Error("nothing should be sent while the terminal is focused")
+	}
+	a.Unfocused = true
+	if a.Notify(idle("ses_1")) == nil {
+		t.Error("expected a notification once the terminal is unfocused")
+	}
+	if notification, _ := a.notification(idle("ses_1")); notification.Body != `"fix tests" is done` {
+		t.Errorf("unexpected notification %+v", notification)
+	}
+	if a.Notify(idle("ses_child")) != nil {
+		t.Error("sessions that are not open should not be notified")
+	}
+
+	failed := decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_1",
+		"error":{"name":"ProviderAuthError","data":{"providerID":"p","message":"bad key"}}}}`)
+	notification, ok := a.notification(failed)
+	if !ok || notification.Body != `"fix tests" failed: bad key` {
+		t.Errorf("unexpected notification %+v", notification)
+	}
+	if a.Notify(idle("ses_1")) != nil {
+		t.Error("the idle event after a notified error should be skipped")
+	}
+	if a.Notify(idle("ses_1")) == nil {
+		t.Error("expected later idle events to be notified again")
+	}
+
+	aborted := decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_1",
+		"error":{"name":"MessageAbortedError","data":{}}}}`)
+	if a.Notify(aborted) != nil {
+		t.Error("an interrupt should not be notified")
+	}
+
+	a.State.Notify = config.NotifyOff
+	if a.Notify(idle("ses_1")) != nil {
+		t.Error("expected no notification when disabled without a command")
+	}
+}
+
+func TestTerminalNotification(t *testing.T) {
+	notification := Notification{Title: "opencode", Body: "done; \x1b]0;evil\x07"}
+	sequence := func(mode string, env map[string]string) string {
+		cmd := terminalNotification(mode, notification, func(key string) string { return env[key] })
+		if cmd == nil {
+			return ""
+		}
+		return cmd().(tea.RawMsg).Msg.(string)
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		env  map[string]string
+		want string
+	}{
+		{"osc 9", config.NotifyOSC, map[string]string{"TERM": "xterm-256color"}, "\x1b]9;opencode: done,  ]0,evil \x07\a"},
+		{"osc 777", config.NotifyOSC, map[string]string{"TERM": "foot"}, "\x1b]777;notify;opencode;done,  ]0,evil \x07\a"},
+		{"tmux", config.NotifyOSC, map[string]string{"TMUX": "/tmp/tmux"}, "\x1bPtmux;\x1b\x1b]9;opencode: done,  ]0,evil \x07\x1b\\\a"},
+		{"bell", config.NotifyBell, nil, "\a"},
+		{"off", config.NotifyOff, nil, ""},
+	}
+	for _, tt := range tests {
+		if got := sequence(tt.mode, tt.env); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"notify-send -u low", []string{"notify-send", "-u", "low"}},
+		{`notify-send --app-name "open code"`, []string{"notify-send", "--app-name", "open code"}},
+		{`"/Applications/My Notifier/notify" 'a "quoted" arg'`, []string{"/Applications/My Notifier/notify", `a "quoted" arg`}},
+		{`say hello\ world "tab\"s" "a\b" ''`, []string{"say", "hello world", `tab"s`, `a\b`, ""}},
+		{"  ", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := splitCommand(tt.command)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, %v, want %q", tt.command, got, err, tt.want)
+		}
+	}
+	for _, command := range []string{`notify "unterminated`, `notify 'open`, `notify trailing\`} {
+		if _, err := splitCommand(command); err == nil {
+			t.Errorf("splitCommand(%q) should fail", command)
+		}
+	}
+}
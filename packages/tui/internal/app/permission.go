@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+)
+
+type Permission = opencode.EventListResponseEventPermissionUpdatedProperties
+
+type PermissionRespondedMsg struct {
+	Permission Permission
+	Response   opencode.PermissionRespondParamsResponse
+}
+
+// PermissionFailedMsg is sent when a response could not be delivered, which
+// leaves the server waiting on the request
+type PermissionFailedMsg struct {
+	Permission Permission
+}
+
+// AddPermission queues a pending permission request. The server keys pending
+// requests by session and permission ID, so a repeated request replaces the
+// queued one instead of being asked twice.
+func (a *App) AddPermission(permission Permission) {
+	index := slices.IndexFunc(a.Permissions, func(p Permission) bool {
+		return p.SessionID == permission.SessionID && p.ID == permission.ID
+	})
+	if index > -1 {
+		a.Permissions[index] = permission
+		return
+	}
+	a.Permissions = append(a.Permissions, permission)
+}
+
+// RestorePermission puts back a request whose response failed, ahead of the
+// others so it is asked again first
+func (a *App) RestorePermission(permission Permission) {
+	if slices.ContainsFunc(a.Permissions, func(p Permission) bool {
+		return p.SessionID == permission.SessionID && p.ID == permission.ID
+	}) {
+		return
+	}
+	a.Permissions = append([]Permission{permission}, a.Permissions...)
+}
+
+// RemovePermissions drops every queued permission belonging to the session
+func (a *App) RemovePermissions(sessionID string) {
+	a.Permissions = slices.DeleteFunc(a.Permissions, func(p Permission) bool {
+		return p.SessionID == sessionID
+	})
+}
+
+func (a *App) RespondToPermission(
+	ctx context.Context,
+	permission Permission,
+	response opencode.PermissionRespondParamsResponse,
+) tea.Cmd {
+	a.Permissions = slices.DeleteFunc(a.Permissions, func(p Permission) bool {
+		return p.SessionID == permission.SessionID && p.ID == permission.ID
+	})
+
+	return func() tea.Msg {
+		_, err := a.Client.Permission.Respond(
+			ctx,
+			permission.SessionID,
+			permission.ID,
+			opencode.PermissionRespondParams{
+				Response: opencode.F(response),
+			},
+		)
+		if err != nil {
+			slog.Error("Failed to respond to permission request", "error", err)
+			return PermissionFailedMsg{Permission: permission}
+		}
+		return PermissionRespondedMsg{Permission: permission, Response: response}
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import "testing"
+
+func permissionIDs(permissions []Permission) []string {
+	ids := []string{}
+	for _, permission := range permissions {
+		ids = append(ids, permission.SessionID+"/"+permission.ID)
+	}
+	return ids
+}
+
+func TestAddPermission(t *testing.T) {
+	a := &App{}
+	a.AddPermission(Permission{SessionID: "ses_1", ID: "bash", Title: "first"})
+	a.AddPermission(Permission{SessionID: "ses_2", ID: "bash", Title: "other session"})
+	a.AddPermission(Permission{SessionID: "ses_1", ID: "bash", Title: "repeated"})
+
+	if len(a.Permissions) != 2 {
+		t.Fatalf("expected a repeated request to replace the queued one, got %v", permissionIDs(a.Permissions))
+	}
+	if a.Permissions[0].Title != "repeated" || a.Permissions[1].SessionID != "ses_2" {
+		t.Errorf("expected the replaced request to keep its place, got %+v", a.Permissions)
+	}
+
+	a.RestorePermission(Permission{SessionID: "ses_3", ID: "edit"})
+	a.RestorePermission(Permission{SessionID: "ses_3", ID: "edit"})
+	if ids := permissionIDs(a.Permissions); len(ids) != 3 || ids[0] != "ses_3/edit" {
+		t.Errorf("expected a failed response to be asked again first, got %v", ids)
+	}
+}
+
+func TestRemovePermissions(t *testing.T) {
+	a := &App{}
+	a.AddPermission(Permission{SessionID: "ses_1", ID: "bash"})
+	a.AddPermission(Permission{SessionID: "ses_2", ID: "bash"})
+	a.AddPermission(Permission{SessionID: "ses_1", ID: "edit"})
+
+	a.RemovePermissions("ses_1")
+	if ids := permissionIDs(a.Permissions); len(ids) != 1 || ids[0] != "ses_2/bash" {
+		t.Errorf("expected only the other session's request to remain, got %v", ids)
+	}
+	a.RemovePermissions("ses_missing")
+	if len(a.Permissions) != 1 {
+		t.Error("removing an unknown session should keep the queue")
+	}
+}
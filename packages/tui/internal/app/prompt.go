@@ -0,0 +1,76 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/util"
+)
+
+// readToolMarker starts the synthetic part the server adds for every file
+// attached to a prompt
+const readToolMarker = "Called the Read tool with the following input: "
+
+// EditPromptMsg loads an earlier prompt into the editor. Sending it reverts
+// the session to before MessageID and sends the edited prompt in its place.
+type EditPromptMsg struct {
+	MessageID   string
+	Text        string
+	Attachments []opencode.FilePart
+}
+
+// PromptAttachments turns the files attached to a prompt back into parts
+// that can be sent again
+func PromptAttachments(message Message) []opencode.FilePartParam {
+	attachments := []opencode.FilePartParam{}
+	for _, file := range EditPrompt(message).Attachments {
+		attachments = append(attachments, opencode.FilePartParam{
+			Type:     opencode.F(opencode.FilePartTypeFile),
+			Mime:     opencode.F(file.Mime),
+			URL:      opencode.F(file.URL),
+			Filename: opencode.F(file.Filename),
+		})
+	}
+	return attachments
+}
+
+// EditPrompt collects the text and attachments of a prompt for editing. The
+// server inlines text files attached with @ as synthetic parts, so those are
+// turned back into file references.
+func EditPrompt(message Message) EditPromptMsg {
+	msg := EditPromptMsg{MessageID: message.ID(), Text: MessageText(message)}
+	for i, part := range message.Parts {
+		switch casted := part.(type) {
+		case opencode.FilePart:
+			msg.Attachments = append(msg.Attachments, casted)
+		case opencode.TextPart:
+			input, ok := strings.CutPrefix(casted.Text, readToolMarker)
+			if !casted.Synthetic || !ok {
+				continue
+			}
+			// binary files are followed by the file part holding their content
+			if i+1 < len(message.Parts) {
+				if _, ok := message.Parts[i+1].(opencode.FilePart); ok {
+					continue
+				}
+			}
+			var args struct {
+				FilePath string `json:"filePath"`
+			}
+			if err := json.Unmarshal([]byte(input), &args); err != nil || args.FilePath == "" {
+				continue
+			}
+			filePath := util.Relative(args.FilePath)
+			msg.Attachments = append(msg.Attachments, opencode.FilePart{
+				Type:     opencode.FilePartTypeFile,
+				Mime:     "text/plain",
+				URL:      fmt.Sprintf("file://./%s", url.PathEscape(filePath)),
+				Filename: filePath,
+			})
+		}
+	}
+	return msg
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/util"
+)
+
+func TestEditPrompt(t *testing.T) {
+	util.CwdPath = "/repo"
+	message := Message{
+		Info: opencode.UserMessage{ID: "msg_1"},
+		Parts: []opencode.PartUnion{
+			opencode.TextPart{Text: "explain @main.go and [Image #2]"},
+			opencode.TextPart{Synthetic: true, Text: readToolMarker + `{"filePath":"/repo/main.go"}`},
+			opencode.TextPart{Synthetic: true, Text: "package main"},
+			opencode.TextPart{Synthetic: true, Text: readToolMarker + `{"filePath":"/logo.png"}`},
+			opencode.FilePart{Mime: "image/png", URL: "data:image/png;base64,AA==", Filename: "logo.png"},
+		},
+	}
+	msg := EditPrompt(message)
+	if msg.MessageID != "msg_1" || msg.Text != "explain @main.go and [Image #2]" {
+		t.Errorf("unexpected prompt: %+v", msg)
+	}
+	if len(msg.Attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(msg.Attachments))
+	}
+	if file := msg.Attachments[0]; file.Filename != "main.go" || file.URL != "file://./main.go" || file.Mime != "text/plain" {
+		t.Errorf("expected the inlined text file to become a file reference, got %+v", file)
+	}
+	if file := msg.Attachments[1]; file.URL != "data:image/png;base64,AA==" {
+		t.Errorf("expected the image to keep its content, got %+v", file)
+	}
+}
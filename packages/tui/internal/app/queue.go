@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/config"
+)
+
+// PromptQueuedMsg adds a prompt submitted while the agent is busy to the
+// queue of the current session
+type PromptQueuedMsg struct {
+	Prompt config.PromptEntry
+}
+
+// PromptQueue holds the prompts submitted in a session while its agent was
+// busy. They are sent one at a time, each once the session goes idle.
+type PromptQueue struct {
+	Prompts []config.PromptEntry
+	// Paused holds the queue back after an interrupt until the user chooses
+	// to keep or discard it
+	Paused bool
+}
+
+// Queue returns the queue of the current session
+func (a *App) Queue() *PromptQueue {
+	if a.Queues == nil {
+		a.Queues = map[string]*PromptQueue{}
+	}
+	queue, ok := a.Queues[a.Session.ID]
+	if !ok {
+		queue = &PromptQueue{}
+		a.Queues[a.Session.ID] = queue
+	}
+	return queue
+}
+
+// QueuePrompt adds a prompt to the queue of the current session
+func (a *App) QueuePrompt(prompt config.PromptEntry) {
+	queue := a.Queue()
+	queue.Prompts = append(queue.Prompts, prompt)
+}
+
+// QueuedPrompts returns the prompts waiting to be sent in the current session
+func (a *App) QueuedPrompts() []config.PromptEntry {
+	if queue, ok := a.Queues[a.Session.ID]; ok {
+		return queue.Prompts
+	}
+	return nil
+}
+
+// RemoveQueued takes the prompt at index out of the queue of the current
+// session
+func (a *App) RemoveQueued(index int) (config.PromptEntry, bool) {
+	return a.removeQueued(a.Session.ID, index)
+}
+
+func (a *App) removeQueued(sessionID string, index int) (config.PromptEntry, bool) {
+	queue, ok := a.Queues[sessionID]
+	if !ok || index < 0 || index >= len(queue.Prompts) {
+		return config.PromptEntry{}, false
+	}
+	prompt := queue.Prompts[index]
+	queue.Prompts = slices.Delete(queue.Prompts, index, index+1)
+	return prompt, true
+}
+
+// ClearQueue discards every prompt queued in the current session
+func (a *App) ClearQueue() {
+	delete(a.Queues, a.Session.ID)
+}
+
+// SendQueued sends the next prompt queued in a session once it is idle: the
+// agent is not working, nothing is being compacted and the last prompt has
+// been answered. Sessions in background tabs are sent to without being shown.
+func (a *App) SendQueued(sessionID string) tea.Cmd {
+	queue, ok := a.Queues[sessionID]
+	if !ok || len(queue.Prompts) == 0 || queue.Paused {
+		return nil
+	}
+	if a.compaction != nil && a.compaction.SessionID == sessionID {
+		return nil
+	}
+	current := sessionID == a.Session.ID
+	if current && a.ReadOnly() {
+		return nil
+	}
+	var messages []Message
+	tab := a.Tab(sessionID)
+	if current {
+		messages = a.Messages
+	} else if tab != nil {
+		messages = tab.Messages
+	}
+	if isBusy(messages) {
+		return nil
+	}
+	if len(messages) > 0 {
+		if _, ok := messages[len(messages)-1].Info.(opencode.UserMessage); ok {
+			return nil
+		}
+	}
+
+	prompt, _ := a.removeQueued(sessionID, 0)
+	msg := PromptSendMsg(prompt)
+	if current {
+		_, cmd := a.SendChatMessage(context.Background(), msg.Text, msg.Attachments)
+		return cmd
+	}
+	message := newPromptMessage(sessionID, msg.Text, msg.Attachments)
+	if tab != nil {
+		tab.Messages = append(tab.Messages, message)
+	}
+	return func() tea.Msg {
+		if err := a.chat(context.Background(), message); err != nil {
+			slog.Error("Failed to send queued prompt", "session", sessionID, "error", err)
+			return toast.NewErrorToast(fmt.Sprintf("failed to send message: %v", err))()
+		}
+		return nil
+	}
+}
+
+// PromptSendMsg turns a saved prompt into the message sending it
+func PromptSendMsg(prompt config.PromptEntry) SendMsg {
+	attachments := []opencode.FilePartParam{}
+	for _, attachment := range prompt.Attachments {
+		attachments = append(attachments, opencode.FilePartParam{
+			Type:     opencode.F(opencode.FilePartTypeFile),
+			Mime:     opencode.F(attachment.Mime),
+			URL:      opencode.F(attachment.URL),
+			Filename: opencode.F(attachment.Filename),
+		})
+	}
+	return SendMsg{Text: prompt.Text, Attachments: attachments}
+}
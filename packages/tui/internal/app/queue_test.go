@@ -0,0 +1,126 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+)
+
+func TestPromptQueue(t *testing.T) {
+	a := &App{Session: &opencode.Session{ID: "ses_1"}, State: &config.State{}}
+	a.QueuePrompt(config.PromptEntry{Text: "one"})
+	a.QueuePrompt(config.PromptEntry{Text: "two"})
+	a.QueuePrompt(config.PromptEntry{Text: "three"})
+
+	prompt, ok := a.RemoveQueued(1)
+	if !ok || prompt.Text != "two" {
+		t.Fatalf("expected to remove the second prompt, got %q", prompt.Text)
+	}
+	if _, ok := a.RemoveQueued(5); ok {
+		t.Error("expected an out of range index to be ignored")
+	}
+	if queued := a.QueuedPrompts(); len(queued) != 2 || queued[0].Text != "one" || queued[1].Text != "three" {
+		t.Errorf("unexpected queue %+v", queued)
+	}
+
+	a.Session = &opencode.Session{ID: "ses_2"}
+	if len(a.QueuedPrompts()) != 0 {
+		t.Error("the queue of another session should not be shown")
+	}
+	a.QueuePrompt(config.PromptEntry{Text: "four"})
+	if queued := a.QueuedPrompts(); len(queued) != 1 || queued[0].Text != "four" {
+		t.Errorf("expected a new queue for the new session, got %+v", queued)
+	}
+	a.ClearQueue()
+	if len(a.QueuedPrompts()) != 0 {
+		t.Error("expected the queue to be cleared")
+	}
+	a.Session = &opencode.Session{ID: "ses_1"}
+	if len(a.QueuedPrompts()) != 2 {
+		t.Error("expected the queue of the first session to be kept")
+	}
+}
+
+func TestSendQueuedWaitsForIdle(t *testing.T) {
+	a := &App{
+		Session: &opencode.Session{ID: "ses_1"},
+		State:   &config.State{},
+		Messages: []Message{{Info: opencode.AssistantMessage{
+			Time: opencode.AssistantMessageTime{Created: 1},
+		}}},
+	}
+	if a.SendQueued("ses_1") != nil {
+		t.Error("nothing should be sent from an empty queue")
+	}
+	a.QueuePrompt(config.PromptEntry{Text: "next"})
+	if a.SendQueued("ses_1") != nil {
+		t.Error("nothing should be sent while the agent is busy")
+	}
+
+	a.Messages = []Message{{Info: opencode.UserMessage{ID: "msg_1"}}}
+	if a.SendQueued("ses_1") != nil {
+		t.Error("nothing should be sent before the last prompt is answered")
+	}
+
+	a.Messages = []Message{{Info: opencode.AssistantMessage{
+		Time: opencode.AssistantMessageTime{Created: 1, Completed: 2},
+	}}}
+	a.Queue().Paused = true
+	if a.SendQueued("ses_1") != nil {
+		t.Error("nothing should be sent while the queue is paused")
+	}
+	a.Queue().Paused = false
+	a.compaction = &Compaction{SessionID: "ses_1"}
+	if a.SendQueued("ses_1") != nil {
+		t.Error("nothing should be sent while the session is compacted")
+	}
+	if len(a.QueuedPrompts()) != 1 {
+		t.Error("held back prompts should stay queued")
+	}
+}
+
+func TestSendQueuedInBackground(t *testing.T) {
+	a := &App{
+		Session: &opencode.Session{ID: "ses_1"},
+		State:   &config.State{},
+		Tabs: []*SessionTab{{
+			Session: opencode.Session{ID: "ses_2"},
+			Messages: []Message{{Info: opencode.AssistantMessage{
+				Time: opencode.AssistantMessageTime{Created: 1, Completed: 2},
+			}}},
+		}},
+	}
+	a.Session = &opencode.Session{ID: "ses_2"}
+	a.QueuePrompt(config.PromptEntry{Text: "later"})
+	a.Session = &opencode.Session{ID: "ses_1"}
+
+	if a.SendQueued("ses_1") != nil {
+		t.Error("the queue of another session should not be sent")
+	}
+	if a.SendQueued("ses_2") == nil {
+		t.Fatal("expected the queue of the idle background session to be sent")
+	}
+	messages := a.Tab("ses_2").Messages
+	if user, ok := messages[len(messages)-1].Info.(opencode.UserMessage); !ok || user.SessionID != "ses_2" {
+		t.Errorf("expected the prompt to be added to its own session, got %+v", messages[len(messages)-1].Info)
+	}
+	if len(a.Queues["ses_2"].Prompts) != 0 || len(a.Messages) != 0 {
+		t.Error("expected the prompt to leave the queue without touching the current session")
+	}
+}
+
+func TestPromptSendMsg(t *testing.T) {
+	msg := PromptSendMsg(config.PromptEntry{
+		Text: "look at this",
+		Attachments: []config.PromptAttachment{
+			{Mime: "image/png", URL: "file:///tmp/a.png", Filename: "a.png"},
+		},
+	})
+	if msg.Text != "look at this" || len(msg.Attachments) != 1 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if attachment := msg.Attachments[0]; attachment.URL.Value != "file:///tmp/a.png" || attachment.Mime.Value != "image/png" {
+		t.Errorf("unexpected attachment %+v", attachment)
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+type SessionRevertedMsg struct {
+	Session *opencode.Session
+	// Resend is sent once the revert is applied, when the revert was made
+	// to replace an edited prompt
+	Resend *SendMsg
+}
+
+// RevertPreview describes what reverting to before a message would undo
+type RevertPreview struct {
+	// Snapshot is the snapshot the workspace is restored to, empty when none
+	// was recorded
+	Snapshot string
+	// Files are the files changed by tools from the revert point onwards
+	Files []string
+	// Messages is the number of messages that will be dropped
+	Messages int
+}
+
+// PreviewRevert collects the snapshot and touched files from the message at
+// index onwards
+func (a *App) PreviewRevert(index int) RevertPreview {
+	preview := RevertPreview{Files: []string{}}
+	if index < 0 || index >= len(a.Messages) {
+		return preview
+	}
+	preview.Messages = len(a.Messages) - index
+	for _, message := range a.Messages[index:] {
+		for _, part := range message.Parts {
+			switch casted := part.(type) {
+			case opencode.SnapshotPart:
+				if preview.Snapshot == "" {
+					preview.Snapshot = casted.Snapshot
+				}
+			case opencode.ToolPart:
+				for _, filePath := range toolFiles(casted) {
+					if !slices.Contains(preview.Files, filePath) {
+						preview.Files = append(preview.Files, filePath)
+					}
+				}
+			}
+		}
+	}
+	return preview
+}
+
+// patchFileHeaders mark the files a patch adds, updates or deletes
+var patchFileHeaders = []string{"*** Add File:", "*** Update File:", "*** Delete File:"}
+
+// toolFiles returns the files a tool call changes. The patch tool names them
+// in the headers of its patch text rather than in a filePath argument.
+func toolFiles(part opencode.ToolPart) []string {
+	input, ok := part.State.Input.(map[string]any)
+	if !ok {
+		return nil
+	}
+	switch part.Tool {
+	case "edit", "multiedit", "write":
+		if filePath, ok := input["filePath"].(string); ok && filePath != "" {
+			return []string{filePath}
+		}
+	case "patch":
+		patchText, _ := input["patchText"].(string)
+		files := []string{}
+		for line := range strings.SplitSeq(patchText, "\n") {
+			for _, header := range patchFileHeaders {
+				if filePath, ok := strings.CutPrefix(line, header); ok && strings.TrimSpace(filePath) != "" {
+					files = append(files, strings.TrimSpace(filePath))
+				}
+			}
+		}
+		return files
+	}
+	return nil
+}
+
+// RevertMessage reverts the session to before the given message. The server
+// restores the workspace from the recorded snapshot and keeps the later
+// messages around until the next prompt, so the revert can still be undone.
+func (a *App) RevertMessage(ctx context.Context, messageID string) tea.Cmd {
+	sessionID := a.Session.ID
+	return func() tea.Msg {
+		session, err := a.Client.Session.Revert(ctx, sessionID, opencode.SessionRevertParams{
+			MessageID: opencode.F(messageID),
+			Part:      opencode.F(0.0),
+		})
+		if err != nil {
+			slog.Error("Failed to revert session", "error", err)
+			return toast.NewErrorToast("Failed to revert session")()
+		}
+		return SessionRevertedMsg{Session: session}
+	}
+}
+
+// ResendPrompt reverts the session to before the prompt msg replaces and then
+// sends msg in its place. The edited prompt goes back into the editor with its
+// attachments if the revert fails.
+func (a *App) ResendPrompt(ctx context.Context, msg SendMsg) tea.Cmd {
+	revert := a.RevertMessage(ctx, msg.Revert)
+	return func() tea.Msg {
+		result := revert()
+		reverted, ok := result.(SessionRevertedMsg)
+		if !ok {
+			return tea.BatchMsg{
+				func() tea.Msg { return result },
+				func() tea.Msg { return resendFailed(msg) },
+			}
+		}
+		msg.Revert = ""
+		reverted.Resend = &msg
+		return reverted
+	}
+}
+
+// resendFailed restores the edit of the prompt msg was meant to replace
+func resendFailed(msg SendMsg) EditPromptMsg {
+	edit := EditPromptMsg{MessageID: msg.Revert, Text: msg.Text}
+	for _, attachment := range msg.Attachments {
+		edit.Attachments = append(edit.Attachments, opencode.FilePart{
+			Type:     opencode.FilePartTypeFile,
+			Mime:     attachment.Mime.Value,
+			URL:      attachment.URL.Value,
+			Filename: attachment.Filename.Value,
+		})
+	}
+	return edit
+}
+
+// UnrevertSession undoes the last revert, which is only possible while no new
+// prompt has been sent
+func (a *App) UnrevertSession(ctx context.Context) tea.Cmd {
+	if a.Session.Revert.MessageID == "" {
+		return toast.NewInfoToast("Nothing to unrevert")
+	}
+	sessionID := a.Session.ID
+	return func() tea.Msg {
+		_, err := a.Client.Session.Unrevert(ctx, sessionID)
+		if err != nil {
+			slog.Error("Failed to unrevert session", "error", err)
+			return toast.NewErrorToast("Failed to unrevert session")()
+		}
+		if resync := a.ResyncSession(ctx); resync != nil {
+			return resync()
+		}
+		return nil
+	}
+}
+
+// TrimReverted drops the messages hidden by a pending revert
+func TrimReverted(messages []Message, revert opencode.SessionRevert) []Message {
+	if revert.MessageID == "" {
+		return messages
+	}
+	trimmed := []Message{}
+	for _, message := range messages {
+		id := message.ID()
+		if id > revert.MessageID || (id == revert.MessageID && revert.Part == 0) {
+			break
+		}
+		if id == revert.MessageID {
+			message.Parts = message.Parts[:min(int(revert.Part), len(message.Parts))]
+		}
+		trimmed = append(trimmed, message)
+	}
+	return trimmed
+}
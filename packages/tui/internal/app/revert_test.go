@@ -0,0 +1,124 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func partsMessage(id string, parts int) Message {
+	message := Message{Info: opencode.UserMessage{ID: id}}
+	for i := range parts {
+		message.Parts = append(message.Parts, opencode.TextPart{ID: id + "_" + string(rune('a'+i))})
+	}
+	return message
+}
+
+func TestTrimReverted(t *testing.T) {
+	messages := []Message{partsMessage("msg_1", 2), partsMessage("msg_2", 3), partsMessage("msg_3", 1)}
+	tests := []struct {
+		name   string
+		revert opencode.SessionRevert
+		ids    []string
+		parts  int
+	}{
+		{name: "no revert", revert: opencode.SessionRevert{}, ids: []string{"msg_1", "msg_2", "msg_3"}, parts: 1},
+		{name: "whole message", revert: opencode.SessionRevert{MessageID: "msg_2"}, ids: []string{"msg_1"}, parts: 2},
+		{name: "within a message", revert: opencode.SessionRevert{MessageID: "msg_2", Part: 2}, ids: []string{"msg_1", "msg_2"}, parts: 2},
+		{name: "past the last part", revert: opencode.SessionRevert{MessageID: "msg_2", Part: 9}, ids: []string{"msg_1", "msg_2"}, parts: 3},
+		{name: "first message", revert: opencode.SessionRevert{MessageID: "msg_1"}, ids: []string{}, parts: 0},
+		{name: "missing message", revert: opencode.SessionRevert{MessageID: "msg_25"}, ids: []string{"msg_1", "msg_2"}, parts: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trimmed := TrimReverted(messages, test.revert)
+			ids := []string{}
+			for _, message := range trimmed {
+				ids = append(ids, message.ID())
+			}
+			if !slices.Equal(ids, test.ids) {
+				t.Fatalf("expected %v, got %v", test.ids, ids)
+			}
+			if len(trimmed) > 0 && len(trimmed[len(trimmed)-1].Parts) != test.parts {
+				t.Errorf("expected %d parts in the last message, got %d", test.parts, len(trimmed[len(trimmed)-1].Parts))
+			}
+		})
+	}
+	if len(messages[1].Parts) != 3 {
+		t.Error("trimming should not change the parts of the original messages")
+	}
+}
+
+func toolMessage(id string, tools ...opencode.ToolPart) Message {
+	message := Message{Info: opencode.AssistantMessage{ID: id}}
+	for _, tool := range tools {
+		message.Parts = append(message.Parts, tool)
+	}
+	return message
+}
+
+func toolCall(tool string, input map[string]any) opencode.ToolPart {
+	return opencode.ToolPart{Tool: tool, State: opencode.ToolPartState{Input: input}}
+}
+
+func TestPreviewRevert(t *testing.T) {
+	a := &App{Messages: []Message{
+		toolMessage("msg_1", toolCall("edit", map[string]any{"filePath": "/a.go"})),
+		textMessage("msg_2", "change more"),
+		{
+			Info: opencode.AssistantMessage{ID: "msg_3"},
+			Parts: []opencode.PartUnion{
+				opencode.SnapshotPart{Snapshot: "abc"},
+				toolCall("read", map[string]any{"filePath": "/read.go"}),
+				toolCall("write", map[string]any{"filePath": "/b.go"}),
+				toolCall("patch", map[string]any{"patchText": "*** Begin Patch\n" +
+					"*** Update File: /b.go\n@@ func b\n-old\n+new\n" +
+					"*** Add File: /c.go\n+package c\n" +
+					"*** Delete File: /d.go\n*** End Patch"}),
+				opencode.SnapshotPart{Snapshot: "later"},
+			},
+		},
+		toolMessage("msg_4", toolCall("multiedit", map[string]any{"filePath": "/e.go"})),
+	}}
+
+	tests := []struct {
+		name     string
+		index    int
+		snapshot string
+		files    []string
+		messages int
+	}{
+		{name: "from the start", index: 0, snapshot: "abc", files: []string{"/a.go", "/b.go", "/c.go", "/d.go", "/e.go"}, messages: 4},
+		{name: "from a prompt", index: 1, snapshot: "abc", files: []string{"/b.go", "/c.go", "/d.go", "/e.go"}, messages: 3},
+		{name: "without snapshot", index: 3, snapshot: "", files: []string{"/e.go"}, messages: 1},
+		{name: "out of range", index: 4, snapshot: "", files: []string{}, messages: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			preview := a.PreviewRevert(test.index)
+			if preview.Snapshot != test.snapshot || preview.Messages != test.messages || !slices.Equal(preview.Files, test.files) {
+				t.Errorf("expected %s %v %d, got %+v", test.snapshot, test.files, test.messages, preview)
+			}
+		})
+	}
+}
+
+func TestResendFailed(t *testing.T) {
+	edit := resendFailed(SendMsg{
+		Text:   "look at @main.go",
+		Revert: "msg_2",
+		Attachments: []opencode.FilePartParam{{
+			Type:     opencode.F(opencode.FilePartTypeFile),
+			Mime:     opencode.F("text/plain"),
+			URL:      opencode.F("file://./main.go"),
+			Filename: opencode.F("main.go"),
+		}},
+	})
+	if edit.MessageID != "msg_2" || edit.Text != "look at @main.go" {
+		t.Fatalf("expected the edit of msg_2 to be restored, got %+v", edit)
+	}
+	if len(edit.Attachments) != 1 || edit.Attachments[0].URL != "file://./main.go" || edit.Attachments[0].Filename != "main.go" {
+		t.Errorf("expected the attachment to be restored, got %+v", edit.Attachments)
+	}
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+)
+
+const (
+	// sessionIndexBatch is how many sessions are indexed per SessionsIndexedMsg
+	sessionIndexBatch = 8
+	// sessionIndexTextLimit caps the message text kept per session for search
+	sessionIndexTextLimit = 16 * 1024
+)
+
+// SessionSummary holds the per session details the session browser shows and
+// searches, derived from the session's messages
+type SessionSummary struct {
+	// Updated is the session update time the summary was computed at, so
+	// sessions that changed since are indexed again
+	Updated  float64
+	Messages int
+	Cost     float64
+	// Text is the lower cased text of the conversation
+	Text string
+}
+
+// SessionsIndexedMsg is sent after each batch of sessions has been indexed.
+// Remaining holds the sessions still to be indexed.
+type SessionsIndexedMsg struct {
+	Remaining []opencode.Session
+}
+
+// SessionIndex caches session summaries for the lifetime of the app
+type SessionIndex struct {
+	mu      sync.RWMutex
+	entries map[string]SessionSummary
+}
+
+func NewSessionIndex() *SessionIndex {
+	return &SessionIndex{entries: map[string]SessionSummary{}}
+}
+
+// Get returns the summary of a session, if it has been indexed
+func (i *SessionIndex) Get(sessionID string) (SessionSummary, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	summary, ok := i.entries[sessionID]
+	return summary, ok
+}
+
+func (i *SessionIndex) set(sessionID string, summary SessionSummary) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.entries[sessionID] = summary
+}
+
+// Stale returns the sessions that were never indexed or changed since
+func (i *SessionIndex) Stale(sessions []opencode.Session) []opencode.Session {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	var stale []opencode.Session
+	for _, session := range sessions {
+		if summary, ok := i.entries[session.ID]; !ok || summary.Updated < session.Time.Updated {
+			stale = append(stale, session)
+		}
+	}
+	return stale
+}
+
+// Summarize computes the summary of a conversation
+func Summarize(messages []Message) SessionSummary {
+	summary := SessionSummary{Messages: len(messages)}
+	var text strings.Builder
+	for _, message := range messages {
+		if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
+			summary.Cost += assistant.Cost
+		}
+		for _, part := range message.Parts {
+			if textPart, ok := part.(opencode.TextPart); ok && !textPart.Synthetic &&
+				text.Len() < sessionIndexTextLimit {
+				text.WriteString(textPart.Text)
+				text.WriteString("\n")
+			}
+		}
+	}
+	summary.Text = strings.ToLower(text.String())
+	if len(summary.Text) > sessionIndexTextLimit {
+		summary.Text = strings.ToValidUTF8(summary.Text[:sessionIndexTextLimit], "")
+	}
+	return summary
+}
+
+// IndexSessions indexes the next batch of sessions. Callers keep indexing
+// the remaining sessions from the SessionsIndexedMsg until none are left.
+func (a *App) IndexSessions(ctx context.Context, sessions []opencode.Session) tea.Cmd {
+	if len(sessions) == 0 {
+		return nil
+	}
+	batch := sessions[:min(sessionIndexBatch, len(sessions))]
+	remaining := sessions[len(batch):]
+	return func() tea.Msg {
+		var wg sync.WaitGroup
+		for _, session := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				messages, err := a.ListMessages(ctx, session.ID)
+				if err != nil {
+					slog.Error("Failed to index session", "session", session.ID, "error", err)
+					return
+				}
+				summary := Summarize(messages)
+				summary.Updated = session.Time.Updated
+				a.SessionIndex.set(session.ID, summary)
+			}()
+		}
+		wg.Wait()
+		return SessionsIndexedMsg{Remaining: remaining}
+	}
+}
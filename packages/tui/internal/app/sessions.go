@@ -0,0 +1,127 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// SessionNode is a session placed in the parent/child hierarchy created by
+// the task tool
+type SessionNode struct {
+	Session opencode.Session
+	// Depth is 0 for top level sessions
+	Depth int
+}
+
+// SessionTree orders sessions depth first so children follow their parent.
+// Top level sessions keep their given order, children are oldest first.
+// Sessions whose parent is missing are treated as top level.
+func SessionTree(sessions []opencode.Session) []SessionNode {
+	known := map[string]bool{}
+	for _, session := range sessions {
+		known[session.ID] = true
+	}
+	children := map[string][]opencode.Session{}
+	var roots []opencode.Session
+	for _, session := range sessions {
+		if session.ParentID == "" || !known[session.ParentID] {
+			roots = append(roots, session)
+			continue
+		}
+		children[session.ParentID] = append(children[session.ParentID], session)
+	}
+
+	var nodes []SessionNode
+	var walk func(session opencode.Session, depth int)
+	walk = func(session opencode.Session, depth int) {
+		nodes = append(nodes, SessionNode{Session: session, Depth: depth})
+		descendants := children[session.ID]
+		slices.SortStableFunc(descendants, func(a, b opencode.Session) int {
+			return cmp.Compare(a.Time.Created, b.Time.Created)
+		})
+		for _, child := range descendants {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return nodes
+}
+
+// ChildSessionID returns the session a task tool call runs its sub-agent in,
+// or an empty string for other tools and tasks that have not started yet
+func ChildSessionID(part opencode.ToolPart) string {
+	if part.Tool != "task" {
+		return ""
+	}
+	metadata, _ := part.State.Metadata.(map[string]any)
+	if id, ok := metadata["sessionID"].(string); ok && id != "" {
+		return id
+	}
+	// older servers only report the child session through its tool calls
+	summary, _ := metadata["summary"].([]any)
+	for _, item := range summary {
+		if call, ok := item.(map[string]any); ok {
+			if id, ok := call["sessionID"].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// TaskCall is a task tool call of the current session and the sub-agent
+// session behind it
+type TaskCall struct {
+	Part      opencode.ToolPart
+	SessionID string
+}
+
+// TaskCalls lists the task tool calls of the current session, oldest first
+func (a *App) TaskCalls() []TaskCall {
+	var calls []TaskCall
+	for _, message := range a.Messages {
+		for _, part := range message.Parts {
+			if tool, ok := part.(opencode.ToolPart); ok && tool.Tool == "task" {
+				calls = append(calls, TaskCall{Part: tool, SessionID: ChildSessionID(tool)})
+			}
+		}
+	}
+	return calls
+}
+
+// GetSession fetches a session by ID
+func (a *App) GetSession(ctx context.Context, sessionID string) (*opencode.Session, error) {
+	return a.Client.Session.Get(ctx, sessionID)
+}
+
+// OpenSession loads a session by ID and switches to it
+func (a *App) OpenSession(ctx context.Context, sessionID string) tea.Cmd {
+	return func() tea.Msg {
+		session, err := a.GetSession(ctx, sessionID)
+		if err != nil {
+			return toast.NewErrorToast("Failed to open session: " + err.Error())()
+		}
+		return SessionSelectedMsg(session)
+	}
+}
+
+// RenameSession sets the title of a session. The server confirms the change
+// with a session.updated event.
+func (a *App) RenameSession(ctx context.Context, sessionID string, title string) (*opencode.Session, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("session title cannot be empty")
+	}
+	return a.Client.Session.Update(ctx, sessionID, opencode.SessionUpdateParams{
+		Title: opencode.F(title),
+	})
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func TestSessionTree(t *testing.T) {
+	session := func(id, parent string, created float64) opencode.Session {
+		return opencode.Session{ID: id, ParentID: parent, Time: opencode.SessionTime{Created: created}}
+	}
+	nodes := SessionTree([]opencode.Session{
+		session("ses_b", "", 5),
+		session("ses_b2", "ses_b", 6.7),
+		session("ses_b1", "ses_b", 6.2),
+		session("ses_a", "", 1),
+		session("ses_b1a", "ses_b1", 8),
+		session("ses_orphan", "ses_gone", 9),
+	})
+
+	var got []string
+	for _, node := range nodes {
+		got = append(got, node.Session.ID+":"+string(rune('0'+node.Depth)))
+	}
+	want := []string{"ses_b:0", "ses_b1:1", "ses_b1a:2", "ses_b2:1", "ses_a:0", "ses_orphan:0"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestChildSessionID(t *testing.T) {
+	decode := func(raw string) opencode.ToolPart {
+		var part opencode.Part
+		if err := json.Unmarshal([]byte(raw), &part); err != nil {
+			t.Fatal(err)
+		}
+		return part.AsUnion().(opencode.ToolPart)
+	}
+
+	current := decode(`{"id":"prt_1","type":"tool","tool":"task","callID":"c","messageID":"m","sessionID":"ses_1",
+		"state":{"status":"running","input":{},"time":{"start":1},"metadata":{"sessionID":"ses_child"}}}`)
+	if id := ChildSessionID(current); id != "ses_child" {
+		t.Errorf("expected the child session from metadata, got %q", id)
+	}
+
+	legacy := decode(`{"id":"prt_1","type":"tool","tool":"task","callID":"c","messageID":"m","sessionID":"ses_1",
+		"state":{"status":"completed","input":{},"output":"","title":"t","time":{"start":1,"end":2},
+		"metadata":{"summary":[{"id":"prt_9","sessionID":"ses_child","tool":"bash"}]}}}`)
+	if id := ChildSessionID(legacy); id != "ses_child" {
+		t.Errorf("expected the child session from the summary, got %q", id)
+	}
+
+	bash := decode(`{"id":"prt_1","type":"tool","tool":"bash","callID":"c","messageID":"m","sessionID":"ses_1",
+		"state":{"status":"running","input":{},"time":{"start":1},"metadata":{"sessionID":"ses_child"}}}`)
+	if id := ChildSessionID(bash); id != "" {
+		t.Errorf("expected no child session for other tools, got %q", id)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	messages := []Message{
+		{
+			Info: opencode.UserMessage{ID: "msg_1"},
+			Parts: []opencode.PartUnion{
+				opencode.TextPart{Text: "Fix the LOGIN page"},
+				opencode.TextPart{Text: "hidden context", Synthetic: true},
+			},
+		},
+		{Info: opencode.AssistantMessage{ID: "msg_2", Cost: 0.5}},
+		{Info: opencode.AssistantMessage{ID: "msg_3", Cost: 0.25}},
+	}
+	summary := Summarize(messages)
+	if summary.Messages != 3 || summary.Cost != 0.75 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary.Text != "fix the login page\n" {
+		t.Errorf("expected lower cased text without synthetic parts, got %q", summary.Text)
+	}
+
+	index := NewSessionIndex()
+	index.set("ses_1", SessionSummary{Updated: 5})
+	stale := index.Stale([]opencode.Session{
+		{ID: "ses_1", Time: opencode.SessionTime{Updated: 5}},
+		{ID: "ses_2", Time: opencode.SessionTime{Updated: 1}},
+		{ID: "ses_1", Time: opencode.SessionTime{Updated: 6}},
+	})
+	if len(stale) != 2 || stale[0].ID != "ses_2" || stale[1].Time.Updated != 6 {
+		t.Errorf("expected new and updated sessions to be stale, got %v", stale)
+	}
+}
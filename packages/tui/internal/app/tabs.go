@@ -0,0 +1,210 @@
+package app
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+)
+
+// SessionTab is a session open in the tab bar. Its messages are kept current
+// from the event stream while another tab is shown, so switching back does
+// not reload them.
+type SessionTab struct {
+	Session  opencode.Session
+	Messages []Message
+	// Error is the last error reported for the session, cleared by the next
+	// prompt
+	Error *ErrorInfo
+	// Unread is set when a background session changes, until it is shown
+	Unread bool
+}
+
+// Busy reports whether the agent is working in the session
+func (t *SessionTab) Busy() bool {
+	return isBusy(t.Messages)
+}
+
+// Tab returns the open tab of a session, or nil
+func (a *App) Tab(sessionID string) *SessionTab {
+	index := a.tabIndex(sessionID)
+	if index == -1 {
+		return nil
+	}
+	return a.Tabs[index]
+}
+
+// ActiveTab returns the index of the tab shown, or -1 when the current
+// session has no tab, as for a new session or an imported transcript
+func (a *App) ActiveTab() int {
+	if a.Session == nil || a.Session.ID == "" || a.ReadOnly() {
+		return -1
+	}
+	return a.tabIndex(a.Session.ID)
+}
+
+func (a *App) tabIndex(sessionID string) int {
+	return slices.IndexFunc(a.Tabs, func(tab *SessionTab) bool {
+		return tab.Session.ID == sessionID
+	})
+}
+
+// OpenTab adds a tab for the current session unless it has one
+func (a *App) OpenTab() {
+	if a.Session == nil || a.Session.ID == "" || a.ReadOnly() || a.tabIndex(a.Session.ID) != -1 {
+		return
+	}
+	a.Tabs = append(a.Tabs, &SessionTab{Session: *a.Session})
+}
+
+// SaveTab copies the current session into its tab, so it is shown as it was
+// when switching back
+func (a *App) SaveTab() {
+	index := a.ActiveTab()
+	if index == -1 {
+		return
+	}
+	tab := a.Tabs[index]
+	tab.Session = *a.Session
+	tab.Messages = slices.Clone(a.Messages)
+	tab.Unread = false
+}
+
+// ShowSession replaces the current session with one just loaded from the
+// server and opens a tab for it
+func (a *App) ShowSession(session *opencode.Session, messages []Message) {
+	a.SaveTab()
+	a.Session = session
+	a.Messages = messages
+	a.ImportedFrom = ""
+	a.OpenTab()
+}
+
+// SwitchTab shows the open tab of a session from its own message store,
+// reporting false when the session has no tab. session replaces the stored
+// info when given.
+func (a *App) SwitchTab(sessionID string, session *opencode.Session) bool {
+	tab := a.Tab(sessionID)
+	if tab == nil {
+		return false
+	}
+	a.SaveTab()
+	if session != nil {
+		tab.Session = *session
+	}
+	info := tab.Session
+	a.Session = &info
+	a.Messages = slices.Clone(TrimReverted(tab.Messages, info.Revert))
+	a.ImportedFrom = ""
+	tab.Unread = false
+	return true
+}
+
+// ResyncTab replaces the messages of a background tab with the ones reloaded
+// from the server, closing the tab when the session no longer exists. It
+// reports false when the session has no background tab.
+func (a *App) ResyncTab(msg SessionResyncedMsg) bool {
+	index := a.tabIndex(msg.SessionID)
+	if index == -1 || index == a.ActiveTab() {
+		return false
+	}
+	if msg.Session == nil {
+		a.Tabs = slices.Delete(a.Tabs, index, index+1)
+		return true
+	}
+	tab := a.Tabs[index]
+	if !DiffMessages(tab.Messages, msg.Messages).Empty() {
+		tab.Unread = true
+	}
+	tab.Session = *msg.Session
+	tab.Messages = msg.Messages
+	return true
+}
+
+// CycleTab returns the session of the tab after the current one, or before
+// it when forward is false, or nil when there is nowhere to go
+func (a *App) CycleTab(forward bool) *opencode.Session {
+	if len(a.Tabs) == 0 {
+		return nil
+	}
+	index := a.ActiveTab()
+	switch {
+	case index == -1 && forward:
+		index = 0
+	case index == -1:
+		index = len(a.Tabs) - 1
+	case len(a.Tabs) == 1:
+		return nil
+	case forward:
+		index = (index + 1) % len(a.Tabs)
+	default:
+		index = (index - 1 + len(a.Tabs)) % len(a.Tabs)
+	}
+	session := a.Tabs[index].Session
+	return &session
+}
+
+// CloseTab removes the tab of a session. When it is the one shown, the
+// session of the neighbouring tab is returned to show instead; nil then
+// means no tab is left.
+func (a *App) CloseTab(sessionID string) (next *opencode.Session, active bool) {
+	index := a.tabIndex(sessionID)
+	if index == -1 {
+		return nil, false
+	}
+	active = index == a.ActiveTab()
+	a.Tabs = slices.Delete(a.Tabs, index, index+1)
+	if !active || len(a.Tabs) == 0 {
+		return nil, active
+	}
+	session := a.Tabs[min(index, len(a.Tabs)-1)].Session
+	return &session, true
+}
+
+// UpdateTabs applies an event from the shared stream to the open tabs. The
+// messages of the current session are left to the caller; those of the
+// background tabs are updated here and the tab marked unread.
+func (a *App) UpdateTabs(msg tea.Msg) {
+	background := func(sessionID string) *SessionTab {
+		if sessionID == a.Session.ID && !a.ReadOnly() {
+			return nil
+		}
+		return a.Tab(sessionID)
+	}
+
+	switch msg := msg.(type) {
+	case opencode.EventListResponseEventSessionUpdated:
+		if tab := a.Tab(msg.Properties.Info.ID); tab != nil {
+			tab.Session = msg.Properties.Info
+		}
+	case opencode.EventListResponseEventSessionDeleted:
+		if index := a.tabIndex(msg.Properties.Info.ID); index != -1 {
+			a.Tabs = slices.Delete(a.Tabs, index, index+1)
+		}
+	case opencode.EventListResponseEventSessionError:
+		// an interrupt aborts the session on purpose and is not flagged
+		info := SessionErrorInfo(msg.Properties.Error)
+		if tab := a.Tab(msg.Properties.SessionID); tab != nil && info != nil && !info.Aborted() {
+			tab.Error = info
+			tab.Unread = tab.Unread || background(msg.Properties.SessionID) != nil
+		}
+	case opencode.EventListResponseEventMessageUpdated:
+		info := msg.Properties.Info
+		if tab := a.Tab(info.SessionID); tab != nil && info.Role == opencode.MessageRoleUser {
+			tab.Error = nil
+		}
+		if tab := background(info.SessionID); tab != nil {
+			tab.Messages = UpdateMessage(tab.Messages, info.AsUnion())
+			tab.Unread = true
+		}
+	case opencode.EventListResponseEventMessagePartUpdated:
+		if tab := background(msg.Properties.Part.SessionID); tab != nil {
+			tab.Messages = UpdatePart(tab.Messages, msg.Properties.Part)
+			tab.Unread = true
+		}
+	case opencode.EventListResponseEventMessageRemoved:
+		if tab := background(msg.Properties.SessionID); tab != nil {
+			tab.Messages, _ = removeMessage(tab.Messages, msg.Properties.MessageID)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+)
+
+func decodeEvent(t *testing.T, raw string) any {
+	t.Helper()
+	var event opencode.EventListResponse
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatal(err)
+	}
+	return event.AsUnion()
+}
+
+func TestBackgroundTabs(t *testing.T) {
+	a := &App{State: &config.State{}}
+	a.ShowSession(&opencode.Session{ID: "ses_1", Title: "first"}, []Message{})
+	a.ShowSession(&opencode.Session{ID: "ses_2", Title: "second"}, []Message{})
+	if len(a.Tabs) != 2 || a.ActiveTab() != 1 {
+		t.Fatalf("expected two tabs with the second shown, got %d tabs, active %d", len(a.Tabs), a.ActiveTab())
+	}
+
+	a.UpdateTabs(decodeEvent(t, `{"type":"message.updated","properties":{"info":
+		{"id":"msg_1","role":"assistant","sessionID":"ses_1","modelID":"m","providerID":"p","mode":"build",
+		 "system":[],"cost":0,"path":{"cwd":"/","root":"/"},"time":{"created":1},
+		 "tokens":{"input":0,"output":0,"reasoning":0,"cache":{"read":0,"write":0}}}}}`))
+	a.UpdateTabs(decodeEvent(t, `{"type":"message.part.updated","properties":{"part":
+		{"id":"prt_1","type":"text","text":"working on it","messageID":"msg_1","sessionID":"ses_1"}}}`))
+
+	background := a.Tab("ses_1")
+	if len(background.Messages) != 1 || len(background.Messages[0].Parts) != 1 {
+		t.Fatalf("expected the background tab to collect the message, got %+v", background.Messages)
+	}
+	if !background.Busy() || !background.Unread {
+		t.Error("expected the background tab to be busy and unread")
+	}
+	if len(a.Messages) != 0 || a.Tab("ses_2").Unread {
+		t.Error("events of a background session should not reach the current one")
+	}
+
+	a.UpdateTabs(decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_1",
+		"error":{"name":"UnknownError","data":{"message":"boom"}}}}`))
+	if background.Error == nil || background.Error.Message != "boom" {
+		t.Errorf("expected the error to be flagged, got %+v", background.Error)
+	}
+	a.UpdateTabs(decodeEvent(t, `{"type":"session.error","properties":{"sessionID":"ses_2",
+		"error":{"name":"MessageAbortedError","data":{}}}}`))
+	if a.Tab("ses_2").Error != nil {
+		t.Error("an interrupt should not be flagged as an error")
+	}
+
+	a.Messages = append(a.Messages, Message{Info: opencode.UserMessage{ID: "msg_2", SessionID: "ses_2"}})
+	if !a.SwitchTab("ses_1", nil) {
+		t.Fatal("expected to switch to the open tab")
+	}
+	if a.Session.ID != "ses_1" || len(a.Messages) != 1 || background.Unread {
+		t.Errorf("expected the stored messages to be shown and marked read, got %s with %d messages", a.Session.ID, len(a.Messages))
+	}
+	if len(a.Tab("ses_2").Messages) != 1 {
+		t.Error("expected the messages of the tab left behind to be kept")
+	}
+	a.Messages[0] = Message{Info: opencode.UserMessage{ID: "msg_4", SessionID: "ses_1"}}
+	if background.Messages[0].Info.(opencode.AssistantMessage).ID != "msg_1" {
+		t.Error("expected the shown messages not to share the store of the tab")
+	}
+	a.Messages[0] = background.Messages[0]
+
+	a.UpdateTabs(decodeEvent(t, `{"type":"message.updated","properties":{"info":
+		{"id":"msg_3","role":"user","sessionID":"ses_1","time":{"created":2}}}}`))
+	if background.Error != nil {
+		t.Error("expected the next prompt to clear the error")
+	}
+
+	if next := a.CycleTab(true); next == nil || next.ID != "ses_2" {
+		t.Errorf("expected the next tab to wrap around to ses_2, got %+v", next)
+	}
+	next, active := a.CloseTab("ses_1")
+	if !active || next == nil || next.ID != "ses_2" {
+		t.Errorf("expected closing the current tab to show ses_2, got %+v", next)
+	}
+	if next, active := a.CloseTab("ses_2"); next != nil || active {
+		t.Error("closing a background tab should not change the current session")
+	}
+	if len(a.Tabs) != 0 {
+		t.Errorf("expected every tab to be closed, got %d", len(a.Tabs))
+	}
+}
+
+func TestResyncTab(t *testing.T) {
+	a := &App{State: &config.State{}}
+	a.ShowSession(&opencode.Session{ID: "ses_1"}, []Message{partsMessage("msg_1", 1)})
+	a.ShowSession(&opencode.Session{ID: "ses_2"}, []Message{})
+	a.ShowSession(&opencode.Session{ID: "ses_3"}, []Message{})
+
+	reverted := opencode.Session{ID: "ses_1", Revert: opencode.SessionRevert{MessageID: "msg_2"}}
+	if !a.ResyncTab(SessionResyncedMsg{
+		SessionID: "ses_1",
+		Session:   &reverted,
+		Messages:  []Message{partsMessage("msg_1", 1), partsMessage("msg_2", 1)},
+	}) {
+		t.Fatal("expected the background tab to be resynced")
+	}
+	if tab := a.Tab("ses_1"); len(tab.Messages) != 2 || !tab.Unread {
+		t.Errorf("expected the reloaded messages to be stored and marked unread, got %d", len(tab.Messages))
+	}
+	if a.ResyncTab(SessionResyncedMsg{SessionID: "ses_3", Session: &opencode.Session{ID: "ses_3"}}) {
+		t.Error("the current session should be left to the caller")
+	}
+	if !a.ResyncTab(SessionResyncedMsg{SessionID: "ses_2"}) || a.Tab("ses_2") != nil {
+		t.Error("expected the tab of a deleted session to be closed")
+	}
+
+	a.SwitchTab("ses_1", nil)
+	if len(a.Messages) != 1 || a.Messages[0].ID() != "msg_1" {
+		t.Errorf("expected the reverted messages to be hidden, got %d messages", len(a.Messages))
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/components/toast"
+)
+
+// TokenUsage counts the tokens of a message or step by kind
+type TokenUsage struct {
+	Input      float64
+	Output     float64
+	Reasoning  float64
+	CacheRead  float64
+	CacheWrite float64
+}
+
+// Total returns the tokens of every kind
+func (u TokenUsage) Total() float64 {
+	return u.Input + u.Output + u.Reasoning + u.CacheRead + u.CacheWrite
+}
+
+func (u TokenUsage) add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		Input:      u.Input + other.Input,
+		Output:     u.Output + other.Output,
+		Reasoning:  u.Reasoning + other.Reasoning,
+		CacheRead:  u.CacheRead + other.CacheRead,
+		CacheWrite: u.CacheWrite + other.CacheWrite,
+	}
+}
+
+// StepUsage is the usage of one model call, reported by a step-finish part
+type StepUsage struct {
+	Tokens TokenUsage
+	Cost   float64
+}
+
+// MessageUsage is the usage of one assistant message and its steps
+type MessageUsage struct {
+	MessageID  string
+	ProviderID string
+	ModelID    string
+	Summary    bool
+	Tokens     TokenUsage
+	Cost       float64
+	Steps      []StepUsage
+	// Context is the size of the context window after the message
+	Context float64
+}
+
+// ModelCost is the usage of every message answered by one model
+type ModelCost struct {
+	ProviderID string
+	ModelID    string
+	Messages   int
+	Tokens     TokenUsage
+	Cost       float64
+}
+
+// SessionUsage breaks the token usage and cost of a conversation down per
+// message, step and model
+type SessionUsage struct {
+	Messages []MessageUsage
+	Models   []ModelCost
+	Tokens   TokenUsage
+	Cost     float64
+	// Context is the current size of the context window
+	Context float64
+	// ContextHistory holds the context size after every step, oldest first
+	ContextHistory []float64
+}
+
+// Usage computes the usage of a conversation. A summary message replaces
+// the context with its output, the way compaction does on the server.
+func Usage(messages []Message) SessionUsage {
+	usage := SessionUsage{}
+	for _, message := range messages {
+		assistant, ok := message.Info.(opencode.AssistantMessage)
+		if !ok {
+			continue
+		}
+		tokens := assistant.Tokens
+		entry := MessageUsage{
+			MessageID:  assistant.ID,
+			ProviderID: assistant.ProviderID,
+			ModelID:    assistant.ModelID,
+			Summary:    assistant.Summary,
+			Cost:       assistant.Cost,
+			Tokens: TokenUsage{
+				Input:      tokens.Input,
+				Output:     tokens.Output,
+				Reasoning:  tokens.Reasoning,
+				CacheRead:  tokens.Cache.Read,
+				CacheWrite: tokens.Cache.Write,
+			},
+		}
+		for _, part := range message.Parts {
+			step, ok := part.(opencode.StepFinishPart)
+			if !ok {
+				continue
+			}
+			stepUsage := StepUsage{
+				Cost: step.Cost,
+				Tokens: TokenUsage{
+					Input:      step.Tokens.Input,
+					Output:     step.Tokens.Output,
+					Reasoning:  step.Tokens.Reasoning,
+					CacheRead:  step.Tokens.Cache.Read,
+					CacheWrite: step.Tokens.Cache.Write,
+				},
+			}
+			entry.Steps = append(entry.Steps, stepUsage)
+			if !entry.Summary {
+				usage.ContextHistory = append(usage.ContextHistory, stepUsage.Tokens.Total())
+			}
+		}
+
+		if entry.Tokens.Output > 0 {
+			usage.Context = entry.Tokens.Total()
+			if entry.Summary {
+				usage.Context = entry.Tokens.Output
+				usage.ContextHistory = append(usage.ContextHistory, usage.Context)
+			} else if len(entry.Steps) == 0 {
+				usage.ContextHistory = append(usage.ContextHistory, usage.Context)
+			}
+		}
+		entry.Context = usage.Context
+
+		usage.Messages = append(usage.Messages, entry)
+		usage.Tokens = usage.Tokens.add(entry.Tokens)
+		usage.Cost += entry.Cost
+
+		index := slices.IndexFunc(usage.Models, func(model ModelCost) bool {
+			return model.ProviderID == entry.ProviderID && model.ModelID == entry.ModelID
+		})
+		if index < 0 {
+			usage.Models = append(usage.Models, ModelCost{ProviderID: entry.ProviderID, ModelID: entry.ModelID})
+			index = len(usage.Models) - 1
+		}
+		usage.Models[index].Messages++
+		usage.Models[index].Tokens = usage.Models[index].Tokens.add(entry.Tokens)
+		usage.Models[index].Cost += entry.Cost
+	}
+	return usage
+}
+
+// ContextShare returns the share of the current model's context window the
+// conversation uses, or 0 when the window is unknown
+func (a *App) ContextShare(usage SessionUsage) float64 {
+	if a.Model == nil || a.Model.Limit.Context <= 0 {
+		return 0
+	}
+	return usage.Context / a.Model.Limit.Context
+}
+
+// ContextWarning reports whether the conversation uses more of the context
+// window than the configured context_warning share
+func (a *App) ContextWarning(usage SessionUsage) bool {
+	share := a.ContextShare(usage)
+	return share > 0 && share >= a.State.ContextWarningShare()
+}
+
+// CheckContextBudget warns once per session when the conversation crosses
+// the context_warning share of the context window
+func (a *App) CheckContextBudget() tea.Cmd {
+	usage := Usage(a.Messages)
+	if !a.ContextWarning(usage) {
+		if a.contextWarned == a.Session.ID {
+			a.contextWarned = ""
+		}
+		return nil
+	}
+	if a.contextWarned == a.Session.ID {
+		return nil
+	}
+	a.contextWarned = a.Session.ID
+	return toast.NewWarningToast(
+		fmt.Sprintf("%.0f%% of the context window is used, /compact to summarize the session", a.ContextShare(usage)*100),
+		toast.WithTitle("Context budget"),
+	)
+}
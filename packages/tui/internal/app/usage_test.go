@@ -0,0 +1,82 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/config"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func TestUsage(t *testing.T) {
+	assistant := func(id, model string, input, output, cacheRead float64, cost float64, summary bool) opencode.AssistantMessage {
+		return opencode.AssistantMessage{
+			ID:      id,
+			ModelID: model,
+			Cost:    cost,
+			Summary: summary,
+			Tokens: opencode.AssistantMessageTokens{
+				Input:  input,
+				Output: output,
+				Cache:  opencode.AssistantMessageTokensCache{Read: cacheRead},
+			},
+		}
+	}
+	step := func(input, output float64) opencode.PartUnion {
+		return opencode.StepFinishPart{Tokens: opencode.StepFinishPartTokens{Input: input, Output: output}}
+	}
+	messages := []Message{
+		{Info: opencode.UserMessage{ID: "msg_1"}},
+		{Info: assistant("msg_2", "small", 100, 50, 0, 0.01, false), Parts: []opencode.PartUnion{step(40, 10), step(60, 40)}},
+		{Info: assistant("msg_3", "large", 300, 100, 200, 0.05, false)},
+		{Info: assistant("msg_4", "small", 600, 80, 0, 0.02, true)},
+	}
+
+	usage := Usage(messages)
+	if len(usage.Messages) != 3 || len(usage.Messages[0].Steps) != 2 {
+		t.Fatalf("expected 3 assistant messages with 2 steps on the first, got %+v", usage.Messages)
+	}
+	if usage.Context != 80 {
+		t.Errorf("a summary should reset the context to its output, got %v", usage.Context)
+	}
+	if want := []float64{50, 100, 600, 80}; len(usage.ContextHistory) != len(want) {
+		t.Errorf("ContextHistory = %v, want %v", usage.ContextHistory, want)
+	} else {
+		for i := range want {
+			if usage.ContextHistory[i] != want[i] {
+				t.Errorf("ContextHistory = %v, want %v", usage.ContextHistory, want)
+				break
+			}
+		}
+	}
+	if len(usage.Models) != 2 || usage.Models[0].Messages != 2 || usage.Models[0].Cost != 0.03 {
+		t.Errorf("expected per model totals, got %+v", usage.Models)
+	}
+	if usage.Tokens.Input != 1000 || usage.Tokens.CacheRead != 200 {
+		t.Errorf("Tokens = %+v", usage.Tokens)
+	}
+}
+
+func TestContextWarning(t *testing.T) {
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatalf("Failed to load themes: %v", err)
+	}
+	theme.SetTheme("opencode")
+	a := &App{
+		Session: &opencode.Session{ID: "ses_1"},
+		State:   &config.State{ContextWarning: 0.5},
+		Model:   &opencode.Model{Limit: opencode.ModelLimit{Context: 1000}},
+		Messages: []Message{{Info: opencode.AssistantMessage{
+			Tokens: opencode.AssistantMessageTokens{Input: 550, Output: 10},
+		}}},
+	}
+	if !a.ContextWarning(Usage(a.Messages)) {
+		t.Fatal("expected a warning past the configured share")
+	}
+	if a.CheckContextBudget() == nil {
+		t.Error("expected a toast the first time the share is crossed")
+	}
+	if a.CheckContextBudget() != nil {
+		t.Error("expected the warning to be shown once per session")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"slices"
 	"strings"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/sst/opencode-sdk-go"
@@ -30,6 +31,11 @@ type Command struct {
 	Description string
 	Keybindings []Keybinding
 	Trigger     []string
+	// AcceptsArgs is set for commands taking text typed after the trigger,
+	// e.g. /export html out.html
+	AcceptsArgs bool
+	// Args holds the rest of the line typed after the trigger, unsplit
+	Args string
 }
 
 func (c Command) Keys() []string {
@@ -55,8 +61,51 @@ func (c Command) MatchesTrigger(trigger string) bool {
 	return slices.Contains(c.Trigger, trigger)
 }
 
+// WithArgs returns a copy of the command invoked with the given arguments
+func (c Command) WithArgs(args string) Command {
+	c.Args = strings.TrimSpace(args)
+	return c
+}
+
+// SplitArgs returns the first word of the arguments and the rest of them,
+// which may contain spaces
+func (c Command) SplitArgs() (string, string) {
+	first, rest, _ := strings.Cut(c.Args, " ")
+	return first, strings.TrimSpace(rest)
+}
+
+// EscapePrefix starts a prompt beginning with "/" that is not a command, the
+// first slash being dropped
+const EscapePrefix = "//"
+
 type CommandRegistry map[CommandName]Command
 
+// Parse resolves typed input such as "/export html out.html" to the command
+// with that trigger, passing the rest of the line as its arguments. Input with
+// text after the trigger of a command that takes no arguments is a prompt, and
+// so is input starting with the "//" escape.
+func (r CommandRegistry) Parse(input string) (Command, bool) {
+	if !strings.HasPrefix(input, "/") || strings.HasPrefix(input, EscapePrefix) {
+		return Command{}, false
+	}
+	trigger, args := input[1:], ""
+	if i := strings.IndexFunc(trigger, unicode.IsSpace); i != -1 {
+		trigger, args = trigger[:i], strings.TrimSpace(trigger[i:])
+	}
+	if trigger == "" {
+		return Command{}, false
+	}
+	for _, command := range r {
+		if command.MatchesTrigger(trigger) {
+			if args != "" && !command.AcceptsArgs {
+				return Command{}, false
+			}
+			return command.WithArgs(args), true
+		}
+	}
+	return Command{}, false
+}
+
 func (r CommandRegistry) Sorted() []Command {
 	var commands []Command
 	for _, command := range r {
@@ -91,11 +140,24 @@ const (
 	EditorOpenCommand           CommandName = "editor_open"
 	SessionNewCommand           CommandName = "session_new"
 	SessionListCommand          CommandName = "session_list"
+	SessionTabNextCommand       CommandName = "session_tab_next"
+	SessionTabPreviousCommand   CommandName = "session_tab_previous"
+	SessionTabCloseCommand      CommandName = "session_tab_close"
 	SessionShareCommand         CommandName = "session_share"
 	SessionUnshareCommand       CommandName = "session_unshare"
 	SessionInterruptCommand     CommandName = "session_interrupt"
 	SessionCompactCommand       CommandName = "session_compact"
+	SessionAutoCompactCommand   CommandName = "session_auto_compact"
+	SessionRetryCommand         CommandName = "session_retry"
+	SessionCompareCommand       CommandName = "session_compare"
 	SessionExportCommand        CommandName = "session_export"
+	SessionImportCommand        CommandName = "session_import"
+	SessionChildrenCommand      CommandName = "session_children"
+	SessionParentCommand        CommandName = "session_parent"
+	SessionRenameCommand        CommandName = "session_rename"
+	SessionForkCommand          CommandName = "session_fork"
+	SessionUsageCommand         CommandName = "session_usage"
+	SessionPermissionsCommand   CommandName = "session_permissions"
 	ToolDetailsCommand          CommandName = "tool_details"
 	ModelListCommand            CommandName = "model_list"
 	ThemeListCommand            CommandName = "theme_list"
@@ -103,11 +165,14 @@ const (
 	FileCloseCommand            CommandName = "file_close"
 	FileSearchCommand           CommandName = "file_search"
 	FileDiffToggleCommand       CommandName = "file_diff_toggle"
+	DiagnosticsToggleCommand    CommandName = "diagnostics_toggle"
 	ProjectInitCommand          CommandName = "project_init"
 	InputClearCommand           CommandName = "input_clear"
 	InputPasteCommand           CommandName = "input_paste"
 	InputSubmitCommand          CommandName = "input_submit"
 	InputNewlineCommand         CommandName = "input_newline"
+	InputHistorySearchCommand   CommandName = "input_history_search"
+	InputQueueCommand           CommandName = "input_queue"
 	MessagesPageUpCommand       CommandName = "messages_page_up"
 	MessagesPageDownCommand     CommandName = "messages_page_down"
 	MessagesHalfPageUpCommand   CommandName = "messages_half_page_up"
@@ -119,6 +184,10 @@ const (
 	MessagesLayoutToggleCommand CommandName = "messages_layout_toggle"
 	MessagesCopyCommand         CommandName = "messages_copy"
 	MessagesRevertCommand       CommandName = "messages_revert"
+	MessagesUnrevertCommand     CommandName = "messages_unrevert"
+	MessagesEditCommand         CommandName = "messages_edit"
+	MessagesReconcileCommand    CommandName = "messages_reconcile"
+	DebugCacheStatsCommand      CommandName = "debug_cache_stats"
 	AppExitCommand              CommandName = "app_exit"
 )
 
@@ -176,6 +245,13 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "export conversation",
 			Keybindings: parseBindings("<leader>x"),
 			Trigger:     []string{"export"},
+			AcceptsArgs: true,
+		},
+		{
+			Name:        SessionImportCommand,
+			Description: "import conversation",
+			Trigger:     []string{"import"},
+			AcceptsArgs: true,
 		},
 		{
 			Name:        SessionNewCommand,
@@ -189,6 +265,50 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>l"),
 			Trigger:     []string{"sessions", "resume", "continue"},
 		},
+		{
+			Name:        SessionTabNextCommand,
+			Description: "next session tab",
+			Keybindings: parseBindings("<leader>]"),
+		},
+		{
+			Name:        SessionTabPreviousCommand,
+			Description: "previous session tab",
+			Keybindings: parseBindings("<leader>["),
+		},
+		{
+			Name:        SessionTabCloseCommand,
+			Description: "close session tab",
+			Keybindings: parseBindings("<leader>w"),
+			Trigger:     []string{"close"},
+		},
+		{
+			Name:        SessionChildrenCommand,
+			Description: "open subagent session",
+			Keybindings: parseBindings("<leader>o"),
+			Trigger:     []string{"subagents", "children"},
+		},
+		{
+			Name:        SessionParentCommand,
+			Description: "back to parent or forked session",
+			Keybindings: parseBindings("<leader>b"),
+			Trigger:     []string{"parent"},
+		},
+		{
+			Name:        SessionRenameCommand,
+			Description: "rename session",
+			Trigger:     []string{"rename"},
+			AcceptsArgs: true,
+		},
+		{
+			Name:        SessionForkCommand,
+			Description: "fork session from a message",
+			Trigger:     []string{"fork", "branch"},
+		},
+		{
+			Name:        SessionUsageCommand,
+			Description: "show token usage and cost",
+			Trigger:     []string{"usage", "cost"},
+		},
 		{
 			Name:        SessionShareCommand,
 			Description: "share session",
@@ -212,6 +332,28 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Keybindings: parseBindings("<leader>c"),
 			Trigger:     []string{"compact", "summarize"},
 		},
+		{
+			Name:        SessionAutoCompactCommand,
+			Description: "toggle auto-compact",
+			Trigger:     []string{"autocompact"},
+		},
+		{
+			Name:        SessionRetryCommand,
+			Description: "retry the failed prompt",
+			Trigger:     []string{"retry"},
+		},
+		{
+			Name:        SessionCompareCommand,
+			Description: "compare models on a prompt",
+			Trigger:     []string{"compare"},
+			AcceptsArgs: true,
+		},
+		{
+			Name:        SessionPermissionsCommand,
+			Description: "review permissions",
+			Keybindings: parseBindings("<leader>a"),
+			Trigger:     []string{"permissions"},
+		},
 		{
 			Name:        ToolDetailsCommand,
 			Description: "toggle tool details",
@@ -251,6 +393,12 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "split/unified diff",
 			Keybindings: parseBindings("<leader>v"),
 		},
+		{
+			Name:        DiagnosticsToggleCommand,
+			Description: "toggle diagnostics",
+			Keybindings: parseBindings("<leader>g"),
+			Trigger:     []string{"diagnostics"},
+		},
 		{
 			Name:        ProjectInitCommand,
 			Description: "create/update AGENTS.md",
@@ -277,6 +425,17 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Description: "insert newline",
 			Keybindings: parseBindings("shift+enter", "ctrl+j"),
 		},
+		{
+			Name:        InputHistorySearchCommand,
+			Description: "search prompt history",
+			Keybindings: parseBindings("ctrl+r"),
+			Trigger:     []string{"history"},
+		},
+		{
+			Name:        InputQueueCommand,
+			Description: "edit queued prompts",
+			Trigger:     []string{"queue"},
+		},
 		{
 			Name:        MessagesPageUpCommand,
 			Description: "page up",
@@ -299,12 +458,12 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 		},
 		{
 			Name:        MessagesPreviousCommand,
-			Description: "previous message",
+			Description: "select previous message",
 			Keybindings: parseBindings("ctrl+up"),
 		},
 		{
 			Name:        MessagesNextCommand,
-			Description: "next message",
+			Description: "select next message",
 			Keybindings: parseBindings("ctrl+down"),
 		},
 		{
@@ -331,6 +490,27 @@ func LoadFromConfig(config *opencode.Config) CommandRegistry {
 			Name:        MessagesRevertCommand,
 			Description: "revert message",
 			Keybindings: parseBindings("<leader>r"),
+			Trigger:     []string{"revert", "undo"},
+		},
+		{
+			Name:        MessagesEditCommand,
+			Description: "edit and resend prompt",
+			Trigger:     []string{"edit"},
+		},
+		{
+			Name:        MessagesUnrevertCommand,
+			Description: "undo last revert",
+			Trigger:     []string{"unrevert", "redo"},
+		},
+		{
+			Name:        MessagesReconcileCommand,
+			Description: "reload messages from the server",
+			Trigger:     []string{"reconcile", "sync"},
+		},
+		{
+			Name:        DebugCacheStatsCommand,
+			Description: "show render cache stats",
+			Trigger:     []string{"cache"},
 		},
 		{
 			Name:        AppExitCommand,
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+)
+
+func TestParse(t *testing.T) {
+	registry := LoadFromConfig(&opencode.Config{})
+	tests := []struct {
+		input string
+		name  CommandName
+		args  string
+		ok    bool
+	}{
+		{input: "/undo", name: MessagesRevertCommand, ok: true},
+		{input: "/undo the last change broke X", ok: false},
+		{input: "//undo", ok: false},
+		{input: "/export html  my notes/out file.html ", name: SessionExportCommand, args: "html  my notes/out file.html", ok: true},
+		{input: "/rename a better\ttitle", name: SessionRenameCommand, args: "a better\ttitle", ok: true},
+		{input: "/", ok: false},
+		{input: "/missing", ok: false},
+		{input: "undo", ok: false},
+	}
+	for _, test := range tests {
+		command, ok := registry.Parse(test.input)
+		if ok != test.ok || command.Name != test.name || command.Args != test.args {
+			t.Errorf("%q: expected %s %q %v, got %s %q %v", test.input, test.name, test.args, test.ok, command.Name, command.Args, ok)
+		}
+	}
+
+	format, path := Command{Args: "html my notes/out file.html"}.SplitArgs()
+	if format != "html" || path != "my notes/out file.html" {
+		t.Errorf("expected the path to keep its spaces, got %q %q", format, path)
+	}
+}
@@ -1,22 +1,68 @@
 package chat
 
 import (
+	"container/list"
 	"encoding/hex"
 	"fmt"
 	"hash/fnv"
 	"sync"
 )
 
-// PartCache caches rendered messages to avoid re-rendering
+// DefaultPartCacheSize is the default memory budget of a PartCache in bytes
+const DefaultPartCacheSize = 32 * 1024 * 1024
+
+// PartCache caches rendered messages to avoid re-rendering. It is bounded by
+// the total size of its keys and contents and evicts the least recently used
+// entries once that budget is exceeded.
 type PartCache struct {
-	mu    sync.RWMutex
-	cache map[string]string
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	entries  *list.List
+	cache    map[string]*list.Element
+	// messages indexes the keys rendered from each message
+	messages  map[string]map[string]struct{}
+	hits      int
+	misses    int
+	evictions int
+}
+
+type partCacheEntry struct {
+	key       string
+	messageID string
+	content   string
+}
+
+func (e *partCacheEntry) size() int {
+	return len(e.key) + len(e.content)
+}
+
+// PartCacheStats is a snapshot of the cache usage counters
+type PartCacheStats struct {
+	Entries   int
+	Bytes     int
+	MaxBytes  int
+	Hits      int
+	Misses    int
+	Evictions int
+}
+
+// HitRate returns the share of lookups served from the cache
+func (s PartCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
 }
 
-// NewPartCache creates a new message cache
-func NewPartCache() *PartCache {
+// NewPartCache creates a new message cache holding at most maxBytes of
+// rendered content
+func NewPartCache(maxBytes int) *PartCache {
 	return &PartCache{
-		cache: make(map[string]string),
+		maxBytes: maxBytes,
+		entries:  list.New(),
+		cache:    make(map[string]*list.Element),
+		messages: make(map[string]map[string]struct{}),
 	}
 }
 
@@ -31,18 +77,81 @@ func (c *PartCache) GenerateKey(params ...any) string {
 
 // Get retrieves a cached rendered message
 func (c *PartCache) Get(key string) (string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	content, exists := c.cache[key]
-	return content, exists
+	element, exists := c.cache[key]
+	if !exists {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	c.entries.MoveToFront(element)
+	return element.Value.(*partCacheEntry).content, true
 }
 
 // Set stores a rendered message in the cache
 func (c *PartCache) Set(key string, content string) {
+	c.SetFor("", key, content)
+}
+
+// SetFor stores content rendered from a message in the cache, so it can be
+// dropped with InvalidateMessage once the message changes or goes away
+func (c *PartCache) SetFor(messageID string, key string, content string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, exists := c.cache[key]; exists {
+		entry := element.Value.(*partCacheEntry)
+		c.bytes += len(content) - len(entry.content)
+		entry.content = content
+		c.entries.MoveToFront(element)
+	} else {
+		entry := &partCacheEntry{key: key, messageID: messageID, content: content}
+		c.cache[key] = c.entries.PushFront(entry)
+		c.bytes += entry.size()
+		if messageID != "" {
+			if c.messages[messageID] == nil {
+				c.messages[messageID] = map[string]struct{}{}
+			}
+			c.messages[messageID][key] = struct{}{}
+		}
+	}
+
+	for c.bytes > c.maxBytes && c.entries.Len() > 1 {
+		c.remove(c.entries.Back())
+		c.evictions++
+	}
+}
+
+// InvalidateMessage removes every entry rendered from the message and
+// returns how many were removed
+func (c *PartCache) InvalidateMessage(messageID string) int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.cache[key] = content
+
+	removed := 0
+	for key := range c.messages[messageID] {
+		if element, exists := c.cache[key]; exists {
+			c.remove(element)
+			removed++
+		}
+	}
+	delete(c.messages, messageID)
+	return removed
+}
+
+func (c *PartCache) remove(element *list.Element) {
+	entry := element.Value.(*partCacheEntry)
+	c.entries.Remove(element)
+	delete(c.cache, entry.key)
+	c.bytes -= entry.size()
+	if keys, ok := c.messages[entry.messageID]; ok {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.messages, entry.messageID)
+		}
+	}
 }
 
 // Clear removes all entries from the cache
@@ -50,13 +159,31 @@ func (c *PartCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cache = make(map[string]string)
+	c.entries.Init()
+	c.cache = make(map[string]*list.Element)
+	c.messages = make(map[string]map[string]struct{})
+	c.bytes = 0
 }
 
 // Size returns the number of cached entries
 func (c *PartCache) Size() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	return len(c.cache)
 }
+
+// Stats returns the current usage counters
+func (c *PartCache) Stats() PartCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return PartCacheStats{
+		Entries:   len(c.cache),
+		Bytes:     c.bytes,
+		MaxBytes:  c.maxBytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
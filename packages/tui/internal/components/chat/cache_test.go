@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPartCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewPartCache(30)
+	cache.Set("a", strings.Repeat("x", 9))
+	cache.Set("b", strings.Repeat("x", 9))
+	cache.Set("c", strings.Repeat("x", 9))
+
+	// touch a so b becomes the least recently used entry
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.Set("d", strings.Repeat("x", 9))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 3 || stats.Bytes != 30 {
+		t.Errorf("expected 3 entries using 30 bytes, got %d entries using %d bytes", stats.Entries, stats.Bytes)
+	}
+	if stats.Hits != 4 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("unexpected counters: %+v", stats)
+	}
+}
+
+func TestPartCacheReplaceUpdatesSize(t *testing.T) {
+	cache := NewPartCache(100)
+	cache.Set("a", "short")
+	cache.Set("a", "a much longer value")
+
+	content, _ := cache.Get("a")
+	if content != "a much longer value" {
+		t.Errorf("expected replaced content, got %q", content)
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Bytes != len("a")+len(content) {
+		t.Errorf("unexpected stats after replace: %+v", stats)
+	}
+
+	cache.Clear()
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("expected empty cache after clear, got %+v", stats)
+	}
+}
+
+func TestPartCacheInvalidateMessage(t *testing.T) {
+	cache := NewPartCache(100)
+	cache.SetFor("msg_a", "a1", "x")
+	cache.SetFor("msg_a", "a2", "x")
+	cache.SetFor("msg_b", "b1", "x")
+
+	if removed := cache.InvalidateMessage("msg_a"); removed != 2 {
+		t.Errorf("expected 2 entries removed, got %d", removed)
+	}
+	if _, ok := cache.Get("a1"); ok {
+		t.Error("expected a1 to be invalidated")
+	}
+	if _, ok := cache.Get("b1"); !ok {
+		t.Error("expected b1 to stay cached")
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Bytes != len("b1x") {
+		t.Errorf("unexpected stats after invalidation: %+v", stats)
+	}
+	if removed := cache.InvalidateMessage("msg_a"); removed != 0 {
+		t.Errorf("expected nothing left to invalidate, got %d", removed)
+	}
+}
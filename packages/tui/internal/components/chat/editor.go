@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -20,6 +21,8 @@ import (
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/components/dialog"
 	"github.com/sst/opencode/internal/components/textarea"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/config"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
 	"github.com/sst/opencode/internal/util"
@@ -40,6 +43,9 @@ type EditorComponent interface {
 	Paste() (tea.Model, tea.Cmd)
 	Newline() (tea.Model, tea.Cmd)
 	SetValue(value string)
+	EditPrompt(msg app.EditPromptMsg)
+	RecallPrompt(entry config.PromptEntry)
+	Prompt() config.PromptEntry
 	SetInterruptKeyInDebounce(inDebounce bool)
 	SetExitKeyInDebounce(inDebounce bool)
 }
@@ -51,6 +57,12 @@ type editorComponent struct {
 	spinner                spinner.Model
 	interruptKeyInDebounce bool
 	exitKeyInDebounce      bool
+	// editing is the earlier prompt the editor content replaces when sent
+	editing string
+	// historyIndex is the prompt history entry shown while browsing it with
+	// up and down, -1 otherwise; recalled is the text it was loaded as
+	historyIndex int
+	recalled     string
 }
 
 func (m *editorComponent) Init() tea.Cmd {
@@ -78,6 +90,16 @@ func (m *editorComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 			return m, tea.Batch(cmds...)
 		}
+		switch msg.String() {
+		case "up":
+			if m.recallOlder() {
+				return m, nil
+			}
+		case "down":
+			if m.recallNewer() {
+				return m, nil
+			}
+		}
 	case tea.PasteMsg:
 		text := string(msg)
 		text = strings.ReplaceAll(text, "\\", "")
@@ -266,14 +288,22 @@ func (m *editorComponent) Content() string {
 		Render(textarea)
 
 	hint := base(m.getSubmitKeyText()) + muted(" send   ")
+	if m.editing != "" {
+		hint = base(m.getSubmitKeyText()) + muted(" revert and resend  ") +
+			base(m.getClearKeyText()) + muted(" cancel edit   ")
+	}
 	if m.exitKeyInDebounce {
 		keyText := m.getExitKeyText()
 		hint = base(keyText+" again") + muted(" to exit")
-	} else if m.app.IsBusy() {
+	} else if m.app.IsBusy() || m.app.IsCompacting() {
 		keyText := m.getInterruptKeyText()
+		status := "working"
+		if m.app.IsCompacting() {
+			status = "compacting"
+		}
 		if m.interruptKeyInDebounce {
 			hint = muted(
-				"working",
+				status,
 			) + m.spinner.View() + muted(
 				"  ",
 			) + base(
@@ -282,7 +312,10 @@ func (m *editorComponent) Content() string {
 				" interrupt",
 			)
 		} else {
-			hint = muted("working") + m.spinner.View() + muted("  ") + base(keyText) + muted(" interrupt")
+			hint = muted(status) + m.spinner.View() + muted("  ") + base(keyText) + muted(" interrupt")
+		}
+		if m.editing == "" && m.textarea.Value() != "" {
+			hint += muted("  ") + base(m.getSubmitKeyText()) + muted(" queue")
 		}
 	}
 
@@ -347,6 +380,7 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 
 	switch value {
 	case "exit", "quit", "q", ":q":
+		m.Clear()
 		return m, tea.Quit
 	}
 
@@ -359,7 +393,34 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 
 	var cmds []tea.Cmd
 
+	if command, ok := m.app.Commands.Parse(value); ok {
+		updated, cmd := m.Clear()
+		m = updated.(*editorComponent)
+		cmds = append(cmds, cmd, util.CmdHandler(commands.ExecuteCommandMsg(command)))
+		return m, tea.Batch(cmds...)
+	}
+	// the escape sends a prompt starting with "/", history keeps it as
+	// typed so a recalled prompt is not run as a command
+	typed := value
+	if strings.HasPrefix(value, commands.EscapePrefix) {
+		value = value[1:]
+	}
+
+	if m.editing != "" && (m.app.IsBusy() || m.app.IsCompacting()) {
+		return m, toast.NewWarningToast("Wait for the agent to finish before resending")
+	}
+
 	attachments := m.textarea.GetAttachments()
+	if m.app.IsBusy() || m.app.IsCompacting() {
+		// sent once the session goes idle
+		cmds = append(cmds, m.recordPrompt(typed, attachments))
+		prompt := promptEntry(value, attachments)
+		updated, cmd := m.Clear()
+		m = updated.(*editorComponent)
+		cmds = append(cmds, cmd, util.CmdHandler(app.PromptQueuedMsg{Prompt: prompt}))
+		return m, tea.Batch(cmds...)
+	}
+
 	fileParts := make([]opencode.FilePartParam, 0)
 	for _, attachment := range attachments {
 		fileParts = append(fileParts, opencode.FilePartParam{
@@ -370,16 +431,20 @@ func (m *editorComponent) Submit() (tea.Model, tea.Cmd) {
 		})
 	}
 
+	revert := m.editing
+	cmds = append(cmds, m.recordPrompt(typed, attachments))
 	updated, cmd := m.Clear()
 	m = updated.(*editorComponent)
 	cmds = append(cmds, cmd)
 
-	cmds = append(cmds, util.CmdHandler(app.SendMsg{Text: value, Attachments: fileParts}))
+	cmds = append(cmds, util.CmdHandler(app.SendMsg{Text: value, Attachments: fileParts, Revert: revert}))
 	return m, tea.Batch(cmds...)
 }
 
 func (m *editorComponent) Clear() (tea.Model, tea.Cmd) {
 	m.textarea.Reset()
+	m.editing = ""
+	m.historyIndex = -1
 	return m, nil
 }
 
@@ -422,6 +487,182 @@ func (m *editorComponent) SetInterruptKeyInDebounce(inDebounce bool) {
 
 func (m *editorComponent) SetValue(value string) {
 	m.textarea.SetValue(value)
+	m.editing = ""
+	m.historyIndex = -1
+}
+
+// EditPrompt loads an earlier prompt with its attachments so it can be
+// changed and sent in place of the original
+func (m *editorComponent) EditPrompt(msg app.EditPromptMsg) {
+	pending := make([]*textarea.Attachment, 0, len(msg.Attachments))
+	for i, file := range msg.Attachments {
+		pending = append(pending, newAttachment(file, attachmentLabel(file, i+1, msg.Text)))
+	}
+	m.loadPrompt(msg.Text, pending)
+	m.editing = msg.MessageID
+	m.historyIndex = -1
+}
+
+// RecallPrompt loads a prompt from the history with the attachments that can
+// still be sent
+func (m *editorComponent) RecallPrompt(entry config.PromptEntry) {
+	m.loadPrompt(entry.Text, savedAttachments(entry))
+	m.editing = ""
+	m.historyIndex = -1
+}
+
+// recallOlder shows the prompt sent before the one being browsed. Browsing
+// starts from an empty editor with the cursor on the first line, so drafts
+// and multi-line edits are never replaced.
+func (m *editorComponent) recallOlder() bool {
+	if m.app.History == nil || m.textarea.Line() != 0 {
+		return false
+	}
+	index := m.app.History.Len()
+	if m.browsingHistory() {
+		index = m.historyIndex
+	} else if m.Value() != "" {
+		return false
+	}
+	entry, ok := m.app.History.At(index - 1)
+	if !ok {
+		return false
+	}
+	m.recall(index-1, entry)
+	return true
+}
+
+// recallNewer shows the prompt sent after the one being browsed, and empties
+// the editor when moving past the newest
+func (m *editorComponent) recallNewer() bool {
+	if !m.browsingHistory() || m.textarea.Line() != m.textarea.LineCount()-1 {
+		return false
+	}
+	entry, ok := m.app.History.At(m.historyIndex + 1)
+	if !ok {
+		m.Clear()
+		return true
+	}
+	m.recall(m.historyIndex+1, entry)
+	return true
+}
+
+// browsingHistory reports whether the editor shows an untouched prompt
+// recalled with up or down
+func (m *editorComponent) browsingHistory() bool {
+	return m.historyIndex >= 0 && m.Value() == m.recalled
+}
+
+func (m *editorComponent) recall(index int, entry config.PromptEntry) {
+	m.loadPrompt(entry.Text, savedAttachments(entry))
+	m.editing = ""
+	m.historyIndex = index
+	m.recalled = m.Value()
+}
+
+// recordPrompt adds a sent prompt to the project history
+func (m *editorComponent) recordPrompt(text string, attachments []*textarea.Attachment) tea.Cmd {
+	history := m.app.History
+	if history == nil {
+		return nil
+	}
+	entry := promptEntry(text, attachments)
+	return func() tea.Msg {
+		if err := history.Add(entry); err != nil {
+			slog.Error("Failed to save prompt history", "error", err)
+		}
+		return nil
+	}
+}
+
+// Prompt returns the editor content with its attachments, as kept in the
+// prompt history and in drafts
+func (m *editorComponent) Prompt() config.PromptEntry {
+	return promptEntry(m.Value(), m.textarea.GetAttachments())
+}
+
+func promptEntry(text string, attachments []*textarea.Attachment) config.PromptEntry {
+	entry := config.PromptEntry{Text: text}
+	for _, attachment := range attachments {
+		entry.Attachments = append(entry.Attachments, config.PromptAttachment{
+			Mime:     attachment.MediaType,
+			URL:      attachment.URL,
+			Filename: attachment.Filename,
+			Display:  attachment.Display,
+		})
+	}
+	return entry
+}
+
+// savedAttachments returns the attachments of a saved prompt whose files
+// still exist
+func savedAttachments(entry config.PromptEntry) []*textarea.Attachment {
+	attachments := []*textarea.Attachment{}
+	for i, attachment := range entry.ValidAttachments(util.CwdPath) {
+		file := opencode.FilePart{
+			Type:     opencode.FilePartTypeFile,
+			Mime:     attachment.Mime,
+			URL:      attachment.URL,
+			Filename: attachment.Filename,
+		}
+		label := attachment.Display
+		if label == "" {
+			label = attachmentLabel(file, i+1, entry.Text)
+		}
+		attachments = append(attachments, newAttachment(file, label))
+	}
+	return attachments
+}
+
+func newAttachment(file opencode.FilePart, label string) *textarea.Attachment {
+	return &textarea.Attachment{
+		ID:        uuid.NewString(),
+		URL:       file.URL,
+		Filename:  file.Filename,
+		MediaType: file.Mime,
+		Display:   label,
+	}
+}
+
+// loadPrompt replaces the editor content with a prompt, placing each
+// attachment where its label appears in the text
+func (m *editorComponent) loadPrompt(text string, pending []*textarea.Attachment) {
+	m.textarea.Reset()
+	for len(pending) > 0 {
+		next, at := -1, len(text)
+		for i, attachment := range pending {
+			if index := strings.Index(text, attachment.Display); index >= 0 && index < at {
+				next, at = i, index
+			}
+		}
+		if next < 0 {
+			break
+		}
+		m.textarea.InsertString(text[:at])
+		m.textarea.InsertAttachment(pending[next])
+		text = text[at+len(pending[next].Display):]
+		pending = slices.Delete(pending, next, next+1)
+	}
+	m.textarea.InsertString(text)
+	// attachments whose label was edited out of the text go at the end
+	for _, attachment := range pending {
+		m.textarea.InsertString(" ")
+		m.textarea.InsertAttachment(attachment)
+	}
+}
+
+// attachmentLabel returns the label the editor showed for a file when the
+// prompt was written: "@path" for files picked by name, "[Image #n]" or
+// "[File #n]" for pasted ones
+func attachmentLabel(file opencode.FilePart, index int, text string) string {
+	label := "@" + file.Filename
+	if strings.Contains(text, label) || !strings.HasPrefix(file.URL, "data:") {
+		return label
+	}
+	if strings.HasPrefix(file.Mime, "image/") {
+		return fmt.Sprintf("[Image #%d]", index)
+	}
+	return fmt.Sprintf("[File #%d]", index)
 }
 
 func (m *editorComponent) SetExitKeyInDebounce(inDebounce bool) {
@@ -436,6 +677,10 @@ func (m *editorComponent) getSubmitKeyText() string {
 	return m.app.Commands[commands.InputSubmitCommand].Keys()[0]
 }
 
+func (m *editorComponent) getClearKeyText() string {
+	return m.app.Commands[commands.InputClearCommand].Keys()[0]
+}
+
 func (m *editorComponent) getExitKeyText() string {
 	return m.app.Commands[commands.AppExitCommand].Keys()[0]
 }
@@ -500,6 +745,7 @@ func NewEditorComponent(app *app.App) EditorComponent {
 		textarea:               ta,
 		spinner:                s,
 		interruptKeyInDebounce: false,
+		historyIndex:           -1,
 	}
 
 	return m
@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/config"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func TestEditPromptPlacesAttachments(t *testing.T) {
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatalf("Failed to load themes: %v", err)
+	}
+	theme.SetTheme("opencode")
+	m := NewEditorComponent(&app.App{Config: &opencode.Config{}}).(*editorComponent)
+	m.EditPrompt(app.EditPromptMsg{
+		MessageID: "msg_1",
+		Text:      "compare [Image #2] with @main.go please",
+		Attachments: []opencode.FilePart{
+			{Mime: "text/plain", URL: "file://./main.go", Filename: "main.go"},
+			{Mime: "image/png", URL: "data:image/png;base64,AA==", Filename: "image-2.png"},
+			{Mime: "text/plain", URL: "file://./removed.go", Filename: "removed.go"},
+		},
+	})
+
+	if got, want := m.Value(), "compare [Image #2] with @main.go please @removed.go"; got != want {
+		t.Errorf("Value() = %q, want %q", got, want)
+	}
+	attachments := m.textarea.GetAttachments()
+	if len(attachments) != 3 || attachments[0].Filename != "image-2.png" || attachments[1].Filename != "main.go" {
+		t.Errorf("attachments were not placed in text order: %+v", attachments)
+	}
+	if m.editing != "msg_1" {
+		t.Error("expected the editor to remember the prompt being edited")
+	}
+
+	m.Clear()
+	if m.editing != "" {
+		t.Error("expected clearing the editor to cancel the edit")
+	}
+}
+
+func TestHistoryRecall(t *testing.T) {
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		t.Fatalf("Failed to load themes: %v", err)
+	}
+	theme.SetTheme("opencode")
+	history, _ := config.LoadPromptHistory(t.TempDir(), "/project")
+	history.Add(config.PromptEntry{Text: "first"})
+	history.Add(config.PromptEntry{Text: "second"})
+	m := NewEditorComponent(&app.App{Config: &opencode.Config{}, History: history}).(*editorComponent)
+
+	up := tea.KeyPressMsg{Code: tea.KeyUp}
+	down := tea.KeyPressMsg{Code: tea.KeyDown}
+	for _, step := range []struct {
+		key  tea.KeyPressMsg
+		want string
+	}{{up, "second"}, {up, "first"}, {up, "first"}, {down, "second"}, {down, ""}} {
+		m.Update(step.key)
+		if m.Value() != step.want {
+			t.Fatalf("after %s Value() = %q, want %q", step.key, m.Value(), step.want)
+		}
+	}
+
+	m.SetValue("draft")
+	m.Update(up)
+	if m.Value() != "draft" {
+		t.Errorf("up should not replace a draft, got %q", m.Value())
+	}
+}
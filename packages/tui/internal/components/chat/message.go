@@ -13,6 +13,7 @@ import (
 	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/components/diff"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
@@ -26,6 +27,7 @@ type blockRenderer struct {
 	border           bool
 	borderColor      *compat.AdaptiveColor
 	borderColorRight bool
+	highlight        bool
 	paddingTop       int
 	paddingBottom    int
 	paddingLeft      int
@@ -61,6 +63,14 @@ func WithBorderColorRight(color compat.AdaptiveColor) renderingOption {
 	}
 }
 
+// WithHighlight draws both borders in the primary color, marking the block
+// under the selection cursor
+func WithHighlight() renderingOption {
+	return func(c *blockRenderer) {
+		c.highlight = true
+	}
+}
+
 func WithMarginTop(padding int) renderingOption {
 	return func(c *blockRenderer) {
 		c.marginTop = padding
@@ -157,6 +167,11 @@ func renderContentBlock(
 				BorderRightBackground(t.Background())
 		}
 
+		if renderer.highlight {
+			style = style.
+				BorderLeftForeground(t.Primary()).
+				BorderRightForeground(t.Primary())
+		}
 	}
 
 	content = style.Render(content)
@@ -182,7 +197,8 @@ func renderText(
 	showToolDetails bool,
 	width int,
 	extra string,
-	toolCalls ...opencode.ToolPart,
+	toolCalls []opencode.ToolPart,
+	options ...renderingOption,
 ) string {
 	t := theme.CurrentTheme()
 
@@ -245,15 +261,17 @@ func renderText(
 			app,
 			content,
 			width,
-			WithTextColor(t.Text()),
-			WithBorderColorRight(t.Secondary()),
+			append([]renderingOption{
+				WithTextColor(t.Text()),
+				WithBorderColorRight(t.Secondary()),
+			}, options...)...,
 		)
 	case opencode.AssistantMessage:
 		return renderContentBlock(
 			app,
 			content,
 			width,
-			WithBorderColor(t.Accent()),
+			append([]renderingOption{WithBorderColor(t.Accent())}, options...)...,
 		)
 	}
 	return ""
@@ -263,6 +281,7 @@ func renderToolDetails(
 	app *app.App,
 	toolCall opencode.ToolPart,
 	width int,
+	options ...renderingOption,
 ) string {
 	ignoredTools := []string{"todoread"}
 	if slices.Contains(ignoredTools, toolCall.Tool) {
@@ -271,7 +290,7 @@ func renderToolDetails(
 
 	if toolCall.State.Status == opencode.ToolPartStateStatusPending {
 		title := renderToolTitle(toolCall, width)
-		return renderContentBlock(app, title, width)
+		return renderContentBlock(app, title, width, options...)
 	}
 
 	var result *string
@@ -352,8 +371,10 @@ func renderToolDetails(
 						app,
 						content,
 						width,
-						WithPadding(0),
-						WithBorderColor(borderColor),
+						append([]renderingOption{
+							WithPadding(0),
+							WithBorderColor(borderColor),
+						}, options...)...,
 					)
 					return content
 				}
@@ -418,6 +439,13 @@ func renderToolDetails(
 				}
 				body = strings.Join(steps, "\n")
 			}
+			if key := app.Keybinding(commands.SessionChildrenCommand); key != "" && (summary != nil || metadata["sessionID"] != nil) {
+				hint := styles.NewStyle().
+					Foreground(t.TextMuted()).
+					Background(backgroundColor).
+					Render(key + " to open the subagent session")
+				body = strings.TrimPrefix(body+"\n\n"+hint, "\n\n")
+			}
 			body = defaultStyle(body)
 		default:
 			if result == nil {
@@ -455,7 +483,12 @@ func renderToolDetails(
 
 	title := renderToolTitle(toolCall, width)
 	content := title + "\n\n" + body
-	return renderContentBlock(app, content, width, WithBorderColor(borderColor))
+	return renderContentBlock(
+		app,
+		content,
+		width,
+		append([]renderingOption{WithBorderColor(borderColor)}, options...)...,
+	)
 }
 
 func renderToolName(name string) string {
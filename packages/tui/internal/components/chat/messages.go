@@ -2,13 +2,15 @@ package chat
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
-	"github.com/charmbracelet/bubbles/v2/viewport"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/components/dialog"
 	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/layout"
@@ -27,27 +29,37 @@ type MessagesComponent interface {
 	ToolDetailsVisible() bool
 	GotoTop() (tea.Model, tea.Cmd)
 	GotoBottom() (tea.Model, tea.Cmd)
-	CopyLastMessage() (tea.Model, tea.Cmd)
+	CopyMessage() (tea.Model, tea.Cmd)
+	SelectPrevious() (tea.Model, tea.Cmd)
+	SelectNext() (tea.Model, tea.Cmd)
+	ClearSelection() (tea.Model, tea.Cmd)
+	Selection() (int, []opencode.PartUnion, bool)
+	CacheStats() PartCacheStats
 }
 
 type messagesComponent struct {
 	width, height   int
 	app             *app.App
 	header          string
-	viewport        viewport.Model
+	transcript      *transcript
 	cache           *PartCache
-	rendering       bool
 	showToolDetails bool
 	tail            bool
-	partCount       int
-	lineCount       int
+	// selection is the block under the selection cursor, nil when no
+	// message is selected. It is kept by ID so that it stays on the same
+	// message when messages before it are removed or reverted.
+	selection *blockID
+	// stale is set when a block was asked to render after the messages it
+	// was laid out from were replaced, until the layout is rebuilt
+	stale bool
 }
-type renderFinishedMsg struct{}
+
+const mouseWheelDelta = 4
 
 type ToggleToolDetailsMsg struct{}
 
 func (m *messagesComponent) Init() tea.Cmd {
-	return tea.Batch(m.viewport.Init())
+	return nil
 }
 
 func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -61,60 +73,72 @@ func (m *messagesComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.width = effectiveWidth
 		m.height = msg.Height - 7
-		m.viewport.SetWidth(m.width)
 		m.header = m.renderHeader()
+		m.transcript.setSize(m.width, m.height-lipgloss.Height(m.header))
 		return m, m.Reload()
 	case app.SendMsg:
-		m.viewport.GotoBottom()
+		m.selection = nil
+		m.transcript.gotoBottom()
 		m.tail = true
 		return m, nil
 	case app.OptimisticMessageAddedMsg:
 		m.tail = true
-		m.rendering = true
 		return m, m.Reload()
 	case dialog.ThemeSelectedMsg:
 		m.cache.Clear()
-		m.rendering = true
 		return m, m.Reload()
 	case ToggleToolDetailsMsg:
 		m.showToolDetails = !m.showToolDetails
-		m.rendering = true
 		return m, m.Reload()
 	case app.SessionLoadedMsg, app.SessionClearedMsg:
-		m.cache.Clear()
+		// cache keys are derived from message and part content, so entries
+		// stay valid across session switches
+		m.selection = nil
 		m.tail = true
-		m.rendering = true
 		return m, m.Reload()
-	case renderFinishedMsg:
-		m.rendering = false
-		if m.tail {
-			m.viewport.GotoBottom()
-		}
-
+	case tea.KeyPressMsg, tea.MouseWheelMsg:
+		m.transcript.Update(msg)
 	case opencode.EventListResponseEventSessionUpdated:
 		if msg.Properties.Info.ID == m.app.Session.ID {
 			m.header = m.renderHeader()
 		}
+	case app.ForkSourceLoadedMsg:
+		m.header = m.renderHeader()
 	case opencode.EventListResponseEventMessageUpdated:
 		if msg.Properties.Info.SessionID == m.app.Session.ID {
 			m.renderView()
 			if m.tail {
-				m.viewport.GotoBottom()
+				m.transcript.gotoBottom()
+			}
+		}
+	case opencode.EventListResponseEventMessageRemoved:
+		if msg.Properties.SessionID == m.app.Session.ID {
+			m.cache.InvalidateMessage(msg.Properties.MessageID)
+			m.renderView()
+		}
+	case opencode.EventListResponseEventSessionError:
+		if msg.Properties.SessionID == m.app.Session.ID {
+			m.renderView()
+			if m.tail {
+				m.transcript.gotoBottom()
 			}
 		}
+	case app.MessagesReconciledMsg:
+		for _, id := range slices.Concat(msg.Drift.Removed, msg.Drift.Changed) {
+			m.cache.InvalidateMessage(id)
+		}
 	case opencode.EventListResponseEventMessagePartUpdated:
 		if msg.Properties.Part.SessionID == m.app.Session.ID {
-			m.renderView()
+			if !m.renderPart(msg.Properties.Part.ID) {
+				m.renderView()
+			}
 			if m.tail {
-				m.viewport.GotoBottom()
+				m.transcript.gotoBottom()
 			}
 		}
 	}
 
-	viewport, cmd := m.viewport.Update(msg)
-	m.viewport = viewport
-	m.tail = m.viewport.AtBottom()
-	cmds = append(cmds, cmd)
+	m.tail = m.transcript.atBottom()
 
 	return m, tea.Batch(cmds...)
 }
@@ -124,96 +148,94 @@ func (m *messagesComponent) renderView() {
 	defer measure("messageCount", len(m.app.Messages))
 
 	m.header = m.renderHeader()
+	m.stale = false
+	blocks := m.layoutBlocks()
+	if m.selection != nil && !slices.ContainsFunc(blocks, func(block *messageBlock) bool {
+		return block.id == *m.selection
+	}) {
+		m.selection = nil
+	}
+	m.transcript.reset(blocks)
+	m.transcript.setSize(m.width, m.height-lipgloss.Height(m.header))
+}
 
-	t := theme.CurrentTheme()
-	blocks := make([]string, 0)
-	m.partCount = 0
-	m.lineCount = 0
+// renderPart re-renders only the blocks built from the given part. It reports
+// false when no block depends on the part yet, in which case the layout has to
+// be rebuilt with renderView.
+func (m *messagesComponent) renderPart(partID string) bool {
+	if !m.transcript.has(partID) {
+		return false
+	}
+	measure := util.Measure("messages.renderPart")
+	defer measure("partID", partID)
+	m.transcript.rerender(partID)
+	m.refreshStale()
+	return true
+}
 
-	orphanedToolCalls := make([]opencode.ToolPart, 0)
+// refreshStale rebuilds the layout when re-rendering found blocks laid out
+// from messages that have since been replaced, as after a resync or revert
+func (m *messagesComponent) refreshStale() {
+	if m.stale {
+		m.renderView()
+	}
+}
 
-	width := min(m.width, app.MAX_CONTAINER_WIDTH)
-	if m.app.Config.Layout == opencode.LayoutConfigStretch {
-		width = m.width
+// laidOut reports whether the block laid out at ref with the given id is
+// still rendered from the same message and part
+func (m *messagesComponent) laidOut(ref partRef, id blockID) bool {
+	if ref.message < 0 || ref.message >= len(m.app.Messages) {
+		return false
 	}
+	message := m.app.Messages[ref.message]
+	if message.ID() != id.messageID {
+		return false
+	}
+	if ref.part < 0 {
+		return true
+	}
+	return ref.part < len(message.Parts) && app.PartID(message.Parts[ref.part]) == id.partID
+}
 
-	for _, message := range m.app.Messages {
-		var content string
-		var cached bool
+// partRef locates a part within app.Messages
+type partRef struct {
+	message int
+	part    int
+}
 
+// blockID identifies a transcript block by the message and part it starts
+// at, with an empty part for the error block of a message
+type blockID struct {
+	messageID string
+	partID    string
+}
+
+// layoutBlocks splits the session into the blocks that make up the
+// transcript. Blocks render lazily from app.Messages by index, so a part
+// update in place can re-render its blocks without laying them out again.
+func (m *messagesComponent) layoutBlocks() []*messageBlock {
+	blocks := []*messageBlock{}
+	orphanedToolCalls := []partRef{}
+
+	for messageIndex, message := range m.app.Messages {
 		switch casted := message.Info.(type) {
 		case opencode.UserMessage:
 			for partIndex, part := range message.Parts {
-				switch part := part.(type) {
-				case opencode.TextPart:
-					if part.Synthetic {
-						continue
-					}
-					remainingParts := message.Parts[partIndex+1:]
-					fileParts := make([]opencode.FilePart, 0)
-					for _, part := range remainingParts {
-						switch part := part.(type) {
-						case opencode.FilePart:
-							fileParts = append(fileParts, part)
-						}
-					}
-					flexItems := []layout.FlexItem{}
-					if len(fileParts) > 0 {
-						fileStyle := styles.NewStyle().Background(t.BackgroundElement()).Foreground(t.TextMuted()).Padding(0, 1)
-						mediaTypeStyle := styles.NewStyle().Background(t.Secondary()).Foreground(t.BackgroundPanel()).Padding(0, 1)
-						for _, filePart := range fileParts {
-							mediaType := ""
-							switch filePart.Mime {
-							case "text/plain":
-								mediaType = "txt"
-							case "image/png", "image/jpeg", "image/gif", "image/webp":
-								mediaType = "img"
-								mediaTypeStyle = mediaTypeStyle.Background(t.Accent())
-							case "application/pdf":
-								mediaType = "pdf"
-								mediaTypeStyle = mediaTypeStyle.Background(t.Primary())
-							}
-							flexItems = append(flexItems, layout.FlexItem{
-								View: mediaTypeStyle.Render(mediaType) + fileStyle.Render(filePart.Filename),
-							})
-						}
-					}
-					bgColor := t.BackgroundPanel()
-					files := layout.Render(
-						layout.FlexOptions{
-							Background: &bgColor,
-							Width:      width - 6,
-							Direction:  layout.Column,
-						},
-						flexItems...,
-					)
-
-					key := m.cache.GenerateKey(casted.ID, part.Text, width, files)
-					content, cached = m.cache.Get(key)
-					if !cached {
-						content = renderText(
-							m.app,
-							message.Info,
-							part.Text,
-							m.app.Config.Username,
-							m.showToolDetails,
-							width,
-							files,
-						)
-						content = lipgloss.PlaceHorizontal(
-							m.width,
-							lipgloss.Center,
-							content,
-							styles.WhitespaceStyle(t.Background()),
-						)
-						m.cache.Set(key, content)
-					}
-					if content != "" {
-						m.partCount++
-						m.lineCount += lipgloss.Height(content) + 1
-						blocks = append(blocks, content)
+				textPart, ok := part.(opencode.TextPart)
+				if !ok || textPart.Synthetic {
+					continue
+				}
+				deps := []string{textPart.ID}
+				for _, part := range message.Parts[partIndex+1:] {
+					if filePart, ok := part.(opencode.FilePart); ok {
+						deps = append(deps, filePart.ID)
 					}
 				}
+				blocks = append(blocks, m.newBlock(partRef{messageIndex, partIndex}, deps,
+					func(highlight bool) string {
+						return m.renderUserText(messageIndex, partIndex, highlight)
+					},
+				))
 			}
 
 		case opencode.AssistantMessage:
@@ -222,177 +244,316 @@ func (m *messagesComponent) renderView() {
 				switch part := p.(type) {
 				case opencode.TextPart:
 					hasTextPart = true
-					finished := casted.Time.Completed > 0
-					remainingParts := message.Parts[partIndex+1:]
-					toolCallParts := make([]opencode.ToolPart, 0)
-
 					// sometimes tool calls happen without an assistant message
 					// these should be included in this assistant message as well
-					if len(orphanedToolCalls) > 0 {
-						toolCallParts = append(toolCallParts, orphanedToolCalls...)
-						orphanedToolCalls = make([]opencode.ToolPart, 0)
-					}
+					orphans := orphanedToolCalls
+					orphanedToolCalls = []partRef{}
 
-					remaining := true
-					for _, part := range remainingParts {
-						if !remaining {
+					deps := []string{part.ID}
+					for _, ref := range orphans {
+						deps = append(deps, m.app.Messages[ref.message].Parts[ref.part].(opencode.ToolPart).ID)
+					}
+					for _, part := range message.Parts[partIndex+1:] {
+						// we only want tool calls associated with the current text part.
+						// if we hit another text part, we're done.
+						if _, ok := part.(opencode.TextPart); ok {
 							break
 						}
-						switch part := part.(type) {
-						case opencode.TextPart:
-							// we only want tool calls associated with the current text part.
-							// if we hit another text part, we're done.
-							remaining = false
-						case opencode.ToolPart:
-							toolCallParts = append(toolCallParts, part)
-							if part.State.Status != opencode.ToolPartStateStatusCompleted && part.State.Status != opencode.ToolPartStateStatusError {
-								// i don't think there's a case where a tool call isn't in result state
-								// and the message time is 0, but just in case
-								finished = false
-							}
+						if toolPart, ok := part.(opencode.ToolPart); ok {
+							deps = append(deps, toolPart.ID)
 						}
 					}
-
-					if finished {
-						key := m.cache.GenerateKey(casted.ID, part.Text, width, m.showToolDetails)
-						content, cached = m.cache.Get(key)
-						if !cached {
-							content = renderText(
-								m.app,
-								message.Info,
-								part.Text,
-								casted.ModelID,
-								m.showToolDetails,
-								width,
-								"",
-								toolCallParts...,
-							)
-							content = lipgloss.PlaceHorizontal(
-								m.width,
-								lipgloss.Center,
-								content,
-								styles.WhitespaceStyle(t.Background()),
-							)
-							m.cache.Set(key, content)
-						}
-					} else {
-						content = renderText(
-							m.app,
-							message.Info,
-							part.Text,
-							casted.ModelID,
-							m.showToolDetails,
-							width,
-							"",
-							toolCallParts...,
-						)
-						content = lipgloss.PlaceHorizontal(
-							m.width,
-							lipgloss.Center,
-							content,
-							styles.WhitespaceStyle(t.Background()),
-						)
-					}
-					if content != "" {
-						m.partCount++
-						m.lineCount += lipgloss.Height(content) + 1
-						blocks = append(blocks, content)
-					}
+					blocks = append(blocks, m.newBlock(partRef{messageIndex, partIndex}, deps,
+						func(highlight bool) string {
+							return m.renderAssistantText(messageIndex, partIndex, orphans, highlight)
+						},
+					))
 				case opencode.ToolPart:
 					if !m.showToolDetails {
 						if !hasTextPart {
-							orphanedToolCalls = append(orphanedToolCalls, part)
+							orphanedToolCalls = append(orphanedToolCalls, partRef{messageIndex, partIndex})
 						}
 						continue
 					}
-
-					width := width
-					if m.app.Config.Layout == opencode.LayoutConfigAuto &&
-						part.Tool == "edit" &&
-						part.State.Error == "" {
-						width = min(m.width, app.EDIT_DIFF_MAX_WIDTH)
-					}
-
-					if part.State.Status == opencode.ToolPartStateStatusCompleted || part.State.Status == opencode.ToolPartStateStatusError {
-						key := m.cache.GenerateKey(casted.ID,
-							part.ID,
-							m.showToolDetails,
-							width,
-						)
-						content, cached = m.cache.Get(key)
-						if !cached {
-							content = renderToolDetails(
-								m.app,
-								part,
-								width,
-							)
-							content = lipgloss.PlaceHorizontal(
-								m.width,
-								lipgloss.Center,
-								content,
-								styles.WhitespaceStyle(t.Background()),
-							)
-							m.cache.Set(key, content)
-						}
-					} else {
-						// if the tool call isn't finished, don't cache
-						content = renderToolDetails(
-							m.app,
-							part,
-							width,
-						)
-						content = lipgloss.PlaceHorizontal(
-							m.width,
-							lipgloss.Center,
-							content,
-							styles.WhitespaceStyle(t.Background()),
-						)
-					}
-					if content != "" {
-						m.partCount++
-						m.lineCount += lipgloss.Height(content) + 1
-						blocks = append(blocks, content)
-					}
+					blocks = append(blocks, m.newBlock(partRef{messageIndex, partIndex}, []string{part.ID},
+						func(highlight bool) string {
+							return m.renderToolPart(messageIndex, partIndex, highlight)
+						},
+					))
 				}
 			}
+
+			if app.MessageErrorInfo(casted) != nil {
+				blocks = append(blocks, m.newBlock(partRef{messageIndex, -1}, nil,
+					func(highlight bool) string {
+						return m.renderMessageError(messageIndex, highlight)
+					},
+				))
+			}
+		}
+	}
+
+	// errors reported before the assistant answered have no message to show
+	// them, they follow the prompt instead
+	if last := len(m.app.Messages) - 1; last >= 0 {
+		if _, ok := m.app.Messages[last].Info.(opencode.UserMessage); ok && m.app.LastError() != nil {
+			blocks = append(blocks, m.newBlock(partRef{last, -1}, nil,
+				func(highlight bool) string {
+					return m.renderMessageError(last, highlight)
+				},
+			))
 		}
+	}
+
+	return blocks
+}
+
+// newBlock creates a transcript block. The block under the selection cursor
+// is rendered highlighted, followed by a hint for the selection keys.
+func (m *messagesComponent) newBlock(ref partRef, deps []string, render func(highlight bool) string) *messageBlock {
+	message := m.app.Messages[ref.message]
+	id := blockID{messageID: message.ID()}
+	if ref.part >= 0 {
+		id.partID = app.PartID(message.Parts[ref.part])
+	}
+	return &messageBlock{
+		id:   id,
+		deps: deps,
+		render: func() string {
+			if !m.laidOut(ref, id) {
+				m.stale = true
+				return ""
+			}
+			selected := m.selection != nil && *m.selection == id
+			content := render(selected)
+			if selected && content != "" {
+				content += "\n" + m.renderSelectionHint()
+			}
+			return content
+		},
+	}
+}
+
+func (m *messagesComponent) renderSelectionHint() string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.Background()).Render
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background()).Render
+	hint := base("enter") + muted(" actions  ") + base("esc") + muted(" clear selection")
+	hint = lipgloss.PlaceHorizontal(
+		m.contentWidth(),
+		lipgloss.Right,
+		hint,
+		styles.WhitespaceStyle(t.Background()),
+	)
+	return m.center(hint)
+}
+
+func (m *messagesComponent) contentWidth() int {
+	if m.app.Config.Layout == opencode.LayoutConfigStretch {
+		return m.width
+	}
+	return min(m.width, app.MAX_CONTAINER_WIDTH)
+}
+
+func (m *messagesComponent) center(content string) string {
+	t := theme.CurrentTheme()
+	return lipgloss.PlaceHorizontal(
+		m.width,
+		lipgloss.Center,
+		content,
+		styles.WhitespaceStyle(t.Background()),
+	)
+}
 
-		error := ""
-		if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
-			switch err := assistant.Error.AsUnion().(type) {
-			case nil:
-			case opencode.AssistantMessageErrorMessageOutputLengthError:
-				error = "Message output length exceeded"
-			case opencode.ProviderAuthError:
-				error = err.Data.Message
-			case opencode.MessageAbortedError:
-				error = "Request was aborted"
-			case opencode.UnknownError:
-				error = err.Data.Message
+func (m *messagesComponent) renderUserText(messageIndex, partIndex int, highlight bool) string {
+	t := theme.CurrentTheme()
+	width := m.contentWidth()
+	message := m.app.Messages[messageIndex]
+	part := message.Parts[partIndex].(opencode.TextPart)
+
+	fileParts := make([]opencode.FilePart, 0)
+	for _, part := range message.Parts[partIndex+1:] {
+		switch part := part.(type) {
+		case opencode.FilePart:
+			fileParts = append(fileParts, part)
+		}
+	}
+	flexItems := []layout.FlexItem{}
+	if len(fileParts) > 0 {
+		fileStyle := styles.NewStyle().Background(t.BackgroundElement()).Foreground(t.TextMuted()).Padding(0, 1)
+		mediaTypeStyle := styles.NewStyle().Background(t.Secondary()).Foreground(t.BackgroundPanel()).Padding(0, 1)
+		for _, filePart := range fileParts {
+			mediaType := ""
+			switch filePart.Mime {
+			case "text/plain":
+				mediaType = "txt"
+			case "image/png", "image/jpeg", "image/gif", "image/webp":
+				mediaType = "img"
+				mediaTypeStyle = mediaTypeStyle.Background(t.Accent())
+			case "application/pdf":
+				mediaType = "pdf"
+				mediaTypeStyle = mediaTypeStyle.Background(t.Primary())
 			}
+			flexItems = append(flexItems, layout.FlexItem{
+				View: mediaTypeStyle.Render(mediaType) + fileStyle.Render(filePart.Filename),
+			})
 		}
+	}
+	bgColor := t.BackgroundPanel()
+	files := layout.Render(
+		layout.FlexOptions{
+			Background: &bgColor,
+			Width:      width - 6,
+			Direction:  layout.Column,
+		},
+		flexItems...,
+	)
+
+	render := func(options ...renderingOption) string {
+		return m.center(renderText(
+			m.app,
+			message.Info,
+			part.Text,
+			m.app.Config.Username,
+			m.showToolDetails,
+			width,
+			files,
+			nil,
+			options...,
+		))
+	}
+	if highlight {
+		return render(WithHighlight())
+	}
+	key := m.cache.GenerateKey(message.ID(), part.Text, width, files)
+	content, cached := m.cache.Get(key)
+	if !cached {
+		content = render()
+		m.cache.SetFor(message.ID(), key, content)
+	}
+	return content
+}
 
-		if error != "" {
-			error = styles.NewStyle().Width(width - 6).Render(error)
-			error = renderContentBlock(
-				m.app,
-				error,
-				width,
-				WithBorderColor(t.Error()),
-			)
-			error = lipgloss.PlaceHorizontal(
-				m.width,
-				lipgloss.Center,
-				error,
-				styles.WhitespaceStyle(t.Background()),
-			)
-			blocks = append(blocks, error)
-			m.lineCount += lipgloss.Height(error) + 1
+func (m *messagesComponent) renderAssistantText(messageIndex, partIndex int, orphans []partRef, highlight bool) string {
+	width := m.contentWidth()
+	message := m.app.Messages[messageIndex]
+	casted := message.Info.(opencode.AssistantMessage)
+	part := message.Parts[partIndex].(opencode.TextPart)
+
+	finished := casted.Time.Completed > 0
+	toolCallParts := make([]opencode.ToolPart, 0)
+	for _, ref := range orphans {
+		if ref.message >= len(m.app.Messages) || ref.part >= len(m.app.Messages[ref.message].Parts) {
+			continue
+		}
+		if part, ok := m.app.Messages[ref.message].Parts[ref.part].(opencode.ToolPart); ok {
+			toolCallParts = append(toolCallParts, part)
+		}
+	}
+	for _, part := range message.Parts[partIndex+1:] {
+		if _, ok := part.(opencode.TextPart); ok {
+			break
+		}
+		if part, ok := part.(opencode.ToolPart); ok {
+			toolCallParts = append(toolCallParts, part)
+			if part.State.Status != opencode.ToolPartStateStatusCompleted && part.State.Status != opencode.ToolPartStateStatusError {
+				// i don't think there's a case where a tool call isn't in result state
+				// and the message time is 0, but just in case
+				finished = false
+			}
 		}
 	}
 
-	m.viewport.SetHeight(m.height - lipgloss.Height(m.header))
-	m.viewport.SetContent("\n" + strings.Join(blocks, "\n\n"))
+	render := func(options ...renderingOption) string {
+		return m.center(renderText(
+			m.app,
+			message.Info,
+			part.Text,
+			casted.ModelID,
+			m.showToolDetails,
+			width,
+			"",
+			toolCallParts,
+			options...,
+		))
+	}
+	if highlight {
+		return render(WithHighlight())
+	}
+	if !finished {
+		return render()
+	}
+	key := m.cache.GenerateKey(casted.ID, part.Text, width, m.showToolDetails)
+	content, cached := m.cache.Get(key)
+	if !cached {
+		content = render()
+		m.cache.SetFor(casted.ID, key, content)
+	}
+	return content
+}
+
+func (m *messagesComponent) renderToolPart(messageIndex, partIndex int, highlight bool) string {
+	width := m.contentWidth()
+	message := m.app.Messages[messageIndex]
+	part := message.Parts[partIndex].(opencode.ToolPart)
+
+	if m.app.Config.Layout == opencode.LayoutConfigAuto &&
+		part.Tool == "edit" &&
+		part.State.Error == "" {
+		width = min(m.width, app.EDIT_DIFF_MAX_WIDTH)
+	}
+
+	if highlight {
+		return m.center(renderToolDetails(m.app, part, width, WithHighlight()))
+	}
+	// if the tool call isn't finished, don't cache
+	if part.State.Status != opencode.ToolPartStateStatusCompleted && part.State.Status != opencode.ToolPartStateStatusError {
+		return m.center(renderToolDetails(m.app, part, width))
+	}
+	// the finished state is part of the key, so a tool part the server
+	// updates again renders afresh
+	key := m.cache.GenerateKey(message.ID(),
+		part.ID,
+		part.State.Status,
+		part.State.Title,
+		part.State.Error,
+		part.State.Output,
+		part.State.Input,
+		part.State.Metadata,
+		m.showToolDetails,
+		width,
+	)
+	content, cached := m.cache.Get(key)
+	if !cached {
+		content = m.center(renderToolDetails(m.app, part, width))
+		m.cache.SetFor(message.ID(), key, content)
+	}
+	return content
+}
+
+func (m *messagesComponent) renderMessageError(messageIndex int, highlight bool) string {
+	t := theme.CurrentTheme()
+	width := m.contentWidth()
+	info := m.app.MessageError(messageIndex)
+	if info == nil {
+		return ""
+	}
+	lines := []string{
+		styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundPanel()).Bold(true).Render(info.Title),
+	}
+	if info.Message != "" {
+		lines = append(lines, info.Message)
+	}
+	if !info.Aborted() && messageIndex == len(m.app.Messages)-1 && !m.app.ReadOnly() {
+		lines = append(lines, "", "/retry to send the prompt again or copy the raw error")
+	}
+	error := styles.NewStyle().Width(width - 6).Render(strings.Join(lines, "\n"))
+	options := []renderingOption{WithBorderColor(t.Error())}
+	if highlight {
+		options = append(options, WithHighlight())
+	}
+	error = renderContentBlock(m.app, error, width, options...)
+	return m.center(error)
 }
 
 func (m *messagesComponent) renderHeader() string {
@@ -415,50 +576,51 @@ func (m *messagesComponent) renderHeader() string {
 	)
 
 	share := ""
-	if m.app.Session.Share.URL != "" {
+	if m.app.ReadOnly() {
+		share = muted("imported from " + util.Relative(m.app.ImportedFrom) + " · read-only")
+	} else if m.app.Session.ParentID != "" {
+		share = muted("subagent session")
+		if key := m.app.Keybinding(commands.SessionParentCommand); key != "" {
+			share = base(key) + muted(" back to parent")
+		}
+	} else if m.app.Session.Fork.SessionID != "" {
+		source := "a deleted session"
+		if m.app.ForkSource != nil && m.app.ForkSource.ID == m.app.Session.Fork.SessionID {
+			source = truncate.StringWithTail(m.app.ForkSource.Title, uint(max(headerWidth/3, 12)), "...")
+		}
+		share = muted("⑂ forked from " + source)
+		if key := m.app.Keybinding(commands.SessionParentCommand); key != "" {
+			share += muted(" · ") + base(key) + muted(" back")
+		}
+	} else if m.app.Session.Share.URL != "" {
 		share = muted(m.app.Session.Share.URL + "  /unshare")
 	} else {
 		share = base("/share") + muted(" to create a shareable link")
 	}
 
-	sessionInfo := ""
-	tokens := float64(0)
-	cost := float64(0)
+	usage := app.Usage(m.app.Messages)
 	contextWindow := m.app.Model.Limit.Context
 
-	for _, message := range m.app.Messages {
-		if assistant, ok := message.Info.(opencode.AssistantMessage); ok {
-			cost += assistant.Cost
-			usage := assistant.Tokens
-			if usage.Output > 0 {
-				if assistant.Summary {
-					tokens = usage.Output
-					continue
-				}
-				tokens = (usage.Input +
-					usage.Cache.Write +
-					usage.Cache.Read +
-					usage.Output +
-					usage.Reasoning)
-			}
-		}
-	}
-
 	// Check if current model is a subscription model (cost is 0 for both input and output)
 	isSubscriptionModel := m.app.Model != nil &&
 		m.app.Model.Cost.Input == 0 && m.app.Model.Cost.Output == 0
 
-	sessionInfo = styles.NewStyle().
-		Foreground(t.TextMuted()).
+	infoColor := t.TextMuted()
+	if m.app.ContextWarning(usage) {
+		infoColor = t.Warning()
+	}
+	sessionInfo := styles.NewStyle().
+		Foreground(infoColor).
 		Background(t.Background()).
-		Render(formatTokensAndCost(tokens, contextWindow, cost, isSubscriptionModel))
+		Render(formatTokensAndCost(usage.Context, contextWindow, usage.Cost, isSubscriptionModel))
 
 	background := t.Background()
 
 	var items []layout.FlexItem
 	justify := layout.JustifyEnd
 
-	if m.app.Config.Share != opencode.ConfigShareDisabled {
+	if m.app.Config.Share != opencode.ConfigShareDisabled || m.app.ReadOnly() || m.app.Session.ParentID != "" ||
+		m.app.Session.Fork.SessionID != "" {
 		items = append(items, layout.FlexItem{View: share})
 		justify = layout.JustifySpaceBetween
 	}
@@ -549,46 +711,38 @@ func formatTokensAndCost(
 
 func (m *messagesComponent) View() string {
 	t := theme.CurrentTheme()
-	if m.rendering {
-		return lipgloss.Place(
-			m.width,
-			m.height,
-			lipgloss.Center,
-			lipgloss.Center,
-			styles.NewStyle().Background(t.Background()).Render(""),
-			styles.WhitespaceStyle(t.Background()),
-		)
-	}
-
 	return styles.NewStyle().
 		Background(t.Background()).
-		Render(m.header + "\n" + m.viewport.View())
+		Render(m.header + "\n" + m.transcript.View())
 }
 
+// Reload lays the transcript out again. It runs in Update rather than in a
+// command, since streamed parts re-render the same transcript from Update.
 func (m *messagesComponent) Reload() tea.Cmd {
-	return func() tea.Msg {
-		m.renderView()
-		return renderFinishedMsg{}
+	m.renderView()
+	if m.tail {
+		m.transcript.gotoBottom()
 	}
+	return nil
 }
 
 func (m *messagesComponent) PageUp() (tea.Model, tea.Cmd) {
-	m.viewport.ViewUp()
+	m.transcript.scrollUp(m.transcript.height)
 	return m, nil
 }
 
 func (m *messagesComponent) PageDown() (tea.Model, tea.Cmd) {
-	m.viewport.ViewDown()
+	m.transcript.scrollDown(m.transcript.height)
 	return m, nil
 }
 
 func (m *messagesComponent) HalfPageUp() (tea.Model, tea.Cmd) {
-	m.viewport.HalfViewUp()
+	m.transcript.scrollUp(m.transcript.height / 2)
 	return m, nil
 }
 
 func (m *messagesComponent) HalfPageDown() (tea.Model, tea.Cmd) {
-	m.viewport.HalfViewDown()
+	m.transcript.scrollDown(m.transcript.height / 2)
 	return m, nil
 }
 
@@ -597,45 +751,141 @@ func (m *messagesComponent) ToolDetailsVisible() bool {
 }
 
 func (m *messagesComponent) GotoTop() (tea.Model, tea.Cmd) {
-	m.viewport.GotoTop()
+	m.transcript.gotoTop()
 	return m, nil
 }
 
 func (m *messagesComponent) GotoBottom() (tea.Model, tea.Cmd) {
-	m.viewport.GotoBottom()
+	m.transcript.gotoBottom()
 	return m, nil
 }
 
-func (m *messagesComponent) CopyLastMessage() (tea.Model, tea.Cmd) {
+// CopyMessage copies the text of the selected message, or of the last message
+// when nothing is selected
+func (m *messagesComponent) CopyMessage() (tea.Model, tea.Cmd) {
 	if len(m.app.Messages) == 0 {
 		return m, nil
 	}
-	lastMessage := m.app.Messages[len(m.app.Messages)-1]
-	var lastTextPart *opencode.TextPart
-	for _, part := range lastMessage.Parts {
-		if p, ok := part.(opencode.TextPart); ok {
-			lastTextPart = &p
-		}
+	message := m.app.Messages[len(m.app.Messages)-1]
+	if index, ok := m.selectedMessage(); ok {
+		message = m.app.Messages[index]
 	}
-	if lastTextPart == nil {
+	text := app.MessageText(message)
+	if text == "" {
 		return m, nil
 	}
 	var cmds []tea.Cmd
-	cmds = append(cmds, m.app.SetClipboard(lastTextPart.Text))
+	cmds = append(cmds, m.app.SetClipboard(text))
 	cmds = append(cmds, toast.NewSuccessToast("Message copied to clipboard"))
 	return m, tea.Batch(cmds...)
 }
 
-func NewMessagesComponent(app *app.App) MessagesComponent {
-	vp := viewport.New()
-	vp.KeyMap = viewport.KeyMap{}
-	vp.MouseWheelDelta = 4
+func (m *messagesComponent) SelectPrevious() (tea.Model, tea.Cmd) {
+	m.moveSelection(-1)
+	return m, nil
+}
 
+// SelectNext moves the selection cursor down, clearing the selection and
+// following the conversation again when it moves past the last message
+func (m *messagesComponent) SelectNext() (tea.Model, tea.Cmd) {
+	if m.selection == nil {
+		return m, nil
+	}
+	if !m.moveSelection(1) {
+		return m.ClearSelection()
+	}
+	return m, nil
+}
+
+func (m *messagesComponent) ClearSelection() (tea.Model, tea.Cmd) {
+	if m.selection == nil {
+		return m, nil
+	}
+	previous := m.transcript.find(*m.selection)
+	m.selection = nil
+	m.transcript.rerenderBlock(previous)
+	m.refreshStale()
+	m.transcript.gotoBottom()
+	m.tail = true
+	return m, nil
+}
+
+// moveSelection selects the next visible block in the given direction,
+// starting from the bottom when nothing is selected. It reports false when
+// there is no block left in that direction.
+func (m *messagesComponent) moveSelection(delta int) bool {
+	blocks := m.transcript.blocks
+	current := -1
+	if m.selection != nil {
+		current = m.transcript.find(*m.selection)
+	}
+	next := current
+	if current < 0 {
+		next = len(blocks)
+	}
+	for {
+		next += delta
+		if next < 0 || next >= len(blocks) {
+			return false
+		}
+		if blocks[next].height > 0 {
+			break
+		}
+	}
+	id := blocks[next].id
+	m.selection = &id
+	m.transcript.rerenderBlock(current)
+	m.transcript.rerenderBlock(next)
+	m.refreshStale()
+	m.transcript.scrollToBlock(m.transcript.find(id))
+	m.tail = false
+	return true
+}
+
+// Selection returns the index of the selected message and the parts the
+// selected block is rendered from
+func (m *messagesComponent) Selection() (int, []opencode.PartUnion, bool) {
+	if m.selection == nil {
+		return 0, nil, false
+	}
+	index := m.transcript.find(*m.selection)
+	message, ok := m.selectedMessage()
+	if index < 0 || !ok {
+		return 0, nil, false
+	}
+	deps := m.transcript.blocks[index].deps
+	parts := []opencode.PartUnion{}
+	for _, message := range m.app.Messages {
+		for _, part := range message.Parts {
+			if slices.Contains(deps, app.PartID(part)) {
+				parts = append(parts, part)
+			}
+		}
+	}
+	return message, parts, true
+}
+
+// selectedMessage returns the index of the selected message in app.Messages
+func (m *messagesComponent) selectedMessage() (int, bool) {
+	if m.selection == nil {
+		return 0, false
+	}
+	index := slices.IndexFunc(m.app.Messages, func(message app.Message) bool {
+		return message.ID() == m.selection.messageID
+	})
+	return index, index >= 0
+}
+
+func (m *messagesComponent) CacheStats() PartCacheStats {
+	return m.cache.Stats()
+}
+
+func NewMessagesComponent(app *app.App) MessagesComponent {
 	return &messagesComponent{
 		app:             app,
-		viewport:        vp,
+		transcript:      newTranscript(),
 		showToolDetails: true,
-		cache:           NewPartCache(),
+		cache:           NewPartCache(DefaultPartCacheSize),
 		tail:            true,
 	}
 }
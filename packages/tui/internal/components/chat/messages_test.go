@@ -0,0 +1,220 @@
+package chat
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/theme"
+)
+
+func newTestMessagesComponent(tb testing.TB, exchanges int) *messagesComponent {
+	tb.Helper()
+	if err := theme.LoadThemesFromJSON(); err != nil {
+		tb.Fatalf("Failed to load themes: %v", err)
+	}
+	theme.SetTheme("opencode")
+
+	created := float64(time.Now().UnixMilli())
+	messages := []app.Message{}
+	for i := range exchanges {
+		userID := fmt.Sprintf("msg_%04d_user", i)
+		assistantID := fmt.Sprintf("msg_%04d_assistant", i)
+		messages = append(messages,
+			app.Message{
+				Info: opencode.UserMessage{ID: userID, Role: opencode.UserMessageRoleUser, Time: opencode.UserMessageTime{Created: created}},
+				Parts: []opencode.PartUnion{
+					opencode.TextPart{ID: userID + "_text", MessageID: userID, Type: opencode.TextPartTypeText, Text: "Explain the change in detail"},
+				},
+			},
+			app.Message{
+				Info: opencode.AssistantMessage{ID: assistantID, ModelID: "model", Time: opencode.AssistantMessageTime{Created: created, Completed: created}},
+				Parts: []opencode.PartUnion{
+					opencode.TextPart{ID: assistantID + "_text", MessageID: assistantID, Type: opencode.TextPartTypeText, Text: strings.Repeat("Some **markdown** output. ", 20)},
+				},
+			},
+		)
+	}
+	// the last assistant message is still streaming
+	last := &messages[len(messages)-1]
+	info := last.Info.(opencode.AssistantMessage)
+	info.Time.Completed = 0
+	last.Info = info
+
+	m := NewMessagesComponent(&app.App{
+		Config:   &opencode.Config{},
+		Model:    &opencode.Model{},
+		Session:  &opencode.Session{ID: "ses_test", Title: "Test"},
+		Messages: messages,
+	}).(*messagesComponent)
+	m.width = 100
+	m.height = 40
+	m.renderView()
+	return m
+}
+
+// appendDelta simulates a streamed text delta on the last part of the session
+func appendDelta(m *messagesComponent, delta string) string {
+	message := m.app.Messages[len(m.app.Messages)-1]
+	part := message.Parts[len(message.Parts)-1].(opencode.TextPart)
+	part.Text += delta
+	message.Parts[len(message.Parts)-1] = part
+	return part.ID
+}
+
+func TestRenderPartMatchesFullRender(t *testing.T) {
+	m := newTestMessagesComponent(t, 5)
+	for i := range 20 {
+		delta := fmt.Sprintf(" word%d", i)
+		if i%5 == 0 {
+			delta += "\n\nnew paragraph"
+		}
+		partID := appendDelta(m, delta)
+		if !m.renderPart(partID) {
+			t.Fatalf("expected part %s to be rendered incrementally", partID)
+		}
+	}
+	incremental := slices.Clone(m.transcript.lines)
+
+	m.renderView()
+	if !slices.Equal(incremental, m.transcript.lines) {
+		t.Errorf("incremental render differs from full render: %d lines vs %d lines",
+			len(incremental), len(m.transcript.lines))
+	}
+}
+
+func TestRenderPartUnknownPart(t *testing.T) {
+	m := newTestMessagesComponent(t, 1)
+	if m.renderPart("prt_unknown") {
+		t.Error("expected an unknown part to require a full render")
+	}
+}
+
+func TestSelectionCursor(t *testing.T) {
+	m := newTestMessagesComponent(t, 2)
+	unselected := slices.Clone(m.transcript.lines)
+
+	m.SelectPrevious()
+	m.SelectPrevious()
+	index, parts, ok := m.Selection()
+	if !ok || index != 2 || len(parts) != 1 || app.PartID(parts[0]) != "msg_0001_user_text" {
+		t.Fatalf("expected the last prompt to be selected, got message %d with %d parts", index, len(parts))
+	}
+	selected := slices.Clone(m.transcript.lines)
+	m.renderView()
+	if !slices.Equal(selected, m.transcript.lines) {
+		t.Error("moving the selection differs from a full render of the selection")
+	}
+
+	m.SelectNext()
+	m.SelectNext()
+	if _, _, ok := m.Selection(); ok {
+		t.Error("expected moving past the last message to clear the selection")
+	}
+	if !slices.Equal(unselected, m.transcript.lines) {
+		t.Error("expected clearing the selection to remove the highlight")
+	}
+}
+
+func TestSelectionFollowsMessage(t *testing.T) {
+	m := newTestMessagesComponent(t, 2)
+	m.SelectPrevious()
+	m.SelectPrevious()
+
+	m.app.Messages = m.app.Messages[1:]
+	m.renderView()
+	index, parts, ok := m.Selection()
+	if !ok || index != 1 || len(parts) != 1 || app.PartID(parts[0]) != "msg_0001_user_text" {
+		t.Fatalf("expected the selection to stay on the last prompt, got message %d with %d parts", index, len(parts))
+	}
+
+	m.app.Messages = m.app.Messages[:1]
+	m.renderView()
+	if _, _, ok := m.Selection(); ok {
+		t.Error("expected removing the selected message to clear the selection")
+	}
+	m.CopyMessage()
+}
+
+func TestRenderAfterMessagesReplaced(t *testing.T) {
+	m := newTestMessagesComponent(t, 3)
+	m.app.Messages = []app.Message{}
+	m.SelectPrevious()
+	if _, _, ok := m.Selection(); ok || len(m.transcript.blocks) != 0 {
+		t.Error("expected the layout of the cleared session to be rebuilt")
+	}
+
+	m = newTestMessagesComponent(t, 3)
+	partID := appendDelta(m, " more")
+	m.app.Messages = m.app.Messages[:4]
+	if !m.renderPart(partID) {
+		t.Fatal("expected the part to be rendered")
+	}
+	if len(m.transcript.blocks) != 4 {
+		t.Errorf("expected the layout to follow the trimmed messages, got %d blocks", len(m.transcript.blocks))
+	}
+}
+
+func BenchmarkRenderPart(b *testing.B) {
+	for _, exchanges := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("messages=%d", exchanges*2), func(b *testing.B) {
+			m := newTestMessagesComponent(b, exchanges)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				partID := appendDelta(m, " token")
+				m.renderPart(partID)
+			}
+		})
+	}
+}
+
+func BenchmarkRenderView(b *testing.B) {
+	for _, exchanges := range []int{10, 100} {
+		b.Run(fmt.Sprintf("messages=%d", exchanges*2), func(b *testing.B) {
+			m := newTestMessagesComponent(b, exchanges)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				appendDelta(m, " token")
+				m.renderView()
+			}
+		})
+	}
+}
+
+func TestTranscriptKeys(t *testing.T) {
+	tr := newTranscript()
+	tr.reset([]*messageBlock{{render: func() string {
+		return strings.Repeat("line\n", 99) + "line"
+	}}})
+	tr.setSize(80, 10)
+	tr.gotoBottom()
+	bottom := tr.offset
+
+	steps := []struct {
+		key    tea.KeyPressMsg
+		offset int
+	}{
+		{tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModShift}, bottom - 1},
+		{tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModShift}, bottom - 2},
+		{tea.KeyPressMsg{Code: tea.KeyDown, Mod: tea.ModShift}, bottom - 1},
+		// paging is left to the messages_page_* commands
+		{tea.KeyPressMsg{Code: tea.KeyPgUp}, bottom - 1},
+		{tea.KeyPressMsg{Code: tea.KeyUp}, bottom - 1},
+		{tea.KeyPressMsg{Code: tea.KeyDown, Mod: tea.ModShift}, bottom},
+		{tea.KeyPressMsg{Code: tea.KeyDown, Mod: tea.ModShift}, bottom},
+	}
+	for _, step := range steps {
+		tr.Update(step.key)
+		if tr.offset != step.offset {
+			t.Errorf("%s: expected offset %d, got %d", step.key.String(), step.offset, tr.offset)
+		}
+	}
+	if IsTranscriptKey(tea.KeyPressMsg{Code: tea.KeyUp}) || !IsTranscriptKey(tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModShift}) {
+		t.Error("expected only the scrolling keys to be kept from the editor")
+	}
+}
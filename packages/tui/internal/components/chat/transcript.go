@@ -0,0 +1,204 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// messageBlock is a single rendered unit of the transcript, usually one part
+type messageBlock struct {
+	// deps are the IDs of the parts whose content the block is rendered from
+	deps []string
+	// id identifies the block across layouts, unlike the indexes it is
+	// rendered from
+	id     blockID
+	render func() string
+	start  int
+	height int
+}
+
+// transcript lays rendered blocks out one after another, each preceded by a
+// blank line, and keeps track of where every block starts. A streaming update
+// to a part re-renders only the blocks depending on it and splices their lines
+// in place, so its cost does not grow with the length of the session.
+type transcript struct {
+	blocks     []*messageBlock
+	dependents map[string][]int
+	lines      []string
+	width      int
+	height     int
+	offset     int
+}
+
+// transcriptKeyMap holds the keys scrolling the transcript a line at a time.
+// Paging is left to the messages_page_* commands, and keys the editor needs
+// are left out.
+type transcriptKeyMap struct {
+	Up   key.Binding
+	Down key.Binding
+}
+
+var transcriptKeys = transcriptKeyMap{
+	Up:   key.NewBinding(key.WithKeys("shift+up")),
+	Down: key.NewBinding(key.WithKeys("shift+down")),
+}
+
+// IsTranscriptKey reports whether the key scrolls the transcript, in which
+// case it is not meant for the editor
+func IsTranscriptKey(msg tea.KeyPressMsg) bool {
+	return key.Matches(msg, transcriptKeys.Up, transcriptKeys.Down)
+}
+
+func newTranscript() *transcript {
+	return &transcript{dependents: map[string][]int{}}
+}
+
+// reset renders all blocks and replaces the transcript content
+func (t *transcript) reset(blocks []*messageBlock) {
+	t.blocks = blocks
+	t.dependents = map[string][]int{}
+	t.lines = []string{}
+	for i, block := range blocks {
+		for _, dep := range block.deps {
+			t.dependents[dep] = append(t.dependents[dep], i)
+		}
+		lines := blockLines(block.render())
+		block.start = len(t.lines)
+		block.height = len(lines)
+		t.lines = append(t.lines, lines...)
+	}
+	t.clampOffset()
+}
+
+// has reports whether any block is rendered from the part
+func (t *transcript) has(partID string) bool {
+	_, ok := t.dependents[partID]
+	return ok
+}
+
+// rerender re-renders every block depending on the part and splices the
+// result into the transcript lines
+func (t *transcript) rerender(partID string) {
+	for _, i := range t.dependents[partID] {
+		t.rerenderBlock(i)
+	}
+}
+
+// rerenderBlock re-renders a single block and shifts the blocks after it
+func (t *transcript) rerenderBlock(i int) {
+	if i < 0 || i >= len(t.blocks) {
+		return
+	}
+	block := t.blocks[i]
+	lines := blockLines(block.render())
+	t.lines = slices.Replace(t.lines, block.start, block.start+block.height, lines...)
+	delta := len(lines) - block.height
+	block.height = len(lines)
+	if delta != 0 {
+		for _, next := range t.blocks[i+1:] {
+			next.start += delta
+		}
+	}
+	t.clampOffset()
+}
+
+// find returns the index of the block with the given id, or -1
+func (t *transcript) find(id blockID) int {
+	return slices.IndexFunc(t.blocks, func(block *messageBlock) bool {
+		return block.id == id
+	})
+}
+
+// scrollToBlock scrolls the least amount needed to show the block, keeping
+// its top in view when it is taller than the viewport
+func (t *transcript) scrollToBlock(i int) {
+	if i < 0 || i >= len(t.blocks) {
+		return
+	}
+	block := t.blocks[i]
+	end := block.start + block.height
+	switch {
+	case block.start < t.offset || block.height > t.height:
+		t.offset = block.start
+	case end > t.offset+t.height:
+		t.offset = end - t.height
+	}
+	t.clampOffset()
+}
+
+func blockLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return append([]string{""}, strings.Split(content, "\n")...)
+}
+
+func (t *transcript) setSize(width, height int) {
+	t.width = width
+	t.height = max(height, 0)
+	t.clampOffset()
+}
+
+func (t *transcript) maxOffset() int {
+	return max(0, len(t.lines)-t.height)
+}
+
+func (t *transcript) clampOffset() {
+	t.offset = max(0, min(t.offset, t.maxOffset()))
+}
+
+func (t *transcript) scrollUp(n int) {
+	t.offset = max(0, t.offset-n)
+}
+
+func (t *transcript) scrollDown(n int) {
+	t.offset = min(t.maxOffset(), t.offset+n)
+}
+
+func (t *transcript) gotoTop() {
+	t.offset = 0
+}
+
+func (t *transcript) gotoBottom() {
+	t.offset = t.maxOffset()
+}
+
+func (t *transcript) atBottom() bool {
+	return t.offset >= t.maxOffset()
+}
+
+// Update scrolls the transcript on key presses and mouse wheel events
+func (t *transcript) Update(msg tea.Msg) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, transcriptKeys.Down):
+			t.scrollDown(1)
+		case key.Matches(msg, transcriptKeys.Up):
+			t.scrollUp(1)
+		}
+	case tea.MouseWheelMsg:
+		switch msg.Button {
+		case tea.MouseWheelUp:
+			t.scrollUp(mouseWheelDelta)
+		case tea.MouseWheelDown:
+			t.scrollDown(mouseWheelDelta)
+		}
+	}
+}
+
+func (t *transcript) View() string {
+	end := min(len(t.lines), t.offset+t.height)
+	visible := t.lines[min(t.offset, end):end]
+	return styles.NewStyle().
+		Background(theme.CurrentTheme().Background()).
+		Width(t.width).
+		Height(t.height).
+		MaxHeight(t.height).
+		Render(strings.Join(visible, "\n"))
+}
@@ -0,0 +1,363 @@
+package dialog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// compareMinModels is the number of models a comparison needs
+const compareMinModels = 2
+
+// CompareDialog interface for picking the models to send a prompt to side
+// by side
+type CompareDialog interface {
+	layout.Modal
+	// isCompareDialog tells this dialog apart from other modals
+	isCompareDialog()
+}
+
+// compareModel is a model offered for comparison
+type compareModel struct {
+	provider opencode.Provider
+	model    opencode.Model
+	selected bool
+}
+
+type compareDialog struct {
+	width       int
+	height      int
+	app         *app.App
+	prompt      string
+	attachments []opencode.FilePartParam
+	models      []compareModel
+	modal       *modal.Modal
+	list        list.List[compareModel]
+}
+
+func (d *compareDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *compareDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "space":
+			_, idx := d.list.GetSelectedItem()
+			if idx < 0 {
+				return d, nil
+			}
+			d.models[idx].selected = !d.models[idx].selected
+			d.list.SetItems(d.models)
+			d.list.SetSelectedIndex(idx)
+			return d, nil
+		case "enter":
+			models := d.selected()
+			if len(models) < compareMinModels {
+				return d, toast.NewInfoToast(fmt.Sprintf("Select at least %d models with space", compareMinModels))
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(app.CompareMsg{Prompt: d.prompt, Attachments: d.attachments, Models: models}),
+			)
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[compareModel])
+	return d, cmd
+}
+
+// selected returns the models picked for the comparison, in list order
+func (d *compareDialog) selected() []app.ModelSelectedMsg {
+	models := []app.ModelSelectedMsg{}
+	for _, item := range d.models {
+		if item.selected {
+			models = append(models, app.ModelSelectedMsg{Provider: item.provider, Model: item.model})
+		}
+	}
+	return models
+}
+
+func (d *compareDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	width := layout.Current.Container.Width - 12
+	muted := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel())
+	pad := styles.NewStyle().PaddingLeft(1).Render
+
+	prompt := truncate.StringWithTail(historyItemText(d.prompt), uint(max(width-2, 1)), "...")
+	hint := fmt.Sprintf("space select · enter compare (%d selected)", len(d.selected()))
+	lines := []string{
+		pad(muted.Render(prompt)),
+		"",
+		d.list.View(),
+		"",
+		pad(muted.Render(hint)),
+	}
+	return d.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+func (d *compareDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *compareDialog) isCompareDialog() {}
+
+// compareModels lists the models to compare: the current one and the most
+// recently used ones first, then every other model by provider and name
+func compareModels(a *app.App) []compareModel {
+	choices := modelChoices{}
+	choices.addRecent(a)
+
+	providers := slices.Clone(a.Providers)
+	slices.SortFunc(providers, func(x, y opencode.Provider) int {
+		return strings.Compare(x.Name, y.Name)
+	})
+	for _, provider := range providers {
+		ids := []string{}
+		for id := range provider.Models {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, func(x, y string) int {
+			return strings.Compare(provider.Models[x].Name, provider.Models[y].Name)
+		})
+		for _, id := range ids {
+			model := provider.Models[id]
+			choices.add(&provider, &model)
+		}
+	}
+	models := []compareModel{}
+	for _, choice := range choices {
+		models = append(models, compareModel{provider: choice.Provider, model: choice.Model})
+	}
+	return models
+}
+
+// NewCompareDialog creates a picker for the models to send prompt and its
+// attachments to
+func NewCompareDialog(a *app.App, prompt string, attachments []opencode.FilePartParam) CompareDialog {
+	models := compareModels(a)
+	listComponent := list.NewListComponent(
+		list.WithItems(models),
+		list.WithMaxVisibleHeight[compareModel](10),
+		list.WithFallbackMessage[compareModel]("No models available"),
+		list.WithAlphaNumericKeys[compareModel](true),
+		list.WithRenderFunc(
+			func(item compareModel, selected bool, width int, baseStyle styles.Style) string {
+				t := theme.CurrentTheme()
+				mark := "[ ] "
+				if item.selected {
+					mark = "[x] "
+				}
+				label := mark + item.provider.Name + " " + item.model.Name
+				label = truncate.StringWithTail(label, uint(max(width-1, 1)), "...")
+				if selected {
+					return baseStyle.
+						Background(t.Primary()).
+						Foreground(t.BackgroundElement()).
+						Width(width).
+						PaddingLeft(1).
+						Render(label)
+				}
+				return baseStyle.PaddingLeft(1).Render(label)
+			},
+		),
+		list.WithSelectableFunc(func(item compareModel) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &compareDialog{
+		app:         a,
+		prompt:      prompt,
+		attachments: attachments,
+		models:      models,
+		list:        listComponent,
+		modal: modal.New(
+			modal.WithTitle("Compare Models"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
+
+// ComparisonTickMsg refreshes the comparison while models are answering
+type ComparisonTickMsg struct{}
+
+// ComparisonDialog interface for the answers of a comparison side by side
+type ComparisonDialog interface {
+	layout.Modal
+	// isComparisonDialog tells this dialog apart from other modals
+	isComparisonDialog()
+}
+
+type comparisonDialog struct {
+	width    int
+	height   int
+	app      *app.App
+	modal    *modal.Modal
+	selected int
+}
+
+func (d *comparisonDialog) Init() tea.Cmd {
+	return comparisonTick()
+}
+
+func comparisonTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return ComparisonTickMsg{}
+	})
+}
+
+func (d *comparisonDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	comparison := d.app.Comparison
+	if comparison == nil {
+		return d, nil
+	}
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+	case ComparisonTickMsg:
+		for _, run := range comparison.Runs {
+			if !d.app.Result(run).Done {
+				return d, comparisonTick()
+			}
+		}
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "left", "h", "shift+tab":
+			d.selected = (d.selected - 1 + len(comparison.Runs)) % len(comparison.Runs)
+		case "right", "l", "tab":
+			d.selected = (d.selected + 1) % len(comparison.Runs)
+		case "enter":
+			run := comparison.Runs[d.selected]
+			session := d.app.AdoptComparison(d.selected)
+			if session == nil {
+				return d, nil
+			}
+			// continue with the model that gave the adopted answer
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(app.ModelSelectedMsg{Provider: run.Provider, Model: run.Model}),
+				util.CmdHandler(app.SessionSelectedMsg(session)),
+			)
+		}
+	}
+	return d, nil
+}
+
+// comparisonStats formats the tokens, cost and latency of a result
+func comparisonStats(result app.ComparisonResult) string {
+	latency := result.Latency.Round(100 * time.Millisecond).String()
+	if !result.Done {
+		latency = "running " + result.Latency.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%s in · %s out · $%.4f · %s",
+		formatTokenCount(result.Tokens.Input+result.Tokens.CacheRead+result.Tokens.CacheWrite),
+		formatTokenCount(result.Tokens.Output+result.Tokens.Reasoning),
+		result.Cost,
+		latency,
+	)
+}
+
+func (d *comparisonDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	comparison := d.app.Comparison
+	if comparison == nil {
+		return background
+	}
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+
+	width := layout.Current.Container.Width - 12
+	gap := 2
+	columnWidth := max((width-gap*(len(comparison.Runs)-1))/len(comparison.Runs), 10)
+	textHeight := max(layout.Current.Viewport.Height-16, 4)
+
+	columns := []string{}
+	for i, run := range comparison.Runs {
+		result := d.app.Result(run)
+		header := base.Bold(true)
+		if i == d.selected {
+			header = header.Foreground(t.Primary())
+		}
+		lines := []string{
+			header.Render(truncate.StringWithTail(run.Provider.Name+" "+run.Model.Name, uint(columnWidth), "...")),
+			muted.Render(truncate.StringWithTail(comparisonStats(result), uint(columnWidth), "...")),
+		}
+		if result.Error != nil {
+			lines = append(lines, base.Foreground(t.Error()).Render(
+				truncate.StringWithTail(result.Error.String(), uint(columnWidth), "..."),
+			))
+		}
+		lines = append(lines, "")
+
+		text := []string{}
+		for line := range strings.SplitSeq(wordwrap.String(result.Text, columnWidth), "\n") {
+			text = append(text, truncate.String(line, uint(columnWidth)))
+		}
+		if len(text) > textHeight {
+			text = append([]string{"..."}, text[len(text)-textHeight+1:]...)
+		}
+		for _, line := range text {
+			lines = append(lines, base.Render(line))
+		}
+		column := base.Width(columnWidth).Render(strings.Join(lines, "\n"))
+		if i > 0 {
+			column = base.PaddingLeft(gap).Render(column)
+		}
+		columns = append(columns, column)
+	}
+
+	prompt := truncate.StringWithTail(historyItemText(comparison.Prompt), uint(max(width, 1)), "...")
+	body := []string{
+		muted.Render(prompt),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+		"",
+		muted.Render("←/→ select · enter continue with the selected answer · esc keep comparing"),
+	}
+	return d.modal.Render(
+		styles.NewStyle().PaddingLeft(1).Render(strings.Join(body, "\n")),
+		background,
+	)
+}
+
+func (d *comparisonDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *comparisonDialog) isComparisonDialog() {}
+
+// NewComparisonDialog creates the split view of the answers of the current
+// comparison, from which one is adopted as the continuation
+func NewComparisonDialog(a *app.App) ComparisonDialog {
+	return &comparisonDialog{
+		app: a,
+		modal: modal.New(
+			modal.WithTitle("Comparison"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
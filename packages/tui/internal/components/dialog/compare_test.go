@@ -0,0 +1,49 @@
+package dialog
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/config"
+)
+
+func TestCompareModels(t *testing.T) {
+	first := opencode.Provider{ID: "p", Name: "P", Models: map[string]opencode.Model{
+		"a": {ID: "a", Name: "A"},
+		"b": {ID: "b", Name: "B"},
+	}}
+	second := opencode.Provider{ID: "o", Name: "O", Models: map[string]opencode.Model{
+		"c": {ID: "c", Name: "C"},
+	}}
+	a := &app.App{
+		Providers: []opencode.Provider{first, second},
+		Provider:  &first,
+		Model:     &opencode.Model{ID: "b", Name: "B"},
+		State: &config.State{RecentlyUsedModels: []config.ModelUsage{
+			{ProviderID: "p", ModelID: "a"},
+		}},
+	}
+
+	got := []string{}
+	for _, item := range compareModels(a) {
+		got = append(got, item.provider.ID+"/"+item.model.ID)
+	}
+	want := []string{"p/b", "p/a", "o/c"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	d := NewCompareDialog(a, "fix the tests", nil).(*compareDialog)
+	d.models[0].selected = true
+	d.models[2].selected = true
+	selected := d.selected()
+	if len(selected) != 2 || selected[0].Model.ID != "b" || selected[1].Model.ID != "c" {
+		t.Errorf("unexpected selection %+v", selected)
+	}
+}
@@ -0,0 +1,243 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// DiagnosticSelectedMsg is sent when a diagnostic is picked to open its file
+// at the reported location
+type DiagnosticSelectedMsg struct {
+	FilePath string
+	// Line is zero based, as reported by the language server
+	Line int
+}
+
+// DiagnosticsDialog interface for the LSP diagnostics panel
+type DiagnosticsDialog interface {
+	layout.Modal
+	// isDiagnosticsDialog tells this dialog apart from other modals
+	isDiagnosticsDialog()
+}
+
+// diagnosticsFilters are cycled through with "f", each one including every
+// severity up to and including its own
+var diagnosticsFilters = []app.DiagnosticSeverity{
+	app.DiagnosticSeverityError,
+	app.DiagnosticSeverityWarning,
+	app.DiagnosticSeverityHint,
+}
+
+type diagnosticItem struct {
+	// header is set for the non selectable rows grouping diagnostics per file
+	header     string
+	diagnostic app.FileDiagnostic
+}
+
+type diagnosticsDialog struct {
+	width  int
+	height int
+	app    *app.App
+	modal  *modal.Modal
+	list   list.List[diagnosticItem]
+	filter int
+	// leader is set after the leader key was pressed, so the toggle key
+	// reaches the dialog whole and closes it
+	leader bool
+}
+
+func (d *diagnosticsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *diagnosticsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case opencode.EventListResponseEventLspClientDiagnostics:
+		d.refresh()
+	case tea.KeyPressMsg:
+		leader := d.leader
+		d.leader = false
+		if d.app.Commands[commands.DiagnosticsToggleCommand].Matches(msg, leader) {
+			return d, util.CmdHandler(modal.CloseModalMsg{})
+		}
+		if !leader && d.app.Config.Keybinds.Leader != "" && msg.String() == d.app.Config.Keybinds.Leader {
+			d.leader = true
+			return d, nil
+		}
+		switch msg.String() {
+		case "f":
+			d.filter = (d.filter + 1) % len(diagnosticsFilters)
+			d.refresh()
+			return d, nil
+		case "enter":
+			item, idx := d.list.GetSelectedItem()
+			if idx < 0 {
+				return d, nil
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(DiagnosticSelectedMsg{
+					FilePath: util.Relative(item.diagnostic.Path),
+					Line:     int(item.diagnostic.Diagnostic.Range.Start.Line),
+				}),
+			)
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[diagnosticItem])
+	return d, cmd
+}
+
+// refresh rebuilds the rows from the current diagnostics, keeping the
+// selection on the same diagnostic when it is still reported
+func (d *diagnosticsDialog) refresh() {
+	selected, idx := d.list.GetSelectedItem()
+	items := diagnosticItems(d.app.Diagnostics.List(diagnosticsFilters[d.filter]))
+	d.list.SetItems(items)
+	if idx < 0 {
+		return
+	}
+	previous := selected.diagnostic
+	for i, item := range items {
+		current := item.diagnostic
+		if item.header == "" && current.Path == previous.Path &&
+			current.Diagnostic.Range.Start.Line == previous.Diagnostic.Range.Start.Line &&
+			current.Diagnostic.Range.Start.Character == previous.Diagnostic.Range.Start.Character &&
+			current.Diagnostic.Message == previous.Diagnostic.Message {
+			d.list.SetSelectedIndex(i)
+			return
+		}
+	}
+}
+
+func (d *diagnosticsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+
+	errors := d.app.Diagnostics.Count(app.DiagnosticSeverityError)
+	warnings := d.app.Diagnostics.Count(app.DiagnosticSeverityWarning)
+	summary := base.Foreground(t.Error()).Render(fmt.Sprintf("%d errors", errors)) +
+		muted.Render(", ") +
+		base.Foreground(t.Warning()).Render(fmt.Sprintf("%d warnings", warnings))
+
+	filter := "errors"
+	switch diagnosticsFilters[d.filter] {
+	case app.DiagnosticSeverityWarning:
+		filter = "errors and warnings"
+	case app.DiagnosticSeverityHint:
+		filter = "all"
+	}
+
+	help := base.Render("enter") + muted.Render(" open  ") +
+		base.Render("f") + muted.Render(" showing "+filter)
+	content := strings.Join([]string{
+		styles.NewStyle().PaddingLeft(1).Render(summary),
+		d.list.View(),
+		styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(help),
+	}, "\n")
+	return d.modal.Render(content, background)
+}
+
+func (d *diagnosticsDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *diagnosticsDialog) isDiagnosticsDialog() {}
+
+func diagnosticItems(diagnostics []app.FileDiagnostic) []diagnosticItem {
+	items := []diagnosticItem{}
+	for i, diagnostic := range diagnostics {
+		if i == 0 || diagnostics[i-1].Path != diagnostic.Path {
+			items = append(items, diagnosticItem{header: util.Relative(diagnostic.Path)})
+		}
+		items = append(items, diagnosticItem{diagnostic: diagnostic})
+	}
+	return items
+}
+
+func renderDiagnosticItem(item diagnosticItem, selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	if item.header != "" {
+		header := truncate.StringWithTail(item.header, uint(max(width-1, 1)), "...")
+		return baseStyle.Foreground(t.Accent()).Bold(true).PaddingLeft(1).Render(header)
+	}
+
+	diagnostic := item.diagnostic.Diagnostic
+	severity := app.Severity(diagnostic)
+	label, color := "I", t.Info()
+	switch severity {
+	case app.DiagnosticSeverityError:
+		label, color = "E", t.Error()
+	case app.DiagnosticSeverityWarning:
+		label, color = "W", t.Warning()
+	case app.DiagnosticSeverityHint:
+		label, color = "H", t.TextMuted()
+	}
+
+	// positions are zero based in the protocol but shown one based like editors do
+	position := fmt.Sprintf("%d:%d",
+		int(diagnostic.Range.Start.Line)+1,
+		int(diagnostic.Range.Start.Character)+1,
+	)
+	text := strings.Join(strings.Fields(diagnostic.Message), " ")
+	if diagnostic.Source != "" {
+		text += " [" + diagnostic.Source + "]"
+	}
+	prefix := fmt.Sprintf("  %s %-8s ", label, position)
+	text = truncate.StringWithTail(text, uint(max(width-len(prefix)-1, 1)), "...")
+
+	if selected {
+		return baseStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement()).
+			Width(width).
+			PaddingLeft(1).
+			Render(prefix + text)
+	}
+	return baseStyle.PaddingLeft(1).Foreground(color).Render(prefix) +
+		baseStyle.Render(text)
+}
+
+// NewDiagnosticsDialog creates a panel listing the diagnostics reported by
+// the language servers, grouped per file
+func NewDiagnosticsDialog(app *app.App) DiagnosticsDialog {
+	listComponent := list.NewListComponent(
+		list.WithMaxVisibleHeight[diagnosticItem](12),
+		list.WithFallbackMessage[diagnosticItem]("No diagnostics"),
+		list.WithAlphaNumericKeys[diagnosticItem](true),
+		list.WithRenderFunc(renderDiagnosticItem),
+		list.WithSelectableFunc(func(item diagnosticItem) bool {
+			return item.header == ""
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	dialog := &diagnosticsDialog{
+		app:  app,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Diagnostics"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+	dialog.refresh()
+	return dialog
+}
@@ -0,0 +1,165 @@
+package dialog
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// ForkDialog interface for picking the message a session is forked at
+type ForkDialog interface {
+	layout.Modal
+	// isForkDialog tells this dialog apart from other modals
+	isForkDialog()
+}
+
+type forkItem struct {
+	messageID string
+	role      string
+	text      string
+}
+
+type forkDialog struct {
+	width  int
+	height int
+	app    *app.App
+	modal  *modal.Modal
+	list   list.List[forkItem]
+}
+
+func (f *forkDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (f *forkDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		f.width = msg.Width
+		f.height = msg.Height
+		f.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		if msg.String() == "enter" {
+			item, idx := f.list.GetSelectedItem()
+			if idx < 0 {
+				return f, nil
+			}
+			return f, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				f.app.ForkSession(context.Background(), item.messageID),
+			)
+		}
+	}
+
+	listModel, cmd := f.list.Update(msg)
+	f.list = listModel.(list.List[forkItem])
+	return f, cmd
+}
+
+func (f *forkDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+
+	help := base.Render("enter") + muted.Render(" fork with the messages up to here")
+	content := strings.Join([]string{
+		f.list.View(),
+		styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(help),
+	}, "\n")
+	return f.modal.Render(content, background)
+}
+
+func (f *forkDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (f *forkDialog) isForkDialog() {}
+
+// forkItemText summarizes a message by its first text part, falling back to
+// the tools it called
+func forkItemText(message app.Message) string {
+	var tools []string
+	for _, part := range message.Parts {
+		switch casted := part.(type) {
+		case opencode.TextPart:
+			if !casted.Synthetic && strings.TrimSpace(casted.Text) != "" {
+				return strings.Join(strings.Fields(casted.Text), " ")
+			}
+		case opencode.ToolPart:
+			tools = append(tools, casted.Tool)
+		}
+	}
+	if len(tools) > 0 {
+		return "called " + strings.Join(tools, ", ")
+	}
+	return "(empty)"
+}
+
+func renderForkItem(item forkItem, selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	role := item.role + " "
+	text := truncate.StringWithTail(item.text, uint(max(width-len(role)-1, 1)), "...")
+	if selected {
+		return baseStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement()).
+			Width(width).
+			PaddingLeft(1).
+			Render(role + text)
+	}
+	roleColor := t.Secondary()
+	if item.role == "user" {
+		roleColor = t.Accent()
+	}
+	return baseStyle.PaddingLeft(1).Foreground(roleColor).Render(role) + baseStyle.Render(text)
+}
+
+// NewForkDialog creates a picker for the message the current session is
+// forked at, defaulting to the most recent one
+func NewForkDialog(a *app.App) ForkDialog {
+	var items []forkItem
+	for _, message := range a.Messages {
+		role := "user"
+		if _, ok := message.Info.(opencode.AssistantMessage); ok {
+			role = "assistant"
+		}
+		items = append(items, forkItem{
+			messageID: message.ID(),
+			role:      role,
+			text:      forkItemText(message),
+		})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[forkItem](10),
+		list.WithFallbackMessage[forkItem]("No messages to fork from"),
+		list.WithAlphaNumericKeys[forkItem](true),
+		list.WithRenderFunc(renderForkItem),
+		list.WithSelectableFunc(func(item forkItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+	if len(items) > 0 {
+		listComponent.SetSelectedIndex(len(items) - 1)
+	}
+
+	return &forkDialog{
+		app:  a,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Fork Session"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
@@ -0,0 +1,42 @@
+package dialog
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+func TestForkItemText(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []opencode.PartUnion
+		want  string
+	}{
+		{
+			name: "first text part",
+			parts: []opencode.PartUnion{
+				opencode.TextPart{Text: "context", Synthetic: true},
+				opencode.TextPart{Text: "  fix the\n  login flow "},
+			},
+			want: "fix the login flow",
+		},
+		{
+			name: "tools only",
+			parts: []opencode.PartUnion{
+				opencode.ToolPart{Tool: "read"},
+				opencode.ToolPart{Tool: "edit"},
+			},
+			want: "called read, edit",
+		},
+		{name: "empty", want: "(empty)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := forkItemText(app.Message{Info: opencode.UserMessage{}, Parts: tt.parts})
+			if got != tt.want {
+				t.Errorf("forkItemText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
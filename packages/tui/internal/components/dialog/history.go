@@ -0,0 +1,167 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/config"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const numVisiblePrompts = 10
+
+// PromptRecalledMsg loads a prompt from the history into the editor
+type PromptRecalledMsg struct {
+	Entry config.PromptEntry
+}
+
+// HistoryDialog interface for searching the prompt history
+type HistoryDialog interface {
+	layout.Modal
+	// isHistoryDialog tells this dialog apart from other modals
+	isHistoryDialog()
+}
+
+type historyItem struct {
+	entry config.PromptEntry
+}
+
+func (h historyItem) Render(
+	selected bool,
+	width int,
+	baseStyle styles.Style,
+) string {
+	t := theme.CurrentTheme()
+
+	columns := h.columns(time.Now())
+	textWidth := max(width-lipgloss.Width(columns)-2, 1)
+	text := truncate.StringWithTail(historyItemText(h.entry.Text), uint(textWidth), "...")
+	text += strings.Repeat(" ", max(textWidth-lipgloss.Width(text), 0))
+
+	if selected {
+		return baseStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement()).
+			Width(width).
+			PaddingLeft(1).
+			Render(text + " " + columns)
+	}
+	return baseStyle.PaddingLeft(1).Render(text) +
+		baseStyle.Foreground(t.TextMuted()).Render(" "+columns)
+}
+
+func (h historyItem) Selectable() bool {
+	return true
+}
+
+// columns renders how long ago the prompt was sent and how many files were
+// attached to it
+func (h historyItem) columns(now time.Time) string {
+	files := ""
+	if n := len(h.entry.Attachments); n > 0 {
+		files = fmt.Sprintf("%d file", n)
+		if n > 1 {
+			files += "s"
+		}
+	}
+	return fmt.Sprintf("%7s %8s", files, formatAge(now.Sub(h.entry.Time)))
+}
+
+// historyItemText flattens a prompt to one line, marking the lines left out
+func historyItemText(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	first := strings.TrimSpace(lines[0])
+	if len(lines) > 1 {
+		first += fmt.Sprintf(" (+%d lines)", len(lines)-1)
+	}
+	return first
+}
+
+type historyDialog struct {
+	width        int
+	height       int
+	modal        *modal.Modal
+	searchDialog *SearchDialog
+	app          *app.App
+}
+
+func (h *historyDialog) Init() tea.Cmd {
+	return h.searchDialog.Init()
+}
+
+func (h *historyDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h.width = msg.Width
+		h.height = msg.Height
+		h.searchDialog.SetWidth(layout.Current.Container.Width - 12)
+	case SearchQueryChangedMsg:
+		h.refresh()
+		return h, nil
+	case SearchSelectionMsg:
+		if item, ok := msg.Item.(historyItem); ok {
+			return h, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(PromptRecalledMsg{Entry: item.entry}),
+			)
+		}
+	case SearchCancelledMsg:
+		return h, util.CmdHandler(modal.CloseModalMsg{})
+	}
+
+	// pressing ctrl+r again steps to the next older match, like a shell
+	if key, ok := msg.(tea.KeyPressMsg); ok && key.String() == "ctrl+r" {
+		msg = tea.KeyPressMsg{Code: tea.KeyDown}
+	}
+	updatedDialog, cmd := h.searchDialog.Update(msg)
+	h.searchDialog = updatedDialog.(*SearchDialog)
+	return h, cmd
+}
+
+func (h *historyDialog) refresh() {
+	items := []list.Item{}
+	if h.app.History != nil {
+		for _, entry := range h.app.History.Search(h.searchDialog.GetQuery()) {
+			items = append(items, historyItem{entry: entry})
+		}
+	}
+	h.searchDialog.SetItems(items)
+}
+
+func (h *historyDialog) Render(background string) string {
+	return h.modal.Render(h.searchDialog.View(), background)
+}
+
+func (h *historyDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (h *historyDialog) isHistoryDialog() {}
+
+// NewHistoryDialog creates a dialog searching the prompts sent in this
+// project, newest first
+func NewHistoryDialog(a *app.App) HistoryDialog {
+	searchDialog := NewSearchDialog("Search prompt history...", numVisiblePrompts)
+	searchDialog.SetWidth(layout.Current.Container.Width - 12)
+
+	dialog := &historyDialog{
+		searchDialog: searchDialog,
+		app:          a,
+		modal: modal.New(
+			modal.WithTitle("Prompt History"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+	dialog.refresh()
+	return dialog
+}
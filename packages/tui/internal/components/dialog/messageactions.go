@@ -0,0 +1,219 @@
+package dialog
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/commands"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/export"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// DiffSelectedMsg opens the diff of an edit in the file viewer
+type DiffSelectedMsg struct {
+	FilePath string
+	Diff     string
+}
+
+// MessageActionsDialog interface for the actions on the selected message
+type MessageActionsDialog interface {
+	layout.Modal
+	// isMessageActionsDialog tells this dialog apart from other modals
+	isMessageActionsDialog()
+}
+
+type messageAction struct {
+	label string
+	run   func() tea.Cmd
+}
+
+type messageActionsDialog struct {
+	width  int
+	height int
+	modal  *modal.Modal
+	list   list.List[messageAction]
+}
+
+func (d *messageActionsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *messageActionsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		if msg.String() == "enter" {
+			item, idx := d.list.GetSelectedItem()
+			if idx < 0 {
+				return d, nil
+			}
+			return d, tea.Sequence(util.CmdHandler(modal.CloseModalMsg{}), item.run())
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[messageAction])
+	return d, cmd
+}
+
+func (d *messageActionsDialog) Render(background string) string {
+	return d.modal.Render(d.list.View(), background)
+}
+
+func (d *messageActionsDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *messageActionsDialog) isMessageActionsDialog() {}
+
+// promptMessage finds the user message that started the turn containing the
+// message at index
+func promptMessage(messages []app.Message, index int) (app.Message, bool) {
+	for i := min(index, len(messages)-1); i >= 0; i-- {
+		if _, ok := messages[i].Info.(opencode.UserMessage); ok {
+			return messages[i], true
+		}
+	}
+	return app.Message{}, false
+}
+
+// messageActions lists what can be done with the message at index, given the
+// parts of the selected block
+func messageActions(a *app.App, index int, parts []opencode.PartUnion) []messageAction {
+	message := a.Messages[index]
+	copyText := func(text, confirmation string) func() tea.Cmd {
+		return func() tea.Cmd {
+			return tea.Batch(a.SetClipboard(text), toast.NewSuccessToast(confirmation))
+		}
+	}
+
+	actions := []messageAction{}
+	if text := app.MessageText(message); text != "" {
+		actions = append(actions, messageAction{
+			label: "Copy text",
+			run:   copyText(text, "Message copied to clipboard"),
+		})
+	}
+	actions = append(actions, messageAction{
+		label: "Copy as Markdown",
+		run:   copyText(strings.TrimSpace(export.MessageMarkdown(message)), "Markdown copied to clipboard"),
+	})
+
+	for _, part := range parts {
+		tool, ok := part.(opencode.ToolPart)
+		if !ok {
+			continue
+		}
+		title := tool.Tool
+		if tool.State.Title != "" {
+			title += ": " + tool.State.Title
+		}
+		if tool.State.Output != "" {
+			actions = append(actions, messageAction{
+				label: "Copy output of " + title,
+				run:   copyText(tool.State.Output, "Tool output copied to clipboard"),
+			})
+		}
+		input, _ := tool.State.Input.(map[string]any)
+		metadata, _ := tool.State.Metadata.(map[string]any)
+		filePath, _ := input["filePath"].(string)
+		diff, _ := metadata["diff"].(string)
+		if filePath != "" && diff != "" {
+			actions = append(actions, messageAction{
+				label: "Open diff of " + util.Relative(filePath),
+				run: func() tea.Cmd {
+					return util.CmdHandler(DiffSelectedMsg{FilePath: filePath, Diff: diff})
+				},
+			})
+		}
+	}
+
+	if info := a.MessageError(index); info != nil {
+		actions = append(actions, messageAction{
+			label: "Copy raw error",
+			run:   copyText(info.Raw, "Raw error copied to clipboard"),
+		})
+		if !info.Aborted() && index == len(a.Messages)-1 && !a.ReadOnly() {
+			actions = append(actions, messageAction{
+				label: "Retry with the same or another model",
+				run: func() tea.Cmd {
+					return util.CmdHandler(commands.ExecuteCommandMsg(a.Commands[commands.SessionRetryCommand]))
+				},
+			})
+		}
+	}
+
+	prompt, ok := promptMessage(a.Messages, index)
+	if !ok || a.ReadOnly() {
+		return actions
+	}
+	text := app.MessageText(prompt)
+	actions = append(actions,
+		messageAction{
+			label: "Re-run prompt",
+			run: func() tea.Cmd {
+				if a.IsBusy() {
+					return toast.NewWarningToast("Wait for the agent to finish before re-running")
+				}
+				return util.CmdHandler(app.SendMsg{Text: text, Attachments: app.PromptAttachments(prompt)})
+			},
+		},
+		messageAction{
+			label: "Edit and resend prompt",
+			run: func() tea.Cmd {
+				return util.CmdHandler(app.EditPrompt(prompt))
+			},
+		},
+	)
+	return actions
+}
+
+// NewMessageActionsDialog creates a menu of actions for the message at index,
+// where parts are the parts of the selected block
+func NewMessageActionsDialog(a *app.App, index int, parts []opencode.PartUnion) MessageActionsDialog {
+	listComponent := list.NewListComponent(
+		list.WithItems(messageActions(a, index, parts)),
+		list.WithMaxVisibleHeight[messageAction](10),
+		list.WithFallbackMessage[messageAction]("No actions available"),
+		list.WithAlphaNumericKeys[messageAction](true),
+		list.WithRenderFunc(
+			func(item messageAction, selected bool, width int, baseStyle styles.Style) string {
+				t := theme.CurrentTheme()
+				label := truncate.StringWithTail(item.label, uint(max(width-1, 1)), "...")
+				if selected {
+					return baseStyle.
+						Background(t.Primary()).
+						Foreground(t.BackgroundElement()).
+						Width(width).
+						PaddingLeft(1).
+						Render(label)
+				}
+				return baseStyle.PaddingLeft(1).Render(label)
+			},
+		),
+		list.WithSelectableFunc(func(item messageAction) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &messageActionsDialog{
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Message Actions"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
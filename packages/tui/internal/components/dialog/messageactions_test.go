@@ -0,0 +1,52 @@
+package dialog
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+func TestMessageActions(t *testing.T) {
+	edit := opencode.ToolPart{
+		Tool: "edit",
+		State: opencode.ToolPartState{
+			Title:    "main.go",
+			Input:    map[string]any{"filePath": "main.go"},
+			Metadata: map[string]any{"diff": "@@ -1 +1 @@\n-old\n+new\n"},
+		},
+	}
+	a := &app.App{Messages: []app.Message{
+		{
+			Info:  opencode.UserMessage{ID: "msg_1"},
+			Parts: []opencode.PartUnion{opencode.TextPart{Text: "fix main"}},
+		},
+		{
+			Info:  opencode.AssistantMessage{ID: "msg_2"},
+			Parts: []opencode.PartUnion{opencode.TextPart{Text: "done"}, edit},
+		},
+	}}
+
+	var labels []string
+	for _, action := range messageActions(a, 1, []opencode.PartUnion{edit}) {
+		labels = append(labels, action.label)
+	}
+	want := []string{
+		"Copy text",
+		"Copy as Markdown",
+		"Open diff of main.go",
+		"Re-run prompt",
+		"Edit and resend prompt",
+	}
+	if !slices.Equal(labels, want) {
+		t.Errorf("messageActions() = %q, want %q", labels, want)
+	}
+
+	a.ImportedFrom = "transcript.json"
+	for _, action := range messageActions(a, 1, nil) {
+		if action.label == "Re-run prompt" {
+			t.Error("imported transcripts cannot be re-run")
+		}
+	}
+}
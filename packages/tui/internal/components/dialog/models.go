@@ -454,3 +454,34 @@ func NewModelDialog(app *app.App) ModelDialog {
 
 	return dialog
 }
+
+// modelChoices collects the models offered by the retry and compare dialogs,
+// each once, in the order they are added
+type modelChoices []ModelWithProvider
+
+func (c *modelChoices) add(provider *opencode.Provider, model *opencode.Model) {
+	if provider == nil || model == nil {
+		return
+	}
+	for _, existing := range *c {
+		if existing.Provider.ID == provider.ID && existing.Model.ID == model.ID {
+			return
+		}
+	}
+	*c = append(*c, ModelWithProvider{Provider: *provider, Model: *model})
+}
+
+// addRecent adds the current model followed by the most recently used ones,
+// until maxRecentModels are offered besides the first
+func (c *modelChoices) addRecent(a *app.App) {
+	c.add(a.Provider, a.Model)
+	if a.State == nil {
+		return
+	}
+	for _, usage := range a.State.RecentlyUsedModels {
+		if len(*c) >= maxRecentModels+1 {
+			break
+		}
+		c.add(a.FindModel(usage.ProviderID, usage.ModelID))
+	}
+}
@@ -0,0 +1,172 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// PermissionDialog interface for the permission request dialog
+type PermissionDialog interface {
+	layout.Modal
+	// isPermissionDialog tells this dialog apart from other modals
+	isPermissionDialog()
+}
+
+type permissionOption struct {
+	label    string
+	key      string
+	response opencode.PermissionRespondParamsResponse
+}
+
+var permissionOptions = []permissionOption{
+	{label: "Allow once", key: "y", response: opencode.PermissionRespondParamsResponseOnce},
+	{label: "Always allow", key: "a", response: opencode.PermissionRespondParamsResponseAlways},
+	{label: "Reject", key: "n", response: opencode.PermissionRespondParamsResponseReject},
+}
+
+type permissionDialog struct {
+	width    int
+	height   int
+	app      *app.App
+	modal    *modal.Modal
+	selected int
+}
+
+func (p *permissionDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (p *permissionDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width = msg.Width
+		p.height = msg.Height
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "tab", "right", "l":
+			p.selected = (p.selected + 1) % len(permissionOptions)
+		case "shift+tab", "left", "h":
+			p.selected = (p.selected + len(permissionOptions) - 1) % len(permissionOptions)
+		case "enter":
+			return p, p.respond(permissionOptions[p.selected].response)
+		default:
+			for _, option := range permissionOptions {
+				if msg.String() == option.key {
+					return p, p.respond(option.response)
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+func (p *permissionDialog) respond(response opencode.PermissionRespondParamsResponse) tea.Cmd {
+	if len(p.app.Permissions) == 0 {
+		return util.CmdHandler(modal.CloseModalMsg{})
+	}
+	permission := p.app.Permissions[0]
+	cmd := p.app.RespondToPermission(context.Background(), permission, response)
+	p.selected = 0
+	if len(p.app.Permissions) == 0 {
+		return tea.Sequence(util.CmdHandler(modal.CloseModalMsg{}), cmd)
+	}
+	return cmd
+}
+
+func (p *permissionDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	width := min(layout.Current.Container.Width-16, 72)
+	base := styles.NewStyle().Background(t.BackgroundPanel()).Foreground(t.Text())
+	muted := base.Foreground(t.TextMuted())
+
+	if len(p.app.Permissions) == 0 {
+		return p.modal.Render(muted.Render("No pending permissions"), background)
+	}
+
+	permission := p.app.Permissions[0]
+	lines := []string{
+		base.Bold(true).Width(width).Render(permission.Title),
+		"",
+	}
+
+	row := func(label, value string) string {
+		value = truncate.StringWithTail(value, uint(max(width-12, 1)), "...")
+		return muted.Width(10).Render(label) + base.Render(value)
+	}
+	lines = append(lines, row("Tool", permission.ID))
+	if pattern := permissionPattern(permission); pattern != "" {
+		lines = append(lines, row("Pattern", util.Relative(pattern)))
+	}
+	if permission.SessionID != p.app.Session.ID {
+		lines = append(lines, row("Session", permission.SessionID))
+	}
+	requested := time.UnixMilli(int64(permission.Time.Created)).Local().Format("03:04:05 PM")
+	lines = append(lines, row("Requested", requested))
+
+	buttons := []string{}
+	for i, option := range permissionOptions {
+		style := base.Foreground(t.Primary()).Padding(0, 2)
+		if i == p.selected {
+			style = style.Background(t.Primary()).Foreground(t.BackgroundPanel()).Bold(true)
+		}
+		buttons = append(buttons, style.Render(option.label))
+		if i < len(permissionOptions)-1 {
+			buttons = append(buttons, base.Render("  "))
+		}
+	}
+	lines = append(lines, "", lipgloss.JoinHorizontal(lipgloss.Center, buttons...), "")
+
+	help := []string{}
+	for _, option := range permissionOptions {
+		help = append(help, base.Render(option.key)+muted.Render(" "+strings.ToLower(option.label)))
+	}
+	footer := strings.Join(help, muted.Render("  "))
+	if pending := len(p.app.Permissions); pending > 1 {
+		footer += muted.Render(fmt.Sprintf("  (%d pending)", pending))
+	}
+	lines = append(lines, footer)
+
+	return p.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+func (p *permissionDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (p *permissionDialog) isPermissionDialog() {}
+
+// permissionPattern extracts what the permission applies to from the request
+// metadata, which differs per tool.
+func permissionPattern(permission app.Permission) string {
+	for _, key := range []string{"pattern", "filePath", "command", "url"} {
+		if value, ok := permission.Metadata[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// NewPermissionDialog creates a dialog that walks through the pending
+// permission requests queued on the app
+func NewPermissionDialog(app *app.App) PermissionDialog {
+	return &permissionDialog{
+		app: app,
+		modal: modal.New(
+			modal.WithTitle("Permission Required"),
+			modal.WithMaxWidth(76),
+		),
+	}
+}
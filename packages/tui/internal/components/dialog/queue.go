@@ -0,0 +1,219 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/config"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// QueueDialog interface for editing the prompts queued while the agent is
+// busy
+type QueueDialog interface {
+	layout.Modal
+	// isQueueDialog tells this dialog apart from other modals
+	isQueueDialog()
+}
+
+type queueAction int
+
+const (
+	queueActionNone queueAction = iota
+	queueActionKeep
+	queueActionDiscard
+)
+
+// queueRow is a queued prompt, or after an interrupt one of the choices of
+// what to do with the queue
+type queueRow struct {
+	action queueAction
+	index  int
+	prompt config.PromptEntry
+}
+
+func (r queueRow) label() string {
+	switch r.action {
+	case queueActionKeep:
+		return "Keep queued prompts, sending the next when idle"
+	case queueActionDiscard:
+		return "Discard queued prompts"
+	}
+	label := fmt.Sprintf("%d. %s", r.index+1, historyItemText(r.prompt.Text))
+	if n := len(r.prompt.Attachments); n > 0 {
+		label += fmt.Sprintf(" (+%d files)", n)
+	}
+	return label
+}
+
+type queueDialog struct {
+	width  int
+	height int
+	app    *app.App
+	modal  *modal.Modal
+	list   list.List[queueRow]
+	// interrupted is set when the dialog asks whether to keep the queue
+	// after an interrupt
+	interrupted bool
+}
+
+func (d *queueDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *queueDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case app.PromptQueuedMsg:
+		d.refresh()
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "enter":
+			row, idx := d.list.GetSelectedItem()
+			if idx < 0 {
+				return d, nil
+			}
+			switch row.action {
+			case queueActionKeep:
+				d.app.Queue().Paused = false
+				return d, util.CmdHandler(modal.CloseModalMsg{})
+			case queueActionDiscard:
+				d.app.ClearQueue()
+				return d, tea.Batch(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					toast.NewInfoToast("Queued prompts discarded"),
+				)
+			}
+			prompt, ok := d.app.RemoveQueued(row.index)
+			if !ok {
+				return d, nil
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				util.CmdHandler(PromptRecalledMsg{Entry: prompt}),
+			)
+		case "ctrl+x", "delete":
+			row, idx := d.list.GetSelectedItem()
+			if idx < 0 || row.action != queueActionNone {
+				return d, nil
+			}
+			d.app.RemoveQueued(row.index)
+			if len(d.app.QueuedPrompts()) == 0 {
+				return d, util.CmdHandler(modal.CloseModalMsg{})
+			}
+			d.refresh()
+			return d, nil
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[queueRow])
+	return d, cmd
+}
+
+func (d *queueDialog) refresh() {
+	_, idx := d.list.GetSelectedItem()
+	d.list.SetItems(queueRows(d.app.QueuedPrompts(), d.interrupted))
+	if idx >= 0 {
+		d.list.SetSelectedIndex(idx)
+	}
+}
+
+// queueRows lists the queued prompts, preceded after an interrupt by the
+// choices of keeping or discarding them
+func queueRows(prompts []config.PromptEntry, interrupted bool) []queueRow {
+	rows := []queueRow{}
+	if interrupted {
+		rows = append(rows, queueRow{action: queueActionKeep}, queueRow{action: queueActionDiscard})
+	}
+	for i, prompt := range prompts {
+		rows = append(rows, queueRow{index: i, prompt: prompt})
+	}
+	return rows
+}
+
+func (d *queueDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+
+	lines := []string{}
+	if d.interrupted {
+		count := len(d.app.QueuedPrompts())
+		lines = append(lines, styles.NewStyle().PaddingLeft(1).Render(
+			base.Foreground(t.Warning()).Render(fmt.Sprintf("Interrupted with %d prompt(s) still queued", count)),
+		), "")
+	}
+	help := base.Render("enter") + muted.Render(" edit  ") +
+		base.Render("ctrl+x") + muted.Render(" remove")
+	lines = append(lines, d.list.View(), styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(help))
+	return d.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+// Close resumes a queue paused by an interrupt when the dialog is dismissed
+// without a choice, keeping the prompts
+func (d *queueDialog) Close() tea.Cmd {
+	d.app.Queue().Paused = false
+	return d.app.SendQueued(d.app.Session.ID)
+}
+
+func (d *queueDialog) isQueueDialog() {}
+
+// NewQueueDialog creates a dialog listing the prompts queued in the current
+// session. After an interrupt it first asks whether to keep them.
+func NewQueueDialog(a *app.App, interrupted bool) QueueDialog {
+	listComponent := list.NewListComponent(
+		list.WithItems(queueRows(a.QueuedPrompts(), interrupted)),
+		list.WithMaxVisibleHeight[queueRow](10),
+		list.WithFallbackMessage[queueRow]("No prompts queued"),
+		list.WithAlphaNumericKeys[queueRow](false),
+		list.WithRenderFunc(
+			func(row queueRow, selected bool, width int, baseStyle styles.Style) string {
+				t := theme.CurrentTheme()
+				label := truncate.StringWithTail(row.label(), uint(max(width-1, 1)), "...")
+				if selected {
+					return baseStyle.
+						Background(t.Primary()).
+						Foreground(t.BackgroundElement()).
+						Width(width).
+						PaddingLeft(1).
+						Render(label)
+				}
+				if row.action == queueActionDiscard {
+					return baseStyle.Foreground(t.Error()).PaddingLeft(1).Render(label)
+				}
+				return baseStyle.PaddingLeft(1).Render(label)
+			},
+		),
+		list.WithSelectableFunc(func(row queueRow) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	title := "Queued Prompts"
+	if interrupted {
+		title = "Interrupted"
+	}
+	return &queueDialog{
+		app:         a,
+		list:        listComponent,
+		interrupted: interrupted,
+		modal: modal.New(
+			modal.WithTitle(title),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
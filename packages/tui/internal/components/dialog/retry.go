@@ -0,0 +1,163 @@
+package dialog
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/components/toast"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// RetryDialog interface for the actions on the error that ended the last
+// turn
+type RetryDialog interface {
+	layout.Modal
+	// isRetryDialog tells this dialog apart from other modals
+	isRetryDialog()
+}
+
+type retryDialog struct {
+	width  int
+	height int
+	app    *app.App
+	info   *app.ErrorInfo
+	modal  *modal.Modal
+	list   list.List[messageAction]
+}
+
+func (d *retryDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *retryDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		if msg.String() == "enter" {
+			item, idx := d.list.GetSelectedItem()
+			if idx < 0 {
+				return d, nil
+			}
+			return d, tea.Sequence(util.CmdHandler(modal.CloseModalMsg{}), item.run())
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[messageAction])
+	return d, cmd
+}
+
+func (d *retryDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	width := layout.Current.Container.Width - 12
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	pad := styles.NewStyle().PaddingLeft(1).Render
+
+	lines := []string{pad(base.Foreground(t.Error()).Bold(true).Render(d.info.Title))}
+	if d.info.Message != "" {
+		for line := range strings.SplitSeq(wordwrap.String(d.info.Message, max(width-2, 1)), "\n") {
+			lines = append(lines, pad(base.Render(line)))
+		}
+	}
+	lines = append(lines, "", d.list.View())
+	return d.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+func (d *retryDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *retryDialog) isRetryDialog() {}
+
+// retryModels lists the models to retry with: the one that failed, the
+// current one and the most recently used ones, without duplicates
+func retryModels(a *app.App) []app.RetryMsg {
+	choices := modelChoices{}
+	choices.add(a.FailedModel())
+	choices.addRecent(a)
+	models := []app.RetryMsg{}
+	for _, choice := range choices {
+		models = append(models, app.RetryMsg{Provider: &choice.Provider, Model: &choice.Model})
+	}
+	return models
+}
+
+// retryActions lists the models to retry with, followed by the ways to copy
+// the error
+func retryActions(a *app.App, info *app.ErrorInfo) []messageAction {
+	actions := []messageAction{}
+	_, failed := a.FailedModel()
+	for i, retry := range retryModels(a) {
+		label := "Retry with " + retry.Provider.Name + " " + retry.Model.Name
+		if i == 0 && failed != nil {
+			label += " (same model)"
+		}
+		actions = append(actions, messageAction{
+			label: label,
+			run: func() tea.Cmd {
+				return util.CmdHandler(retry)
+			},
+		})
+	}
+	copyText := func(text, confirmation string) func() tea.Cmd {
+		return func() tea.Cmd {
+			return tea.Batch(a.SetClipboard(text), toast.NewSuccessToast(confirmation))
+		}
+	}
+	actions = append(actions,
+		messageAction{label: "Copy error message", run: copyText(info.String(), "Error copied to clipboard")},
+		messageAction{label: "Copy raw error", run: copyText(info.Raw, "Raw error copied to clipboard")},
+	)
+	return actions
+}
+
+// NewRetryDialog creates a menu to retry the last prompt after info ended
+// it, with the same or another model, or to copy the error
+func NewRetryDialog(a *app.App, info *app.ErrorInfo) RetryDialog {
+	listComponent := list.NewListComponent(
+		list.WithItems(retryActions(a, info)),
+		list.WithMaxVisibleHeight[messageAction](10),
+		list.WithFallbackMessage[messageAction]("No actions available"),
+		list.WithAlphaNumericKeys[messageAction](true),
+		list.WithRenderFunc(
+			func(item messageAction, selected bool, width int, baseStyle styles.Style) string {
+				t := theme.CurrentTheme()
+				label := truncate.StringWithTail(item.label, uint(max(width-1, 1)), "...")
+				if selected {
+					return baseStyle.
+						Background(t.Primary()).
+						Foreground(t.BackgroundElement()).
+						Width(width).
+						PaddingLeft(1).
+						Render(label)
+				}
+				return baseStyle.PaddingLeft(1).Render(label)
+			},
+		),
+		list.WithSelectableFunc(func(item messageAction) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	return &retryDialog{
+		app:  a,
+		info: info,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Session Error"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
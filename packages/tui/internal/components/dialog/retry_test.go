@@ -0,0 +1,48 @@
+package dialog
+
+import (
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/config"
+)
+
+func TestRetryModels(t *testing.T) {
+	provider := opencode.Provider{ID: "p", Name: "P", Models: map[string]opencode.Model{
+		"a": {ID: "a", Name: "A"},
+		"b": {ID: "b", Name: "B"},
+		"c": {ID: "c", Name: "C"},
+	}}
+	a := &app.App{
+		Providers: []opencode.Provider{provider},
+		Provider:  &provider,
+		Model:     &opencode.Model{ID: "b", Name: "B"},
+		State: &config.State{RecentlyUsedModels: []config.ModelUsage{
+			{ProviderID: "p", ModelID: "b"},
+			{ProviderID: "gone", ModelID: "x"},
+			{ProviderID: "p", ModelID: "c"},
+		}},
+		Messages: []app.Message{
+			{Info: opencode.AssistantMessage{ProviderID: "p", ModelID: "a"}},
+		},
+	}
+
+	got := []string{}
+	for _, retry := range retryModels(a) {
+		got = append(got, retry.Model.ID)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("expected the failed model first, then the current and recent ones once, got %v", got)
+	}
+
+	actions := retryActions(a, &app.ErrorInfo{Title: "Error", Raw: "{}"})
+	if len(actions) != 5 || actions[0].label != "Retry with P A (same model)" || actions[4].label != "Copy raw error" {
+		t.Errorf("unexpected actions %+v", actions)
+	}
+
+	a.Messages = nil
+	if actions := retryActions(a, &app.ErrorInfo{Title: "Error"}); actions[0].label != "Retry with P B" {
+		t.Errorf("expected no failed model to be labelled without one, got %q", actions[0].label)
+	}
+}
@@ -0,0 +1,195 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+const maxRevertPreviewFiles = 8
+
+// RevertDialog interface for the message revert dialog
+type RevertDialog interface {
+	layout.Modal
+}
+
+type revertItem struct {
+	index int
+	text  string
+}
+
+type revertDialog struct {
+	width      int
+	height     int
+	app        *app.App
+	modal      *modal.Modal
+	list       list.List[revertItem]
+	confirming *revertItem
+	preview    app.RevertPreview
+}
+
+func (r *revertDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (r *revertDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		r.width = msg.Width
+		r.height = msg.Height
+		r.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case tea.KeyPressMsg:
+		if r.confirming != nil {
+			switch msg.String() {
+			case "enter", "y":
+				if r.confirming.index >= len(r.app.Messages) {
+					return r, util.CmdHandler(modal.CloseModalMsg{})
+				}
+				messageID := r.app.Messages[r.confirming.index].ID()
+				return r, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					r.app.RevertMessage(context.Background(), messageID),
+				)
+			case "n", "backspace":
+				r.confirming = nil
+			}
+			return r, nil
+		}
+		switch msg.String() {
+		case "enter":
+			if item, idx := r.list.GetSelectedItem(); idx >= 0 {
+				r.confirming = &item
+				r.preview = r.app.PreviewRevert(item.index)
+			}
+			return r, nil
+		case "u":
+			if r.app.Session.Revert.MessageID != "" {
+				return r, tea.Sequence(
+					util.CmdHandler(modal.CloseModalMsg{}),
+					r.app.UnrevertSession(context.Background()),
+				)
+			}
+		}
+	}
+
+	listModel, cmd := r.list.Update(msg)
+	r.list = listModel.(list.List[revertItem])
+	return r, cmd
+}
+
+func (r *revertDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	width := layout.Current.Container.Width - 14
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+	keyStyle := base.Render
+
+	if r.confirming == nil {
+		help := keyStyle("enter") + muted.Render(" preview revert")
+		if r.app.Session.Revert.MessageID != "" {
+			help += muted.Render("  ") + keyStyle("u") + muted.Render(" undo last revert")
+		}
+		help = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(help)
+		return r.modal.Render(r.list.View()+"\n"+help, background)
+	}
+
+	lines := []string{
+		muted.Render("Revert to before:"),
+		base.Bold(true).Render(truncate.StringWithTail(r.confirming.text, uint(width), "...")),
+		"",
+		muted.Render(fmt.Sprintf("%d message(s) will be removed", r.preview.Messages)),
+	}
+	if r.preview.Snapshot == "" {
+		lines = append(lines, muted.Render("No snapshot was recorded, files will not be restored"))
+	} else {
+		snapshot := r.preview.Snapshot[:min(len(r.preview.Snapshot), 8)]
+		lines = append(lines, muted.Render("Files restored from snapshot ")+base.Render(snapshot))
+		for i, file := range r.preview.Files {
+			if i == maxRevertPreviewFiles {
+				remaining := len(r.preview.Files) - maxRevertPreviewFiles
+				lines = append(lines, muted.Render(fmt.Sprintf("  … and %d more", remaining)))
+				break
+			}
+			lines = append(lines, base.Foreground(t.Warning()).Render("  "+util.Relative(file)))
+		}
+	}
+	lines = append(lines,
+		"",
+		keyStyle("enter")+muted.Render(" confirm  ")+keyStyle("backspace")+muted.Render(" back"),
+	)
+	content := styles.NewStyle().PaddingLeft(1).Render(strings.Join(lines, "\n"))
+	return r.modal.Render(content, background)
+}
+
+func (r *revertDialog) Close() tea.Cmd {
+	return nil
+}
+
+// NewRevertDialog creates a dialog for reverting the current session to
+// before one of its prompts, defaulting to the most recent one
+func NewRevertDialog(app *app.App) RevertDialog {
+	var items []revertItem
+	for i, message := range app.Messages {
+		if _, ok := message.Info.(opencode.UserMessage); !ok {
+			continue
+		}
+		text := ""
+		for _, part := range message.Parts {
+			if textPart, ok := part.(opencode.TextPart); ok && !textPart.Synthetic {
+				text = textPart.Text
+				break
+			}
+		}
+		text = strings.Join(strings.Fields(text), " ")
+		items = append(items, revertItem{index: i, text: text})
+	}
+
+	listComponent := list.NewListComponent(
+		list.WithItems(items),
+		list.WithMaxVisibleHeight[revertItem](10),
+		list.WithFallbackMessage[revertItem]("No messages to revert"),
+		list.WithAlphaNumericKeys[revertItem](true),
+		list.WithRenderFunc(
+			func(item revertItem, selected bool, width int, baseStyle styles.Style) string {
+				t := theme.CurrentTheme()
+				text := truncate.StringWithTail(item.text, uint(max(width-1, 1)), "...")
+				if selected {
+					return baseStyle.
+						Background(t.Primary()).
+						Foreground(t.BackgroundElement()).
+						Width(width).
+						PaddingLeft(1).
+						Render(text)
+				}
+				return baseStyle.PaddingLeft(1).Render(text)
+			},
+		),
+		list.WithSelectableFunc(func(item revertItem) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+	if len(items) > 0 {
+		listComponent.SetSelectedIndex(len(items) - 1)
+	}
+
+	return &revertDialog{
+		app:  app,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Revert Message"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+}
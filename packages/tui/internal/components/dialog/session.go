@@ -2,11 +2,14 @@ package dialog
 
 import (
 	"context"
-	"strings"
-
+	"fmt"
 	"slices"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
@@ -19,14 +22,66 @@ import (
 	"github.com/sst/opencode/internal/util"
 )
 
+const numVisibleSessions = 12
+
 // SessionDialog interface for the session switching dialog
 type SessionDialog interface {
 	layout.Modal
 }
 
+// sessionSort orders the top level sessions, subagent sessions always follow
+// their parent
+type sessionSort int
+
+const (
+	sessionSortCreated sessionSort = iota
+	sessionSortUpdated
+	sessionSortCost
+)
+
+func (s sessionSort) String() string {
+	switch s {
+	case sessionSortUpdated:
+		return "updated"
+	case sessionSortCost:
+		return "cost"
+	}
+	return "created"
+}
+
+type sessionFilter int
+
+const (
+	sessionFilterAll sessionFilter = iota
+	sessionFilterShared
+	sessionFilterReverted
+)
+
+func (f sessionFilter) String() string {
+	switch f {
+	case sessionFilterShared:
+		return "shared"
+	case sessionFilterReverted:
+		return "reverted"
+	}
+	return "all"
+}
+
+func (f sessionFilter) matches(session opencode.Session) bool {
+	switch f {
+	case sessionFilterShared:
+		return session.Share.URL != ""
+	case sessionFilterReverted:
+		return session.Revert.MessageID != ""
+	}
+	return true
+}
+
 // sessionItem is a custom list item for sessions that can show delete confirmation
 type sessionItem struct {
-	title              string
+	session            opencode.Session
+	summary            *app.SessionSummary // nil until the session has been indexed
+	depth              int                 // nests subagent sessions under the session that started them
 	isDeleteConfirming bool
 	isCurrentSession   bool
 }
@@ -34,23 +89,30 @@ type sessionItem struct {
 func (s sessionItem) Render(
 	selected bool,
 	width int,
-	isFirstInViewport bool,
 	baseStyle styles.Style,
 ) string {
 	t := theme.CurrentTheme()
 
+	indent := ""
+	if s.depth > 0 {
+		indent = strings.Repeat("  ", s.depth-1) + "∟ "
+	}
+
 	var text string
 	if s.isDeleteConfirming {
 		text = "Press again to confirm delete"
 	} else {
 		if s.isCurrentSession {
-			text = "● " + s.title
+			text = "● " + s.session.Title
 		} else {
-			text = s.title
+			text = s.session.Title
 		}
 	}
 
-	truncatedStr := truncate.StringWithTail(text, uint(width-1), "...")
+	columns := s.columns(time.Now())
+	titleWidth := max(width-lipgloss.Width(columns)-2, 1)
+	title := truncate.StringWithTail(indent+text, uint(titleWidth), "...")
+	title += strings.Repeat(" ", max(titleWidth-lipgloss.Width(title), 0))
 
 	var itemStyle styles.Style
 	if selected {
@@ -77,43 +139,81 @@ func (s sessionItem) Render(
 				Width(width).
 				PaddingLeft(1)
 		}
-	} else {
-		if s.isDeleteConfirming {
-			// Red text for delete confirmation when not selected
-			itemStyle = baseStyle.
-				Foreground(t.Error()).
-				PaddingLeft(1)
-		} else if s.isCurrentSession {
-			// Highlight current session when not selected
-			itemStyle = baseStyle.
-				Foreground(t.Primary()).
-				PaddingLeft(1).
-				Bold(true)
-		} else {
-			itemStyle = baseStyle.
-				PaddingLeft(1)
-		}
+		return itemStyle.Render(title + " " + columns)
 	}
 
-	return itemStyle.Render(truncatedStr)
+	if s.isDeleteConfirming {
+		// Red text for delete confirmation when not selected
+		itemStyle = baseStyle.
+			Foreground(t.Error()).
+			PaddingLeft(1)
+	} else if s.isCurrentSession {
+		// Highlight current session when not selected
+		itemStyle = baseStyle.
+			Foreground(t.Primary()).
+			PaddingLeft(1).
+			Bold(true)
+	} else {
+		itemStyle = baseStyle.
+			PaddingLeft(1)
+	}
+	return itemStyle.Render(title) +
+		baseStyle.Foreground(t.TextMuted()).Render(" "+columns)
 }
 
 func (s sessionItem) Selectable() bool {
 	return true
 }
 
+// columns renders the last updated time, message count and cost, padded to
+// fixed widths so they line up across rows
+func (s sessionItem) columns(now time.Time) string {
+	updated := formatAge(now.Sub(time.UnixMilli(int64(s.session.Time.Updated))))
+	messages, cost := "…", "…"
+	if s.summary != nil {
+		messages = fmt.Sprintf("%d msgs", s.summary.Messages)
+		cost = fmt.Sprintf("$%.2f", s.summary.Cost)
+	}
+	return fmt.Sprintf("%8s %9s %7s", updated, messages, cost)
+}
+
+// formatAge renders a duration as a short "time ago" value
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+	return fmt.Sprintf("%dmo ago", int(age.Hours()/24/30))
+}
+
 type sessionDialog struct {
-	width              int
-	height             int
-	modal              *modal.Modal
-	sessions           []opencode.Session
-	list               list.List[sessionItem]
-	app                *app.App
-	deleteConfirmation int // -1 means no confirmation, >= 0 means confirming deletion of session at this index
+	width        int
+	height       int
+	modal        *modal.Modal
+	sessions     []opencode.Session
+	searchDialog *SearchDialog
+	app          *app.App
+	sort         sessionSort
+	filter       sessionFilter
+	// deleteConfirmation is the session waiting for a second delete press
+	deleteConfirmation string
+	// renaming is the session whose title is being edited in the search
+	// input, query holds the search to restore afterwards
+	renaming string
+	query    string
 }
 
 func (s *sessionDialog) Init() tea.Cmd {
-	return nil
+	return tea.Batch(
+		s.searchDialog.Init(),
+		s.app.IndexSessions(context.Background(), s.app.SessionIndex.Stale(s.sessions)),
+	)
 }
 
 func (s *sessionDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -121,74 +221,269 @@ func (s *sessionDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		s.width = msg.Width
 		s.height = msg.Height
-		s.list.SetMaxWidth(layout.Current.Container.Width - 12)
-	case tea.KeyPressMsg:
-		switch msg.String() {
-		case "enter":
-			if s.deleteConfirmation >= 0 {
-				s.deleteConfirmation = -1
-				s.updateListItems()
-				return s, nil
-			}
-			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.sessions) {
-				selectedSession := s.sessions[idx]
-				return s, tea.Sequence(
-					util.CmdHandler(modal.CloseModalMsg{}),
-					util.CmdHandler(app.SessionSelectedMsg(&selectedSession)),
-				)
+		s.searchDialog.SetWidth(layout.Current.Container.Width - 12)
+	case app.SessionsIndexedMsg:
+		s.refresh()
+		return s, s.app.IndexSessions(context.Background(), msg.Remaining)
+	case opencode.EventListResponseEventSessionUpdated:
+		index := slices.IndexFunc(s.sessions, func(session opencode.Session) bool {
+			return session.ID == msg.Properties.Info.ID
+		})
+		if index >= 0 {
+			s.sessions[index] = msg.Properties.Info
+			if s.renaming == "" {
+				s.refresh()
 			}
-		case "n":
-			s.app.Session = &opencode.Session{}
-			s.app.Messages = []app.Message{}
+		}
+	case SearchQueryChangedMsg:
+		if s.renaming != "" {
+			return s, nil
+		}
+		s.deleteConfirmation = ""
+		s.refresh()
+		return s, nil
+	case SearchSelectionMsg:
+		if s.renaming != "" {
+			return s, s.rename()
+		}
+		if s.deleteConfirmation != "" {
+			s.deleteConfirmation = ""
+			s.refresh()
+			return s, nil
+		}
+		if item, ok := msg.Item.(sessionItem); ok {
+			selectedSession := item.session
 			return s, tea.Sequence(
 				util.CmdHandler(modal.CloseModalMsg{}),
-				util.CmdHandler(app.SessionClearedMsg{}),
+				util.CmdHandler(app.SessionSelectedMsg(&selectedSession)),
 			)
-		case "x", "delete", "backspace":
-			if _, idx := s.list.GetSelectedItem(); idx >= 0 && idx < len(s.sessions) {
-				if s.deleteConfirmation == idx {
-					// Second press - actually delete the session
-					sessionToDelete := s.sessions[idx]
-					return s, tea.Sequence(
-						func() tea.Msg {
-							s.sessions = slices.Delete(s.sessions, idx, idx+1)
-							s.deleteConfirmation = -1
-							s.updateListItems()
-							return nil
-						},
-						s.deleteSession(sessionToDelete.ID),
-					)
-				} else {
-					// First press - enter delete confirmation mode
-					s.deleteConfirmation = idx
-					s.updateListItems()
+		}
+	case SearchCancelledMsg:
+		if s.deleteConfirmation != "" {
+			s.deleteConfirmation = ""
+			s.refresh()
+			return s, nil
+		}
+		return s, util.CmdHandler(modal.CloseModalMsg{})
+	case SearchRemoveItemMsg:
+		item, ok := msg.Item.(sessionItem)
+		if !ok {
+			return s, nil
+		}
+		if s.deleteConfirmation != item.session.ID {
+			// First press - enter delete confirmation mode
+			s.deleteConfirmation = item.session.ID
+			s.refresh()
+			return s, nil
+		}
+		// Second press - actually delete the session, the server deletes
+		// subagent sessions along with their parent
+		s.deleteConfirmation = ""
+		s.sessions = slices.DeleteFunc(s.sessions, func(session opencode.Session) bool {
+			return session.ID == item.session.ID || s.descendsFrom(session, item.session.ID)
+		})
+		s.refresh()
+		return s, s.deleteSession(item.session.ID)
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "ctrl+o":
+			if item, idx := s.searchDialog.list.GetSelectedItem(); idx >= 0 && s.renaming == "" {
+				if item, ok := item.(sessionItem); ok {
+					s.renaming = item.session.ID
+					s.query = s.searchDialog.GetQuery()
+					s.searchDialog.SetQuery(item.session.Title)
 					return s, nil
 				}
 			}
-		case "esc":
-			if s.deleteConfirmation >= 0 {
-				s.deleteConfirmation = -1
-				s.updateListItems()
-				return s, nil
-			}
+		case "ctrl+s":
+			s.sort = (s.sort + 1) % 3
+			s.refresh()
+			return s, nil
+		case "ctrl+f":
+			s.filter = (s.filter + 1) % 3
+			s.refresh()
+			return s, nil
 		}
 	}
 
-	var cmd tea.Cmd
-	listModel, cmd := s.list.Update(msg)
-	s.list = listModel.(list.List[sessionItem])
+	updatedDialog, cmd := s.searchDialog.Update(msg)
+	s.searchDialog = updatedDialog.(*SearchDialog)
 	return s, cmd
 }
 
-func (s *sessionDialog) Render(background string) string {
-	listView := s.list.View()
+// rename saves the title typed for the session being renamed and restores
+// the search it replaced
+func (s *sessionDialog) rename() tea.Cmd {
+	sessionID, title := s.renaming, s.searchDialog.GetQuery()
+	s.renaming = ""
+	s.searchDialog.SetQuery(s.query)
+	s.refresh()
+	return func() tea.Msg {
+		if _, err := s.app.RenameSession(context.Background(), sessionID, title); err != nil {
+			return toast.NewErrorToast("Failed to rename session: " + err.Error())()
+		}
+		return nil
+	}
+}
+
+func (s *sessionDialog) descendsFrom(session opencode.Session, ancestorID string) bool {
+	for session.ParentID != "" {
+		if session.ParentID == ancestorID {
+			return true
+		}
+		index := slices.IndexFunc(s.sessions, func(candidate opencode.Session) bool {
+			return candidate.ID == session.ParentID
+		})
+		if index < 0 {
+			return false
+		}
+		session = s.sessions[index]
+	}
+	return false
+}
 
+// refresh rebuilds the list for the current query, filter and sort order
+func (s *sessionDialog) refresh() {
+	items := []list.Item{}
+	for _, item := range s.items(s.searchDialog.GetQuery()) {
+		items = append(items, item)
+	}
+	s.searchDialog.SetItems(items)
+}
+
+// items lists the matching sessions. Without a query or filter sessions are
+// shown as a tree, otherwise as a flat list ranked by relevance.
+func (s *sessionDialog) items(query string) []sessionItem {
+	summaries := map[string]*app.SessionSummary{}
+	for _, session := range s.sessions {
+		if summary, ok := s.app.SessionIndex.Get(session.ID); ok {
+			summaries[session.ID] = &summary
+		}
+	}
+	item := func(session opencode.Session, depth int) sessionItem {
+		return sessionItem{
+			session:            session,
+			summary:            summaries[session.ID],
+			depth:              depth,
+			isDeleteConfirming: s.deleteConfirmation == session.ID,
+			isCurrentSession:   s.app.Session != nil && s.app.Session.ID == session.ID,
+		}
+	}
+
+	sessions := slices.Clone(s.sessions)
+	slices.SortStableFunc(sessions, func(a, b opencode.Session) int {
+		return compareSessions(s.sort, a, b, summaries)
+	})
+
+	var items []sessionItem
+	if query == "" && s.filter == sessionFilterAll {
+		for _, node := range app.SessionTree(sessions) {
+			items = append(items, item(node.Session, node.Depth))
+		}
+		return items
+	}
+
+	var filtered []opencode.Session
+	for _, session := range sessions {
+		if s.filter.matches(session) {
+			filtered = append(filtered, session)
+		}
+	}
+	for _, session := range searchSessions(query, filtered, summaries) {
+		items = append(items, item(session, 0))
+	}
+	return items
+}
+
+// compareSessions orders sessions newest, or most expensive, first
+func compareSessions(
+	order sessionSort,
+	a, b opencode.Session,
+	summaries map[string]*app.SessionSummary,
+) int {
+	var x, y float64
+	switch order {
+	case sessionSortCreated:
+		x, y = a.Time.Created, b.Time.Created
+	case sessionSortUpdated:
+		x, y = a.Time.Updated, b.Time.Updated
+	case sessionSortCost:
+		if summary := summaries[a.ID]; summary != nil {
+			x = summary.Cost
+		}
+		if summary := summaries[b.ID]; summary != nil {
+			y = summary.Cost
+		}
+	}
+	switch {
+	case x > y:
+		return -1
+	case x < y:
+		return 1
+	}
+	return 0
+}
+
+// searchSessions ranks fuzzy title matches first, followed by sessions whose
+// messages contain every word of the query. Sessions keep their given order
+// when the query is empty.
+func searchSessions(
+	query string,
+	sessions []opencode.Session,
+	summaries map[string]*app.SessionSummary,
+) []opencode.Session {
+	if query == "" {
+		return sessions
+	}
+
+	titles := make([]string, len(sessions))
+	for i, session := range sessions {
+		titles[i] = session.Title
+	}
+	matches := fuzzy.RankFindFold(query, titles)
+	slices.SortStableFunc(matches, func(a, b fuzzy.Rank) int {
+		if a.Distance != b.Distance {
+			return a.Distance - b.Distance
+		}
+		return a.OriginalIndex - b.OriginalIndex
+	})
+
+	var results []opencode.Session
+	matched := map[string]bool{}
+	for _, match := range matches {
+		session := sessions[match.OriginalIndex]
+		results = append(results, session)
+		matched[session.ID] = true
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	for _, session := range sessions {
+		summary := summaries[session.ID]
+		if matched[session.ID] || summary == nil {
+			continue
+		}
+		if !slices.ContainsFunc(words, func(word string) bool {
+			return !strings.Contains(summary.Text, word)
+		}) {
+			results = append(results, session)
+		}
+	}
+	return results
+}
+
+func (s *sessionDialog) Render(background string) string {
 	t := theme.CurrentTheme()
 	keyStyle := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel()).Render
 	mutedStyle := styles.NewStyle().Foreground(t.TextMuted()).Background(t.BackgroundPanel()).Render
 
-	leftHelp := keyStyle("n") + mutedStyle(" new session")
-	rightHelp := keyStyle("x/del") + mutedStyle(" delete session")
+	leftHelp := keyStyle("ctrl+s") + mutedStyle(" sort: "+s.sort.String()+"  ") +
+		keyStyle("ctrl+f") + mutedStyle(" filter: "+s.filter.String())
+	rightHelp := keyStyle("ctrl+o") + mutedStyle(" rename  ") +
+		keyStyle("ctrl+x") + mutedStyle(" delete")
+	if s.renaming != "" {
+		leftHelp = mutedStyle("editing the session title")
+		rightHelp = keyStyle("enter") + mutedStyle(" save")
+	}
 
 	bgColor := t.BackgroundPanel()
 	helpText := layout.Render(layout.FlexOptions{
@@ -200,27 +495,11 @@ func (s *sessionDialog) Render(background string) string {
 
 	helpText = styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(helpText)
 
-	content := strings.Join([]string{listView, helpText}, "\n")
+	content := strings.Join([]string{s.searchDialog.View(), helpText}, "\n")
 
 	return s.modal.Render(content, background)
 }
 
-func (s *sessionDialog) updateListItems() {
-	_, currentIdx := s.list.GetSelectedItem()
-
-	var items []sessionItem
-	for i, sess := range s.sessions {
-		item := sessionItem{
-			title:              sess.Title,
-			isDeleteConfirming: s.deleteConfirmation == i,
-			isCurrentSession:   s.app.Session != nil && s.app.Session.ID == sess.ID,
-		}
-		items = append(items, item)
-	}
-	s.list.SetItems(items)
-	s.list.SetSelectedIndex(currentIdx)
-}
-
 func (s *sessionDialog) deleteSession(sessionID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
@@ -239,44 +518,18 @@ func (s *sessionDialog) Close() tea.Cmd {
 func NewSessionDialog(app *app.App) SessionDialog {
 	sessions, _ := app.ListSessions(context.Background())
 
-	var filteredSessions []opencode.Session
-	var items []sessionItem
-	for _, sess := range sessions {
-		if sess.ParentID != "" {
-			continue
-		}
-		filteredSessions = append(filteredSessions, sess)
-		items = append(items, sessionItem{
-			title:              sess.Title,
-			isDeleteConfirming: false,
-			isCurrentSession:   app.Session != nil && app.Session.ID == sess.ID,
-		})
-	}
-
-	listComponent := list.NewListComponent(
-		list.WithItems(items),
-		list.WithMaxVisibleHeight[sessionItem](10),
-		list.WithFallbackMessage[sessionItem]("No sessions available"),
-		list.WithAlphaNumericKeys[sessionItem](true),
-		list.WithRenderFunc(
-			func(item sessionItem, selected bool, width int, baseStyle styles.Style) string {
-				return item.Render(selected, width, false, baseStyle)
-			},
-		),
-		list.WithSelectableFunc(func(item sessionItem) bool {
-			return true
-		}),
-	)
-	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+	searchDialog := NewSearchDialog("Search sessions...", numVisibleSessions)
+	searchDialog.SetWidth(layout.Current.Container.Width - 12)
 
-	return &sessionDialog{
-		sessions:           filteredSessions,
-		list:               listComponent,
-		app:                app,
-		deleteConfirmation: -1,
+	dialog := &sessionDialog{
+		sessions:     sessions,
+		searchDialog: searchDialog,
+		app:          app,
 		modal: modal.New(
 			modal.WithTitle("Switch Session"),
 			modal.WithMaxWidth(layout.Current.Container.Width-8),
 		),
 	}
+	dialog.refresh()
+	return dialog
 }
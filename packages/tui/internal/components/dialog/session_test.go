@@ -0,0 +1,107 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+func testSessionDialog() *sessionDialog {
+	a := &app.App{Session: &opencode.Session{}, SessionIndex: app.NewSessionIndex()}
+	session := func(id, title, parent string, created, updated float64) opencode.Session {
+		return opencode.Session{
+			ID:       id,
+			Title:    title,
+			ParentID: parent,
+			Time:     opencode.SessionTime{Created: created, Updated: updated},
+		}
+	}
+	sessions := []opencode.Session{
+		session("ses_1", "Fix login redirect", "", 3, 4),
+		session("ses_2", "Refactor parser", "", 2, 9),
+		session("ses_3", "Explore tests", "ses_2", 5, 5),
+		session("ses_4", "Write docs", "", 1, 1),
+	}
+	sessions[0].Share.URL = "https://example.com/s/1"
+	sessions[3].Revert.MessageID = "msg_1"
+	return &sessionDialog{app: a, sessions: sessions}
+}
+
+func ids(items []sessionItem) []string {
+	var result []string
+	for _, item := range items {
+		result = append(result, item.session.ID)
+	}
+	return result
+}
+
+func assertIDs(t *testing.T, got []sessionItem, want ...string) {
+	t.Helper()
+	gotIDs := ids(got)
+	if len(gotIDs) != len(want) {
+		t.Fatalf("expected %v, got %v", want, gotIDs)
+	}
+	for i := range want {
+		if gotIDs[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, gotIDs)
+		}
+	}
+}
+
+func TestSessionDialogSortAndFilter(t *testing.T) {
+	s := testSessionDialog()
+
+	// children stay nested under their parent whatever the order
+	items := s.items("")
+	assertIDs(t, items, "ses_1", "ses_2", "ses_3", "ses_4")
+	if items[2].depth != 1 {
+		t.Errorf("expected the subagent session to be nested, got depth %d", items[2].depth)
+	}
+
+	s.sort = sessionSortUpdated
+	assertIDs(t, s.items(""), "ses_2", "ses_3", "ses_1", "ses_4")
+
+	summaries := map[string]*app.SessionSummary{"ses_4": {Cost: 2}, "ses_1": {Cost: 1}}
+	if compareSessions(sessionSortCost, s.sessions[3], s.sessions[0], summaries) >= 0 ||
+		compareSessions(sessionSortCost, s.sessions[1], s.sessions[0], summaries) <= 0 {
+		t.Error("expected more expensive sessions first, with unindexed sessions last")
+	}
+
+	s.filter = sessionFilterShared
+	assertIDs(t, s.items(""), "ses_1")
+	s.filter = sessionFilterReverted
+	assertIDs(t, s.items(""), "ses_4")
+}
+
+func TestSearchSessions(t *testing.T) {
+	s := testSessionDialog()
+	summaries := map[string]*app.SessionSummary{
+		"ses_4": {Text: "how do i document the login flow"},
+	}
+
+	found := searchSessions("login", s.sessions, summaries)
+	if len(found) != 2 || found[0].ID != "ses_1" || found[1].ID != "ses_4" {
+		t.Errorf("expected the title match before the message match, got %v", found)
+	}
+
+	if found := searchSessions("login parser", s.sessions, summaries); len(found) != 0 {
+		t.Errorf("expected every word to be required in message text, got %v", found)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	cases := map[time.Duration]string{
+		10 * time.Second:    "now",
+		5 * time.Minute:     "5m ago",
+		3 * time.Hour:       "3h ago",
+		50 * time.Hour:      "2d ago",
+		65 * 24 * time.Hour: "2mo ago",
+	}
+	for age, want := range cases {
+		if got := formatAge(age); got != want {
+			t.Errorf("formatAge(%s) = %q, want %q", age, got, want)
+		}
+	}
+}
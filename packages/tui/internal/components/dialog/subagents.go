@@ -0,0 +1,160 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/sst/opencode/internal/util"
+)
+
+// SubagentsDialog interface for picking the sub-agent session behind a task
+// tool call
+type SubagentsDialog interface {
+	layout.Modal
+	// isSubagentsDialog tells this dialog apart from other modals
+	isSubagentsDialog()
+}
+
+type subagentsDialog struct {
+	width  int
+	height int
+	app    *app.App
+	modal  *modal.Modal
+	list   list.List[app.TaskCall]
+}
+
+func (d *subagentsDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *subagentsDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case opencode.EventListResponseEventMessagePartUpdated:
+		if part, ok := msg.Properties.Part.AsUnion().(opencode.ToolPart); ok && part.Tool == "task" {
+			d.refresh()
+		}
+	case tea.KeyPressMsg:
+		if msg.String() == "enter" {
+			item, idx := d.list.GetSelectedItem()
+			if idx < 0 {
+				return d, nil
+			}
+			return d, tea.Sequence(
+				util.CmdHandler(modal.CloseModalMsg{}),
+				d.app.OpenSession(context.Background(), item.SessionID),
+			)
+		}
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[app.TaskCall])
+	return d, cmd
+}
+
+// refresh picks up task calls started or updated while the dialog is open
+func (d *subagentsDialog) refresh() {
+	_, idx := d.list.GetSelectedItem()
+	d.list.SetItems(d.app.TaskCalls())
+	if idx >= 0 {
+		d.list.SetSelectedIndex(idx)
+	}
+}
+
+func (d *subagentsDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+
+	help := base.Render("enter") + muted.Render(" open session")
+	content := strings.Join([]string{
+		d.list.View(),
+		styles.NewStyle().PaddingLeft(1).PaddingTop(1).Render(help),
+	}, "\n")
+	return d.modal.Render(content, background)
+}
+
+func (d *subagentsDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *subagentsDialog) isSubagentsDialog() {}
+
+func renderTaskCall(item app.TaskCall, selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	icon, color := "○", t.TextMuted()
+	switch item.Part.State.Status {
+	case opencode.ToolPartStateStatusRunning:
+		icon, color = "●", t.Warning()
+	case opencode.ToolPartStateStatusCompleted:
+		icon, color = "✓", t.Success()
+	case opencode.ToolPartStateStatusError:
+		icon, color = "✗", t.Error()
+	}
+
+	title := item.Part.State.Title
+	if title == "" {
+		input, _ := item.Part.State.Input.(map[string]any)
+		title, _ = input["description"].(string)
+	}
+	metadata, _ := item.Part.State.Metadata.(map[string]any)
+	summary, _ := metadata["summary"].([]any)
+	steps := fmt.Sprintf(" %d steps", len(summary))
+	if len(summary) == 1 {
+		steps = " 1 step"
+	}
+
+	prefix := icon + " "
+	title = truncate.StringWithTail(title, uint(max(width-len(steps)-4, 1)), "...")
+	if selected {
+		return baseStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement()).
+			Width(width).
+			PaddingLeft(1).
+			Render(prefix + title + steps)
+	}
+	return baseStyle.PaddingLeft(1).Foreground(color).Render(prefix) +
+		baseStyle.Render(title) +
+		baseStyle.Foreground(t.TextMuted()).Render(steps)
+}
+
+// NewSubagentsDialog creates a picker for the sub-agent sessions started by
+// task tool calls in the current session
+func NewSubagentsDialog(a *app.App) SubagentsDialog {
+	listComponent := list.NewListComponent(
+		list.WithMaxVisibleHeight[app.TaskCall](10),
+		list.WithFallbackMessage[app.TaskCall]("No subagents in this session"),
+		list.WithAlphaNumericKeys[app.TaskCall](true),
+		list.WithRenderFunc(renderTaskCall),
+		list.WithSelectableFunc(func(item app.TaskCall) bool {
+			// the session is only known once the task has started
+			return item.SessionID != ""
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	dialog := &subagentsDialog{
+		app:  a,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Subagents"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+	dialog.refresh()
+	return dialog
+}
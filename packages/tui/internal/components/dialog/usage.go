@@ -0,0 +1,278 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/components/list"
+	"github.com/sst/opencode/internal/components/modal"
+	"github.com/sst/opencode/internal/layout"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+)
+
+// UsageDialog interface for the token, cost and context dashboard
+type UsageDialog interface {
+	layout.Modal
+	// isUsageDialog tells this dialog apart from other modals
+	isUsageDialog()
+}
+
+// usageRow is one assistant message, or one of its steps when step > 0
+type usageRow struct {
+	index   int
+	step    int
+	model   string
+	summary bool
+	tokens  app.TokenUsage
+	cost    float64
+}
+
+type usageDialog struct {
+	width  int
+	height int
+	app    *app.App
+	modal  *modal.Modal
+	list   list.List[usageRow]
+	usage  app.SessionUsage
+}
+
+func (d *usageDialog) Init() tea.Cmd {
+	return nil
+}
+
+func (d *usageDialog) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width = msg.Width
+		d.height = msg.Height
+		d.list.SetMaxWidth(layout.Current.Container.Width - 12)
+	case opencode.EventListResponseEventMessageUpdated,
+		opencode.EventListResponseEventMessagePartUpdated,
+		opencode.EventListResponseEventMessageRemoved:
+		d.refresh()
+	}
+
+	listModel, cmd := d.list.Update(msg)
+	d.list = listModel.(list.List[usageRow])
+	return d, cmd
+}
+
+func (d *usageDialog) refresh() {
+	_, idx := d.list.GetSelectedItem()
+	d.usage = app.Usage(d.app.Messages)
+	d.list.SetItems(usageRows(d.usage))
+	if idx >= 0 {
+		d.list.SetSelectedIndex(idx)
+	}
+}
+
+// usageRows lists every assistant message followed by its steps, when it
+// took more than one
+func usageRows(usage app.SessionUsage) []usageRow {
+	rows := []usageRow{}
+	for i, message := range usage.Messages {
+		rows = append(rows, usageRow{
+			index:   i + 1,
+			model:   message.ModelID,
+			summary: message.Summary,
+			tokens:  message.Tokens,
+			cost:    message.Cost,
+		})
+		if len(message.Steps) < 2 {
+			continue
+		}
+		for j, step := range message.Steps {
+			rows = append(rows, usageRow{
+				index:  i + 1,
+				step:   j + 1,
+				tokens: step.Tokens,
+				cost:   step.Cost,
+			})
+		}
+	}
+	return rows
+}
+
+// usageColumns renders token counts in fixed width columns so rows line up
+// under the header
+func usageColumns(input, output, reasoning, cacheRead, cacheWrite, cost string) string {
+	return fmt.Sprintf("%7s %7s %7s %7s %7s %8s", input, output, reasoning, cacheRead, cacheWrite, cost)
+}
+
+func (r usageRow) columns() string {
+	return usageColumns(
+		formatTokenCount(r.tokens.Input),
+		formatTokenCount(r.tokens.Output),
+		formatTokenCount(r.tokens.Reasoning),
+		formatTokenCount(r.tokens.CacheRead),
+		formatTokenCount(r.tokens.CacheWrite),
+		fmt.Sprintf("$%.4f", r.cost),
+	)
+}
+
+func (r usageRow) label() string {
+	if r.step > 0 {
+		return fmt.Sprintf("  step %d", r.step)
+	}
+	label := fmt.Sprintf("#%d %s", r.index, r.model)
+	if r.summary {
+		label += " (summary)"
+	}
+	return label
+}
+
+// usageLine fits a label to the space left of the columns
+func usageLine(label, columns string, width int) string {
+	labelWidth := max(width-lipgloss.Width(columns)-2, 1)
+	label = truncate.StringWithTail(label, uint(labelWidth), "...")
+	label += strings.Repeat(" ", max(labelWidth-lipgloss.Width(label), 0))
+	return label + " " + columns
+}
+
+func renderUsageRow(row usageRow, selected bool, width int, baseStyle styles.Style) string {
+	t := theme.CurrentTheme()
+	line := usageLine(row.label(), row.columns(), width)
+	if selected {
+		return baseStyle.
+			Background(t.Primary()).
+			Foreground(t.BackgroundElement()).
+			Width(width).
+			PaddingLeft(1).
+			Render(line)
+	}
+	style := baseStyle
+	if row.step > 0 {
+		style = style.Foreground(t.TextMuted())
+	}
+	return style.PaddingLeft(1).Render(line)
+}
+
+// formatTokenCount formats a token count in a human-readable way, such as
+// 950, 12.5K or 1.2M
+func formatTokenCount(tokens float64) string {
+	var formatted string
+	switch {
+	case tokens >= 1_000_000:
+		formatted = fmt.Sprintf("%.1fM", tokens/1_000_000)
+	case tokens >= 1_000:
+		formatted = fmt.Sprintf("%.1fK", tokens/1_000)
+	default:
+		return fmt.Sprintf("%d", int(tokens))
+	}
+	return strings.Replace(formatted, ".0", "", 1)
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values scaled to limit, one block per value. Only the
+// most recent values are drawn when there are more than width.
+func sparkline(values []float64, limit float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	for _, value := range values {
+		limit = max(limit, value)
+	}
+	if limit <= 0 {
+		return strings.Repeat(string(sparkBlocks[0]), len(values))
+	}
+	var b strings.Builder
+	for _, value := range values {
+		level := int(value / limit * float64(len(sparkBlocks)-1))
+		b.WriteRune(sparkBlocks[max(0, min(level, len(sparkBlocks)-1))])
+	}
+	return b.String()
+}
+
+func (d *usageDialog) Render(background string) string {
+	t := theme.CurrentTheme()
+	width := layout.Current.Container.Width - 12
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundPanel())
+	muted := base.Foreground(t.TextMuted())
+	pad := styles.NewStyle().PaddingLeft(1).Render
+
+	contextLimit := float64(0)
+	if d.app.Model != nil {
+		contextLimit = d.app.Model.Limit.Context
+	}
+	share := d.app.ContextShare(d.usage)
+	contextStyle := base
+	if d.app.ContextWarning(d.usage) {
+		contextStyle = base.Foreground(t.Warning())
+	}
+	contextText := formatTokenCount(d.usage.Context)
+	if contextLimit > 0 {
+		contextText += fmt.Sprintf(" / %s (%.0f%%)", formatTokenCount(contextLimit), share*100)
+	}
+	summary := muted.Render("context ") + contextStyle.Render(contextText) +
+		muted.Render("   cost ") + base.Render(fmt.Sprintf("$%.4f", d.usage.Cost))
+
+	lines := []string{pad(summary)}
+	if d.app.ContextWarning(d.usage) {
+		warning := fmt.Sprintf(
+			"Over %.0f%% of the context window is used, /compact to summarize the session",
+			d.app.State.ContextWarningShare()*100,
+		)
+		lines = append(lines, pad(base.Foreground(t.Warning()).Render(truncate.StringWithTail(warning, uint(max(width-1, 1)), "..."))))
+	}
+	if len(d.usage.ContextHistory) > 0 {
+		spark := sparkline(d.usage.ContextHistory, contextLimit, max(width-10, 1))
+		lines = append(lines, pad(muted.Render("history ")+contextStyle.Render(spark)))
+	}
+
+	header := func(label string) string {
+		return pad(muted.Render(usageLine(label, usageColumns("input", "output", "reason", "c.read", "c.write", "cost"), width)))
+	}
+	lines = append(lines, "", header("per model"))
+	for _, model := range d.usage.Models {
+		row := usageRow{tokens: model.Tokens, cost: model.Cost}
+		label := fmt.Sprintf("%s (%d)", model.ModelID, model.Messages)
+		lines = append(lines, pad(base.Render(usageLine(label, row.columns(), width))))
+	}
+	if len(d.usage.Models) > 1 {
+		total := usageRow{tokens: d.usage.Tokens, cost: d.usage.Cost}
+		lines = append(lines, pad(base.Bold(true).Render(usageLine("total", total.columns(), width))))
+	}
+
+	lines = append(lines, "", header("per message"), d.list.View())
+	return d.modal.Render(strings.Join(lines, "\n"), background)
+}
+
+func (d *usageDialog) Close() tea.Cmd {
+	return nil
+}
+
+func (d *usageDialog) isUsageDialog() {}
+
+// NewUsageDialog creates a dashboard of the token usage, cost and context
+// size of the current session
+func NewUsageDialog(a *app.App) UsageDialog {
+	listComponent := list.NewListComponent(
+		list.WithItems([]usageRow{}),
+		list.WithMaxVisibleHeight[usageRow](10),
+		list.WithFallbackMessage[usageRow]("No assistant messages yet"),
+		list.WithAlphaNumericKeys[usageRow](true),
+		list.WithRenderFunc(renderUsageRow),
+		list.WithSelectableFunc(func(row usageRow) bool {
+			return true
+		}),
+	)
+	listComponent.SetMaxWidth(layout.Current.Container.Width - 12)
+
+	dialog := &usageDialog{
+		app:  a,
+		list: listComponent,
+		modal: modal.New(
+			modal.WithTitle("Usage"),
+			modal.WithMaxWidth(layout.Current.Container.Width-8),
+		),
+	}
+	dialog.refresh()
+	return dialog
+}
@@ -0,0 +1,37 @@
+package dialog
+
+import (
+	"testing"
+
+	"github.com/sst/opencode/internal/app"
+)
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]float64{0, 50, 100}, 100, 10); got != "▁▄█" {
+		t.Errorf("sparkline() = %q", got)
+	}
+	if got := sparkline([]float64{10, 20, 30, 40}, 40, 2); got != "▆█" {
+		t.Errorf("expected only the latest values to fit, got %q", got)
+	}
+	if got := sparkline([]float64{200}, 100, 5); got != "█" {
+		t.Errorf("values over the limit should be clamped, got %q", got)
+	}
+}
+
+func TestFormatTokenCount(t *testing.T) {
+	for tokens, want := range map[float64]string{950: "950", 12000: "12K", 12500: "12.5K", 1_200_000: "1.2M"} {
+		if got := formatTokenCount(tokens); got != want {
+			t.Errorf("formatTokenCount(%v) = %q, want %q", tokens, got, want)
+		}
+	}
+}
+
+func TestUsageRows(t *testing.T) {
+	rows := usageRows(app.SessionUsage{Messages: []app.MessageUsage{
+		{ModelID: "a", Steps: []app.StepUsage{{}}},
+		{ModelID: "b", Steps: []app.StepUsage{{}, {}}},
+	}})
+	if len(rows) != 4 || rows[1].label() != "#2 b" || rows[3].label() != "  step 2" {
+		t.Errorf("expected steps listed only for messages with several, got %+v", rows)
+	}
+}
@@ -32,6 +32,8 @@ type Model struct {
 	content       *string
 	isDiff        *bool
 	diffStyle     DiffStyle
+	// scrollTo is the line to reveal once the pending render completes, or -1
+	scrollTo int
 }
 
 type fileRenderedMsg struct {
@@ -44,6 +46,7 @@ func New(app *app.App) Model {
 		app:       app,
 		viewport:  vp,
 		diffStyle: DiffStyleUnified,
+		scrollTo:  -1,
 	}
 	if app.State.SplitDiff {
 		m.diffStyle = DiffStyleSplit
@@ -61,6 +64,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case fileRenderedMsg:
 		m.viewport.SetContent(msg.content)
+		if m.scrollTo >= 0 {
+			m.viewport.SetYOffset(m.scrollTo)
+			m.scrollTo = -1
+		}
 		return m, util.CmdHandler(app.FileRenderedMsg{
 			FilePath: *m.filename,
 		})
@@ -232,6 +239,12 @@ func (m *Model) ScrollTo(line int) {
 	m.viewport.SetYOffset(line)
 }
 
+// ScrollToLine reveals a line of the file, with a few lines of context above
+// it, once the file has been rendered
+func (m *Model) ScrollToLine(line int) {
+	m.scrollTo = max(0, line-3)
+}
+
 func (m *Model) ScrollToBottom() {
 	m.viewport.GotoBottom()
 }
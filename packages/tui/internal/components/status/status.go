@@ -1,12 +1,15 @@
 package status
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/charmbracelet/lipgloss/v2/compat"
+	"github.com/muesli/reflow/truncate"
 	"github.com/sst/opencode/internal/app"
 	"github.com/sst/opencode/internal/commands"
 	"github.com/sst/opencode/internal/styles"
@@ -19,9 +22,10 @@ type StatusComponent interface {
 }
 
 type statusComponent struct {
-	app   *app.App
-	width int
-	cwd   string
+	app          *app.App
+	width        int
+	cwd          string
+	reconnecting int
 }
 
 func (m statusComponent) Init() tea.Cmd {
@@ -33,6 +37,12 @@ func (m statusComponent) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		return m, nil
+	case app.EventStreamReconnectingMsg:
+		m.reconnecting = msg.Attempt
+		return m, nil
+	case app.EventStreamConnectedMsg:
+		m.reconnecting = 0
+		return m, nil
 	}
 	return m, nil
 }
@@ -55,6 +65,92 @@ func (m statusComponent) logo() string {
 		Render(open + code + version)
 }
 
+// diagnostics renders the error and warning counts reported by the language
+// servers, or nothing when the workspace is clean
+func (m statusComponent) diagnostics() string {
+	t := theme.CurrentTheme()
+	errors := m.app.Diagnostics.Count(app.DiagnosticSeverityError)
+	warnings := m.app.Diagnostics.Count(app.DiagnosticSeverityWarning)
+	if errors == 0 && warnings == 0 {
+		return ""
+	}
+
+	style := styles.NewStyle().Background(t.BackgroundPanel())
+	badges := []string{}
+	if errors > 0 {
+		badges = append(badges, style.Foreground(t.Error()).Render(fmt.Sprintf("✗ %d", errors)))
+	}
+	if warnings > 0 {
+		badges = append(badges, style.Foreground(t.Warning()).Render(fmt.Sprintf("⚠ %d", warnings)))
+	}
+	return style.Padding(0, 1).Render(strings.Join(badges, style.Render(" ")))
+}
+
+// compaction renders how long the session has been summarizing, or nothing
+// when no compaction is running
+func (m statusComponent) compaction() string {
+	compaction := m.app.Compaction()
+	if compaction == nil || compaction.SessionID != m.app.Session.ID {
+		return ""
+	}
+	t := theme.CurrentTheme()
+	label := "compacting"
+	if compaction.Auto {
+		label = "auto-compacting"
+	}
+	elapsed := time.Since(compaction.Started).Truncate(time.Second)
+	return styles.NewStyle().
+		Foreground(t.Primary()).
+		Background(t.BackgroundPanel()).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf("%s %s", label, elapsed))
+}
+
+// tabs renders the open sessions, marking those where the agent is working,
+// that failed or that changed since they were last shown. Nothing is shown
+// until there is another session to switch to.
+func (m statusComponent) tabs() string {
+	t := theme.CurrentTheme()
+	active := m.app.ActiveTab()
+	showNew := active == -1 && m.app.Session.ID == ""
+	count := len(m.app.Tabs)
+	if showNew {
+		count++
+	}
+	if count == 0 || (count == 1 && active != -1) {
+		return ""
+	}
+
+	titleWidth := max(8, min(24, m.width/count-6))
+	rendered := []string{}
+	for i, tab := range m.app.Tabs {
+		style := styles.NewStyle().Foreground(t.TextMuted()).Background(t.Background())
+		title, busy := tab.Session.Title, tab.Busy()
+		if i == active {
+			style = style.Foreground(t.Text()).Background(t.BackgroundElement()).Bold(true)
+			title, busy = m.app.Session.Title, m.app.IsBusy()
+		}
+		marker := style.Render(" ")
+		switch {
+		case busy:
+			marker = style.Foreground(t.Primary()).Render("●")
+		case tab.Error != nil:
+			marker = style.Foreground(t.Error()).Render("✗")
+		case tab.Unread:
+			marker = style.Foreground(t.Accent()).Render("•")
+		}
+		title = truncate.StringWithTail(title, uint(titleWidth), "…")
+		rendered = append(rendered, style.Padding(0, 1).Render(marker+style.Render(" "+title)))
+	}
+	if showNew {
+		style := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement()).Bold(true)
+		rendered = append(rendered, style.Padding(0, 1).Render("+ new"))
+	}
+	bar := strings.Join(rendered, styles.NewStyle().Background(t.Background()).Render(" "))
+	return styles.NewStyle().Background(t.Background()).Width(m.width).MaxWidth(m.width).Render(bar)
+}
+
 func (m statusComponent) View() string {
 	t := theme.CurrentTheme()
 	logo := m.logo()
@@ -65,6 +161,21 @@ func (m statusComponent) View() string {
 		Padding(0, 1).
 		Render(m.cwd)
 
+	if m.reconnecting > 0 {
+		label := "reconnecting"
+		if m.reconnecting > 1 {
+			label = fmt.Sprintf("reconnecting (%d)", m.reconnecting)
+		}
+		cwd += styles.NewStyle().
+			Foreground(t.Warning()).
+			Background(t.BackgroundPanel()).
+			Bold(true).
+			Padding(0, 1).
+			Render(label)
+	}
+	cwd += m.compaction()
+	cwd += m.diagnostics()
+
 	var modeBackground compat.AdaptiveColor
 	var modeForeground compat.AdaptiveColor
 	switch m.app.ModeIndex {
@@ -128,8 +239,11 @@ func (m statusComponent) View() string {
 
 	status := logo + cwd + spacer + mode
 
-	blank := styles.NewStyle().Background(t.Background()).Width(m.width).Render("")
-	return blank + "\n" + status
+	top := m.tabs()
+	if top == "" {
+		top = styles.NewStyle().Background(t.Background()).Width(m.width).Render("")
+	}
+	return top + "\n" + status
 }
 
 func NewStatusCmp(app *app.App) StatusComponent {
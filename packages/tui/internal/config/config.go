@@ -22,23 +22,80 @@ type ModeModel struct {
 }
 
 type State struct {
-	Theme              string               `toml:"theme"`
-	ModeModel          map[string]ModeModel `toml:"mode_model"`
-	Provider           string               `toml:"provider"`
-	Model              string               `toml:"model"`
-	Mode               string               `toml:"mode"`
-	RecentlyUsedModels []ModelUsage         `toml:"recently_used_models"`
-	MessagesRight      bool                 `toml:"messages_right"`
-	SplitDiff          bool                 `toml:"split_diff"`
+	Theme                string               `toml:"theme"`
+	ModeModel            map[string]ModeModel `toml:"mode_model"`
+	Provider             string               `toml:"provider"`
+	Model                string               `toml:"model"`
+	Mode                 string               `toml:"mode"`
+	RecentlyUsedModels   []ModelUsage         `toml:"recently_used_models"`
+	MessagesRight        bool                 `toml:"messages_right"`
+	SplitDiff            bool                 `toml:"split_diff"`
+	DraftMaxAgeDays      int                  `toml:"draft_max_age_days"`
+	ContextWarning       float64              `toml:"context_warning"`
+	AutoCompact          bool                 `toml:"auto_compact"`
+	AutoCompactThreshold float64              `toml:"auto_compact_threshold"`
+	Notify               string               `toml:"notify"`
+	NotifyCommand        string               `toml:"notify_command"`
 }
 
+// DefaultContextWarning is the share of the context window past which the
+// usage is flagged when the state does not set context_warning
+const DefaultContextWarning = 0.8
+
+// DefaultAutoCompactThreshold is the share of the context window past which
+// the session is summarized when auto_compact is on
+const DefaultAutoCompactThreshold = 0.9
+
+// Notify modes choose how the terminal is told that a session finished or
+// failed while it was unfocused
+const (
+	// NotifyOSC sends an OSC 9 or OSC 777 desktop notification and rings the
+	// bell
+	NotifyOSC = "osc"
+	// NotifyBell only rings the bell
+	NotifyBell = "bell"
+	// NotifyOff disables terminal notifications. notify_command still runs.
+	NotifyOff = "off"
+)
+
 func NewState() *State {
 	return &State{
-		Theme:              "opencode",
-		Mode:               "build",
-		ModeModel:          make(map[string]ModeModel),
-		RecentlyUsedModels: make([]ModelUsage, 0),
+		Theme:                "opencode",
+		Mode:                 "build",
+		ModeModel:            make(map[string]ModeModel),
+		RecentlyUsedModels:   make([]ModelUsage, 0),
+		DraftMaxAgeDays:      14,
+		ContextWarning:       DefaultContextWarning,
+		AutoCompactThreshold: DefaultAutoCompactThreshold,
+	}
+}
+
+// ContextWarningShare returns the share of the context window past which
+// the usage is flagged
+func (s *State) ContextWarningShare() float64 {
+	if s.ContextWarning <= 0 || s.ContextWarning > 1 {
+		return DefaultContextWarning
+	}
+	return s.ContextWarning
+}
+
+// AutoCompactShare returns the share of the context window past which the
+// session is summarized when auto-compact is on
+func (s *State) AutoCompactShare() float64 {
+	if s.AutoCompactThreshold <= 0 || s.AutoCompactThreshold > 1 {
+		return DefaultAutoCompactThreshold
+	}
+	return s.AutoCompactThreshold
+}
+
+// NotifyMode returns how the terminal is notified, one of NotifyOSC,
+// NotifyBell or NotifyOff
+func (s *State) NotifyMode() string {
+	switch s.Notify {
+	case NotifyBell, NotifyOff:
+		return s.Notify
 	}
+	return NotifyOSC
 }
 
 // UpdateModelUsage updates the recently used models list with the specified model
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultDraftMaxAge is how long unsent drafts are kept when the state does
+// not set draft_max_age_days
+const DefaultDraftMaxAge = 14 * 24 * time.Hour
+
+// DraftMaxAge returns how long unsent drafts are kept
+func (s *State) DraftMaxAge() time.Duration {
+	if s.DraftMaxAgeDays <= 0 {
+		return DefaultDraftMaxAge
+	}
+	return time.Duration(s.DraftMaxAgeDays) * 24 * time.Hour
+}
+
+// DraftPath returns the file holding the unsent prompt of a session. The
+// empty session ID stands for a session that has not been created yet in the
+// project at root, so projects never share that draft.
+func DraftPath(stateDir, root, sessionID string) string {
+	if sessionID == "" {
+		sessionID = "new-" + projectKey(root)
+	}
+	return filepath.Join(stateDir, "tui-drafts", sessionID+".json")
+}
+
+// LoadDraft reads the unsent prompt of a session, returning nil when there
+// is none
+func LoadDraft(stateDir, root, sessionID string) (*PromptEntry, error) {
+	path := DraftPath(stateDir, root, sessionID)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft %s: %w", path, err)
+	}
+	var draft PromptEntry
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return nil, fmt.Errorf("failed to decode draft %s: %w", path, err)
+	}
+	return &draft, nil
+}
+
+// SaveDraft writes the unsent prompt of a session, removing the draft when
+// the prompt is empty. Inline attachments such as pasted images are kept in
+// full so they survive a restart.
+func SaveDraft(stateDir, root, sessionID string, draft PromptEntry) error {
+	path := DraftPath(stateDir, root, sessionID)
+	if strings.TrimSpace(draft.Text) == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove draft %s: %w", path, err)
+		}
+		return nil
+	}
+	if draft.Time.IsZero() {
+		draft.Time = time.Now()
+	}
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return fmt.Errorf("failed to encode draft: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create draft directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write draft %s: %w", path, err)
+	}
+	return nil
+}
+
+// PurgeDrafts removes drafts last saved longer than maxAge ago and returns
+// how many were removed
+func PurgeDrafts(stateDir string, maxAge time.Duration) (int, error) {
+	dir := filepath.Join(stateDir, "tui-drafts")
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read draft directory %s: %w", dir, err)
+	}
+	purged := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return purged, fmt.Errorf("failed to remove draft %s: %w", entry.Name(), err)
+		}
+		purged++
+	}
+	return purged, nil
+}
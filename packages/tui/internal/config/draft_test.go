@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDrafts(t *testing.T) {
+	dir := t.TempDir()
+	image := PromptAttachment{Mime: "image/png", URL: "data:image/png;base64,AA==", Display: "[Image #1]"}
+	if err := SaveDraft(dir, "/project", "ses_1", PromptEntry{Text: "look at [Image #1]", Attachments: []PromptAttachment{image}}); err != nil {
+		t.Fatalf("SaveDraft() error = %v", err)
+	}
+
+	draft, err := LoadDraft(dir, "/project", "ses_1")
+	if err != nil || draft == nil {
+		t.Fatalf("LoadDraft() = %v, %v", draft, err)
+	}
+	if draft.Text != "look at [Image #1]" || len(draft.Attachments) != 1 || draft.Attachments[0] != image {
+		t.Errorf("LoadDraft() = %+v", draft)
+	}
+	if other, _ := LoadDraft(dir, "/project", "ses_2"); other != nil {
+		t.Errorf("sessions should not share drafts, got %+v", other)
+	}
+
+	if err := SaveDraft(dir, "/project", "ses_1", PromptEntry{Text: " "}); err != nil {
+		t.Fatalf("SaveDraft() error = %v", err)
+	}
+	if draft, _ := LoadDraft(dir, "/project", "ses_1"); draft != nil {
+		t.Errorf("saving an empty prompt should remove the draft, got %+v", draft)
+	}
+}
+
+func TestPurgeDrafts(t *testing.T) {
+	dir := t.TempDir()
+	SaveDraft(dir, "/project", "ses_old", PromptEntry{Text: "old"})
+	SaveDraft(dir, "/project", "", PromptEntry{Text: "recent"})
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(DraftPath(dir, "/project", "ses_old"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := PurgeDrafts(dir, 24*time.Hour)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDrafts() = %d, %v, want 1", purged, err)
+	}
+	if draft, _ := LoadDraft(dir, "/project", ""); draft == nil || draft.Text != "recent" {
+		t.Errorf("expected the recent draft to be kept, got %+v", draft)
+	}
+	if draft, _ := LoadDraft(dir, "/other", ""); draft != nil {
+		t.Errorf("projects should not share the draft of a new session, got %+v", draft)
+	}
+	if (&State{}).DraftMaxAge() != DefaultDraftMaxAge {
+		t.Error("expected the default max age when the state does not set one")
+	}
+}
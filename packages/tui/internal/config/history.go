@@ -0,0 +1,279 @@
+package config
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PromptHistoryLimit caps the prompts kept per project
+	PromptHistoryLimit = 500
+	// promptHistoryMaxInline caps the size of inline attachments, such as
+	// pasted images, that are kept with a prompt
+	promptHistoryMaxInline = 256 * 1024
+)
+
+type PromptAttachment struct {
+	Mime     string `json:"mime"`
+	URL      string `json:"url"`
+	Filename string `json:"filename"`
+	// Display is the label the attachment had in the editor
+	Display string `json:"display,omitempty"`
+	// Blob is the file an inline attachment of a saved prompt is kept in,
+	// in place of its URL
+	Blob string `json:"blob,omitempty"`
+}
+
+type PromptEntry struct {
+	Text        string             `json:"text"`
+	Attachments []PromptAttachment `json:"attachments,omitempty"`
+	Time        time.Time          `json:"time"`
+}
+
+// PromptHistory holds the prompts sent in one project, oldest first. The
+// file is appended one entry per line, and only rewritten once superseded
+// entries make up half of it.
+type PromptHistory struct {
+	mu      sync.RWMutex
+	path    string
+	lines   int
+	Root    string
+	Entries []PromptEntry
+}
+
+// PromptHistoryPath returns the file holding the prompt history of a
+// project, so projects never share a history
+func PromptHistoryPath(stateDir, root string) string {
+	return filepath.Join(stateDir, "tui-history", projectKey(root)+".jsonl")
+}
+
+// projectKey names the files kept per project after its root
+func projectKey(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadPromptHistory reads the prompt history of a project. A missing file
+// yields an empty history.
+func LoadPromptHistory(stateDir, root string) (*PromptHistory, error) {
+	history := &PromptHistory{path: PromptHistoryPath(stateDir, root), Root: root}
+	file, err := os.Open(history.path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return history, fmt.Errorf("failed to read prompt history %s: %w", history.path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		history.lines++
+		var entry PromptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return history, fmt.Errorf("failed to decode prompt history %s: %w", history.path, err)
+		}
+		history.insert(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return history, fmt.Errorf("failed to read prompt history %s: %w", history.path, err)
+	}
+	return history, nil
+}
+
+// insert makes entry the newest prompt, dropping an earlier copy of it and
+// the oldest prompts past the limit
+func (h *PromptHistory) insert(entry PromptEntry) {
+	h.Entries = slices.DeleteFunc(h.Entries, func(existing PromptEntry) bool {
+		return existing.Text == entry.Text
+	})
+	h.Entries = append(h.Entries, entry)
+	if len(h.Entries) > PromptHistoryLimit {
+		h.Entries = slices.Delete(h.Entries, 0, len(h.Entries)-PromptHistoryLimit)
+	}
+}
+
+// Len returns the number of prompts in the history
+func (h *PromptHistory) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.Entries)
+}
+
+// At returns the prompt at index, counting from the oldest
+func (h *PromptHistory) At(index int) (PromptEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if index < 0 || index >= len(h.Entries) {
+		return PromptEntry{}, false
+	}
+	return h.Entries[index], true
+}
+
+// Search returns the prompts containing every word of the query, ignoring
+// case, newest first
+func (h *PromptHistory) Search(query string) []PromptEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	words := strings.Fields(strings.ToLower(query))
+	matches := []PromptEntry{}
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		text := strings.ToLower(h.Entries[i].Text)
+		if !slices.ContainsFunc(words, func(word string) bool {
+			return !strings.Contains(text, word)
+		}) {
+			matches = append(matches, h.Entries[i])
+		}
+	}
+	return matches
+}
+
+// Add records a prompt as the newest entry and saves the history. Sending a
+// prompt again moves it to the end instead of storing it twice. Inline
+// attachments are written to their own files so the history stays small.
+func (h *PromptHistory) Add(entry PromptEntry) error {
+	entry.Text = strings.TrimSpace(entry.Text)
+	if entry.Text == "" {
+		return nil
+	}
+	entry.Attachments = slices.DeleteFunc(slices.Clone(entry.Attachments), func(attachment PromptAttachment) bool {
+		return strings.HasPrefix(attachment.URL, "data:") && len(attachment.URL) > promptHistoryMaxInline
+	})
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create prompt history directory: %w", err)
+	}
+	for i, attachment := range entry.Attachments {
+		if !strings.HasPrefix(attachment.URL, "data:") {
+			continue
+		}
+		blob, err := saveBlob(h.blobDir(), attachment.URL)
+		if err != nil {
+			return err
+		}
+		entry.Attachments[i].URL = ""
+		entry.Attachments[i].Blob = blob
+	}
+	h.insert(entry)
+
+	if h.lines >= 2*PromptHistoryLimit {
+		return h.compact()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt history: %w", err)
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write prompt history %s: %w", h.path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write prompt history %s: %w", h.path, err)
+	}
+	h.lines++
+	return nil
+}
+
+// compact rewrites the history with only the prompts it still holds and
+// removes the blobs none of them use
+func (h *PromptHistory) compact() error {
+	var data []byte
+	used := map[string]bool{}
+	for _, entry := range h.Entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode prompt history: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+		for _, attachment := range entry.Attachments {
+			used[attachment.Blob] = true
+		}
+	}
+	temp := h.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write prompt history %s: %w", h.path, err)
+	}
+	if err := os.Rename(temp, h.path); err != nil {
+		return fmt.Errorf("failed to write prompt history %s: %w", h.path, err)
+	}
+	h.lines = len(h.Entries)
+
+	blobs, _ := os.ReadDir(h.blobDir())
+	for _, blob := range blobs {
+		if path := filepath.Join(h.blobDir(), blob.Name()); !used[path] {
+			os.Remove(path)
+		}
+	}
+	return nil
+}
+
+// blobDir returns the directory holding the inline attachments of the
+// history
+func (h *PromptHistory) blobDir() string {
+	return strings.TrimSuffix(h.path, ".jsonl") + "-blobs"
+}
+
+// saveBlob writes inline attachment data to a file named by its hash and
+// returns the file's path
+func saveBlob(dir, data string) (string, error) {
+	sum := sha256.Sum256([]byte(data))
+	path := filepath.Join(dir, hex.EncodeToString(sum[:]))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create prompt history directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write attachment %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// ValidAttachments returns the attachments of a prompt that can still be
+// sent, with inline attachments read back from their blobs. Files
+// referenced by path must still exist relative to cwd.
+func (e PromptEntry) ValidAttachments(cwd string) []PromptAttachment {
+	valid := []PromptAttachment{}
+	for _, attachment := range e.Attachments {
+		if attachment.Blob != "" {
+			data, err := os.ReadFile(attachment.Blob)
+			if err != nil {
+				continue
+			}
+			attachment.URL = string(data)
+			attachment.Blob = ""
+		}
+		if path, ok := strings.CutPrefix(attachment.URL, "file://"); ok {
+			path, _, _ = strings.Cut(path, "?")
+			if unescaped, err := url.PathUnescape(path); err == nil {
+				path = unescaped
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(cwd, path)
+			}
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+		}
+		valid = append(valid, attachment)
+	}
+	return valid
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPromptHistory(t *testing.T) {
+	dir := t.TempDir()
+	history, err := LoadPromptHistory(dir, "/project")
+	if err != nil {
+		t.Fatalf("LoadPromptHistory() error = %v", err)
+	}
+
+	for _, text := range []string{"fix the build", "add tests", "fix the build", "  "} {
+		if err := history.Add(PromptEntry{Text: text}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if history.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 after deduplication", history.Len())
+	}
+	if newest, _ := history.At(1); newest.Text != "fix the build" {
+		t.Errorf("resending a prompt should make it the newest, got %q", newest.Text)
+	}
+
+	reloaded, err := LoadPromptHistory(dir, "/project")
+	if err != nil {
+		t.Fatalf("LoadPromptHistory() error = %v", err)
+	}
+	if reloaded.Len() != 2 {
+		t.Errorf("reloaded Len() = %d, want 2", reloaded.Len())
+	}
+	other, _ := LoadPromptHistory(dir, "/other")
+	if other.Len() != 0 {
+		t.Errorf("projects should not share a history, got %d entries", other.Len())
+	}
+
+	matches := history.Search("BUILD fix")
+	if len(matches) != 1 || matches[0].Text != "fix the build" {
+		t.Errorf("Search() = %+v", matches)
+	}
+	if all := history.Search(""); len(all) != 2 || all[0].Text != "fix the build" {
+		t.Errorf("an empty search should list every prompt newest first, got %+v", all)
+	}
+}
+
+func TestPromptHistoryLimit(t *testing.T) {
+	history, _ := LoadPromptHistory(t.TempDir(), "/project")
+	for i := range PromptHistoryLimit + 5 {
+		if err := history.Add(PromptEntry{Text: fmt.Sprintf("prompt %d", i)}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if history.Len() != PromptHistoryLimit {
+		t.Fatalf("Len() = %d, want %d", history.Len(), PromptHistoryLimit)
+	}
+	if oldest, _ := history.At(0); oldest.Text != "prompt 5" {
+		t.Errorf("expected the oldest prompts to be dropped, oldest is %q", oldest.Text)
+	}
+}
+
+func TestValidAttachments(t *testing.T) {
+	cwd := t.TempDir()
+	if err := os.WriteFile(filepath.Join(cwd, "main go.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entry := PromptEntry{Attachments: []PromptAttachment{
+		{URL: "file://./main%20go.go"},
+		{URL: "file://./main%20go.go?start=1&end=4"},
+		{URL: "file://./deleted.go"},
+		{URL: "data:image/png;base64,AA=="},
+	}}
+	if valid := entry.ValidAttachments(cwd); len(valid) != 3 || valid[2].URL != "data:image/png;base64,AA==" {
+		t.Errorf("ValidAttachments() = %+v", valid)
+	}
+}
+
+func TestPromptHistoryFile(t *testing.T) {
+	dir := t.TempDir()
+	history, _ := LoadPromptHistory(dir, "/project")
+	image := "data:image/png;base64,AA=="
+	if err := history.Add(PromptEntry{Text: "what is this", Attachments: []PromptAttachment{{URL: image}}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	data, _ := os.ReadFile(PromptHistoryPath(dir, "/project"))
+	if strings.Contains(string(data), image) {
+		t.Error("expected inline attachments to be kept out of the history file")
+	}
+	reloaded, _ := LoadPromptHistory(dir, "/project")
+	entry, _ := reloaded.At(0)
+	if valid := entry.ValidAttachments(dir); len(valid) != 1 || valid[0].URL != image {
+		t.Fatalf("expected the inline attachment to be read back, got %+v", valid)
+	}
+
+	for i := range 2 * PromptHistoryLimit {
+		if err := history.Add(PromptEntry{Text: fmt.Sprintf("prompt %d", i)}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	data, _ = os.ReadFile(PromptHistoryPath(dir, "/project"))
+	if lines := strings.Count(string(data), "\n"); lines > 2*PromptHistoryLimit {
+		t.Errorf("expected superseded entries to be compacted, file has %d lines", lines)
+	}
+	if blobs, _ := os.ReadDir(history.blobDir()); len(blobs) != 0 {
+		t.Errorf("expected blobs of dropped prompts to be removed, %d left", len(blobs))
+	}
+	reloaded, _ = LoadPromptHistory(dir, "/project")
+	if reloaded.Len() != PromptHistoryLimit {
+		t.Errorf("reloaded Len() = %d, want %d", reloaded.Len(), PromptHistoryLimit)
+	}
+}
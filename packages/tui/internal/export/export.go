@@ -0,0 +1,56 @@
+// Package export renders a session transcript to files in different formats.
+// Exporters register themselves by format name so new formats can be added
+// without touching the /export command.
+package export
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+// Exporter writes a session and its messages in a single format
+type Exporter interface {
+	// Format is the name the exporter is selected with, e.g. /export markdown
+	Format() string
+	// Extension is the file extension used for exported files, without a dot
+	Extension() string
+	Export(w io.Writer, session opencode.Session, messages []app.Message) error
+}
+
+var (
+	exporters = map[string]Exporter{}
+	formats   []string
+)
+
+// Register makes an exporter available under its format name and any aliases
+func Register(exporter Exporter, aliases ...string) {
+	formats = append(formats, exporter.Format())
+	for _, name := range append([]string{exporter.Format()}, aliases...) {
+		exporters[strings.ToLower(name)] = exporter
+	}
+}
+
+// Get returns the exporter registered for a format name or alias
+func Get(format string) (Exporter, error) {
+	exporter, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format %q, expected one of %s", format, strings.Join(Formats(), ", "))
+	}
+	return exporter, nil
+}
+
+// Formats lists the registered format names in registration order
+func Formats() []string {
+	return slices.Clone(formats)
+}
+
+func init() {
+	Register(Markdown{}, "md")
+	Register(JSON{})
+	Register(HTML{}, "htm")
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+const transcript = `[
+	{"info":{"id":"msg_1","role":"user","sessionID":"ses_1","time":{"created":1}},
+	 "parts":[{"id":"prt_1","type":"text","messageID":"msg_1","sessionID":"ses_1","text":"fix <b>main</b>"}]},
+	{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1","modelID":"model","providerID":"provider",
+	  "cost":0.25,"path":{"cwd":"/","root":"/"},"system":[],"time":{"created":2,"completed":3},
+	  "tokens":{"input":10,"output":20,"reasoning":0,"cache":{"read":0,"write":0}},
+	  "error":{"name":"UnknownError","data":{"message":"boom"}}},
+	 "parts":[
+	  {"id":"prt_2","type":"tool","messageID":"msg_2","sessionID":"ses_1","callID":"call_1","tool":"bash",
+	   "state":{"status":"completed","input":{"command":"ls"},"output":"README.md","title":"ls",
+	    "metadata":{},"time":{"start":1,"end":2}}},
+	  {"id":"prt_3","type":"tool","messageID":"msg_2","sessionID":"ses_1","callID":"call_2","tool":"edit",
+	   "state":{"status":"completed","input":{"filePath":"main.go"},"output":"","title":"main.go",
+	    "metadata":{"diff":"@@ -1 +1 @@\n-old\n+new\n"},"time":{"start":2,"end":3}}},
+	  {"id":"prt_4","type":"tool","messageID":"msg_2","sessionID":"ses_1","callID":"call_3","tool":"todowrite",
+	   "state":{"status":"completed","input":{"todos":[]},"output":"","title":"1 todo",
+	    "metadata":{"todos":[{"content":"ship it","status":"completed","priority":"high","id":"1"}]},
+	    "time":{"start":3,"end":4}}}]}
+]`
+
+func fixture(t *testing.T) (opencode.Session, []app.Message) {
+	t.Helper()
+	var messages []app.Message
+	if err := json.Unmarshal([]byte(transcript), &messages); err != nil {
+		t.Fatalf("failed to decode messages: %v", err)
+	}
+	return opencode.Session{ID: "ses_1", Title: "Fixing main"}, messages
+}
+
+func export(t *testing.T, format string) string {
+	t.Helper()
+	exporter, err := Get(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, messages := fixture(t)
+	var out bytes.Buffer
+	if err := exporter.Export(&out, session, messages); err != nil {
+		t.Fatalf("%s export failed: %v", format, err)
+	}
+	return out.String()
+}
+
+func TestMarkdown(t *testing.T) {
+	out := export(t, "md")
+	for _, want := range []string{
+		"# Fixing main",
+		"## User",
+		"## Assistant (model)",
+		"### Tool: bash",
+		"\"command\": \"ls\"",
+		"**Output:**\n\n```\nREADME.md\n```",
+		"```diff\n@@ -1 +1 @@\n-old\n+new\n```",
+		"- [x] ship it",
+		"> **Error:** boom",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown export is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	out := export(t, "json")
+	document, err := ReadDocument(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, messages := fixture(t)
+	if document.Version != DocumentVersion || document.Session.ID != "ses_1" {
+		t.Errorf("unexpected document header: %+v", document)
+	}
+	if drift := app.DiffMessages(messages, document.Messages); !drift.Empty() {
+		t.Errorf("messages changed after a round trip: %s", drift)
+	}
+
+	if _, err := ReadDocument(strings.NewReader(`{"version":99}`)); err == nil {
+		t.Error("expected newer document versions to be rejected")
+	}
+}
+
+func TestReadMalformedDocument(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`not json`,
+		`{"version":1,"session":`,
+		`{"version":"1"}`,
+		`{"version":1,"messages":{}}`,
+		`{"version":1,"messages":[{"info":"text"}]}`,
+	} {
+		if _, err := ReadDocument(strings.NewReader(input)); err == nil {
+			t.Errorf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	out := export(t, "html")
+	for _, want := range []string{
+		"<title>Fixing main</title>",
+		"--background: #",
+		"fix &lt;b&gt;main&lt;/b&gt;",
+		`<span class="removed">-old</span>`,
+		`<span class="added">&#43;new</span>`,
+		`<li class="todo-completed">`,
+		`<p class="error">boom</p>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html export is missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<b>main</b>") {
+		t.Error("html export must escape message text")
+	}
+}
+
+func TestGetUnknownFormat(t *testing.T) {
+	if _, err := Get("pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,272 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/v2/compat"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+	"github.com/sst/opencode/internal/styles"
+	"github.com/sst/opencode/internal/theme"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// HTML exports a single self-contained page styled with the current theme
+type HTML struct{}
+
+func (HTML) Format() string    { return "html" }
+func (HTML) Extension() string { return "html" }
+
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+type htmlPalette struct {
+	Background, Panel, Element, Border  string
+	Text, Muted, Primary, Accent, Error string
+	Added, AddedBg, Removed, RemovedBg  string
+	Hunk, Code                          string
+}
+
+type htmlBlock struct {
+	Prompt string
+	Text   template.HTML
+	File   string
+	Tool   *htmlTool
+	Error  string
+}
+
+type htmlTool struct {
+	tool
+	InputJSON  string
+	DiffLines  []htmlDiffLine
+	ShowOutput bool
+	Failed     bool
+}
+
+type htmlDiffLine struct {
+	Class string
+	Text  string
+}
+
+type htmlMessage struct {
+	Role   string
+	User   bool
+	Time   string
+	Blocks []htmlBlock
+}
+
+type htmlPage struct {
+	Title    string
+	Session  opencode.Session
+	Palette  htmlPalette
+	Messages []htmlMessage
+}
+
+func (HTML) Export(w io.Writer, session opencode.Session, messages []app.Message) error {
+	page := htmlPage{
+		Title:   session.Title,
+		Session: session,
+		Palette: currentPalette(),
+	}
+	if page.Title == "" {
+		page.Title = "Conversation History"
+	}
+
+	for _, message := range messages {
+		role, timestamp := roleAndTime(message.Info)
+		if role == "" {
+			continue
+		}
+		_, user := message.Info.(opencode.UserMessage)
+		rendered := htmlMessage{Role: role, User: user, Time: timestamp.Format("2006-01-02 15:04:05")}
+
+		for _, part := range message.Parts {
+			switch p := part.(type) {
+			case opencode.TextPart:
+				if p.Synthetic {
+					continue
+				}
+				// prompts are shown as typed, responses are rendered as markdown
+				if user {
+					rendered.Blocks = append(rendered.Blocks, htmlBlock{Prompt: p.Text})
+					continue
+				}
+				var text bytes.Buffer
+				if err := markdown.Convert([]byte(p.Text), &text); err != nil {
+					return err
+				}
+				rendered.Blocks = append(rendered.Blocks, htmlBlock{Text: template.HTML(text.String())})
+			case opencode.FilePart:
+				rendered.Blocks = append(rendered.Blocks, htmlBlock{File: p.Filename})
+			case opencode.ToolPart:
+				t := newTool(p)
+				rendered.Blocks = append(rendered.Blocks, htmlBlock{Tool: &htmlTool{
+					tool:       t,
+					InputJSON:  t.inputJSON(),
+					DiffLines:  diffLines(t.Diff),
+					ShowOutput: t.showOutput(),
+					Failed:     t.Status == opencode.ToolPartStateStatusError,
+				}})
+			}
+		}
+		if err := messageError(message.Info); err != "" {
+			rendered.Blocks = append(rendered.Blocks, htmlBlock{Error: err})
+		}
+		page.Messages = append(page.Messages, rendered)
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+func diffLines(diff string) []htmlDiffLine {
+	if diff == "" {
+		return nil
+	}
+	lines := []htmlDiffLine{}
+	for line := range strings.SplitSeq(strings.TrimRight(diff, "\n"), "\n") {
+		class := ""
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "Index:"), strings.HasPrefix(line, "==="):
+			class = "meta"
+		case strings.HasPrefix(line, "@@"):
+			class = "hunk"
+		case strings.HasPrefix(line, "+"):
+			class = "added"
+		case strings.HasPrefix(line, "-"):
+			class = "removed"
+		}
+		lines = append(lines, htmlDiffLine{Class: class, Text: line})
+	}
+	return lines
+}
+
+// currentPalette resolves the theme colors for the terminal background, so
+// the page looks like the TUI it was exported from
+func currentPalette() htmlPalette {
+	t := theme.CurrentTheme()
+	if t == nil {
+		return htmlPalette{
+			Background: "#0a0a0a", Panel: "#141414", Element: "#1e1e1e", Border: "#484848",
+			Text: "#eeeeee", Muted: "#808080", Primary: "#fab283", Accent: "#9d7cd8", Error: "#e06c75",
+			Added: "#4fd6be", AddedBg: "#20303b", Removed: "#c53b53", RemovedBg: "#37222c",
+			Hunk: "#828bb8", Code: "#7fd88f",
+		}
+	}
+	dark := styles.Terminal == nil || styles.Terminal.BackgroundIsDark
+	hex := func(c compat.AdaptiveColor, fallback string) string {
+		resolved := c.Light
+		if dark {
+			resolved = c.Dark
+		}
+		if resolved == nil {
+			return fallback
+		}
+		r, g, b, a := resolved.RGBA()
+		if a == 0 {
+			return fallback
+		}
+		return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+	}
+	background := hex(t.Background(), "#0a0a0a")
+	if !dark {
+		background = hex(t.Background(), "#ffffff")
+	}
+	return htmlPalette{
+		Background: background,
+		Panel:      hex(t.BackgroundPanel(), background),
+		Element:    hex(t.BackgroundElement(), background),
+		Border:     hex(t.Border(), "#484848"),
+		Text:       hex(t.Text(), "#eeeeee"),
+		Muted:      hex(t.TextMuted(), "#808080"),
+		Primary:    hex(t.Primary(), "#fab283"),
+		Accent:     hex(t.Accent(), "#9d7cd8"),
+		Error:      hex(t.Error(), "#e06c75"),
+		Added:      hex(t.DiffAdded(), "#4fd6be"),
+		AddedBg:    hex(t.DiffAddedBg(), background),
+		Removed:    hex(t.DiffRemoved(), "#c53b53"),
+		RemovedBg:  hex(t.DiffRemovedBg(), background),
+		Hunk:       hex(t.DiffHunkHeader(), "#828bb8"),
+		Code:       hex(t.MarkdownCode(), "#7fd88f"),
+	}
+}
+
+var htmlTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+:root {
+  --background: {{.Palette.Background}}; --panel: {{.Palette.Panel}}; --element: {{.Palette.Element}};
+  --border: {{.Palette.Border}}; --text: {{.Palette.Text}}; --muted: {{.Palette.Muted}};
+  --primary: {{.Palette.Primary}}; --accent: {{.Palette.Accent}}; --error: {{.Palette.Error}};
+  --added: {{.Palette.Added}}; --added-bg: {{.Palette.AddedBg}};
+  --removed: {{.Palette.Removed}}; --removed-bg: {{.Palette.RemovedBg}};
+  --hunk: {{.Palette.Hunk}}; --code: {{.Palette.Code}};
+}
+body { background: var(--background); color: var(--text); font: 15px/1.6 ui-sans-serif, system-ui, sans-serif; margin: 0; }
+main { max-width: 860px; margin: 0 auto; padding: 32px 16px; }
+header h1 { margin: 0 0 4px; }
+header p, .time { color: var(--muted); font-size: 13px; }
+a { color: var(--primary); }
+.message { background: var(--panel); border-left: 3px solid var(--border); margin: 20px 0; padding: 12px 20px; }
+.message.user { border-left-color: var(--primary); }
+.role { font-weight: 600; }
+.prompt { white-space: pre-wrap; margin: 12px 0; }
+pre, code { font: 13px/1.5 ui-monospace, SFMono-Regular, Menlo, monospace; }
+code { color: var(--code); }
+pre { background: var(--element); padding: 12px; overflow-x: auto; white-space: pre-wrap; }
+pre code { color: var(--text); }
+.attachment { color: var(--accent); }
+details.tool { background: var(--element); margin: 12px 0; padding: 8px 12px; }
+details.tool summary { cursor: pointer; color: var(--muted); }
+details.tool summary strong { color: var(--text); }
+details.tool pre { background: var(--panel); }
+.diff span { display: block; }
+.diff .added { color: var(--added); background: var(--added-bg); }
+.diff .removed { color: var(--removed); background: var(--removed-bg); }
+.diff .hunk, .diff .meta { color: var(--hunk); }
+ul.todos { list-style: none; padding-left: 4px; }
+.todo-completed { color: var(--muted); }
+.todo-cancelled { color: var(--muted); text-decoration: line-through; }
+.todo-in_progress { color: var(--primary); font-weight: 600; }
+.error { color: var(--error); border-left: 3px solid var(--error); padding-left: 12px; }
+</style>
+</head>
+<body>
+<main>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Session.ID}}<p>Session <code>{{.Session.ID}}</code>{{if .Session.Share.URL}}, shared at <a href="{{.Session.Share.URL}}">{{.Session.Share.URL}}</a>{{end}}</p>{{end}}
+</header>
+{{range .Messages}}
+<section class="message{{if .User}} user{{end}}">
+<div class="role">{{.Role}} <span class="time">{{.Time}}</span></div>
+{{range .Blocks}}
+{{if .Prompt}}<div class="prompt">{{.Prompt}}</div>{{end}}
+{{if .Text}}<div class="text">{{.Text}}</div>{{end}}
+{{if .File}}<p class="attachment">Attachment: <code>{{.File}}</code></p>{{end}}
+{{with .Tool}}
+<details class="tool"{{if or .DiffLines .Todos}} open{{end}}>
+<summary><strong>{{.Name}}</strong> {{if ne .Title .Name}}{{.Title}}{{end}}{{if .Failed}} <span class="error">failed</span>{{end}}</summary>
+{{if .InputJSON}}<pre><code>{{.InputJSON}}</code></pre>{{end}}
+{{if .DiffLines}}<pre class="diff"><code>{{range .DiffLines}}<span class="{{.Class}}">{{.Text}}</span>{{end}}</code></pre>{{end}}
+{{if .Content}}<p><code>{{.File}}</code></p><pre><code>{{.Content}}</code></pre>{{end}}
+{{if .Todos}}<ul class="todos">{{range .Todos}}<li class="todo-{{.Status}}">{{if eq .Status "completed"}}☑{{else}}☐{{end}} {{.Content}}</li>{{end}}</ul>{{end}}
+{{if .ShowOutput}}<pre><code>{{.Output}}</code></pre>{{end}}
+{{if and .Failed .Error}}<p class="error">{{.Error}}</p>{{end}}
+</details>
+{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{end}}
+</section>
+{{end}}
+</main>
+</body>
+</html>
+`))
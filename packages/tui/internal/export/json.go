@@ -0,0 +1,51 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+// DocumentVersion is bumped whenever the JSON export layout changes in a way
+// older readers cannot handle
+const DocumentVersion = 1
+
+// Document is the JSON export of a session. Messages are stored in the same
+// shape the server returns them, so they decode back to identical
+// app.Message values.
+type Document struct {
+	Version  int              `json:"version"`
+	Session  opencode.Session `json:"session"`
+	Messages []app.Message    `json:"messages"`
+}
+
+// JSON exports a lossless Document that can be imported again
+type JSON struct{}
+
+func (JSON) Format() string    { return "json" }
+func (JSON) Extension() string { return "json" }
+
+func (JSON) Export(w io.Writer, session opencode.Session, messages []app.Message) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Document{
+		Version:  DocumentVersion,
+		Session:  session,
+		Messages: messages,
+	})
+}
+
+// ReadDocument decodes a JSON export
+func ReadDocument(r io.Reader) (*Document, error) {
+	var document Document
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid export: %w", err)
+	}
+	if document.Version > DocumentVersion {
+		return nil, fmt.Errorf("export version %d is newer than the supported version %d", document.Version, DocumentVersion)
+	}
+	return &document, nil
+}
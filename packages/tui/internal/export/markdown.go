@@ -0,0 +1,127 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+// Markdown exports a readable transcript including tool calls with their
+// inputs and outputs, edit diffs and todo lists
+type Markdown struct{}
+
+func (Markdown) Format() string    { return "markdown" }
+func (Markdown) Extension() string { return "md" }
+
+func (Markdown) Export(w io.Writer, session opencode.Session, messages []app.Message) error {
+	var b strings.Builder
+
+	title := session.Title
+	if title == "" {
+		title = "Conversation History"
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if session.ID != "" {
+		fmt.Fprintf(&b, "Session `%s`", session.ID)
+		if session.Share.URL != "" {
+			fmt.Fprintf(&b, ", shared at %s", session.Share.URL)
+		}
+		b.WriteString("\n\n")
+	}
+
+	for _, message := range messages {
+		if markdown := MessageMarkdown(message); markdown != "" {
+			b.WriteString("---\n\n" + markdown)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MessageMarkdown renders a single message the way Markdown exports it,
+// starting with its role heading
+func MessageMarkdown(message app.Message) string {
+	role, timestamp := roleAndTime(message.Info)
+	if role == "" {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n*%s*\n\n", role, timestamp.Format("2006-01-02 15:04:05"))
+
+	for _, part := range message.Parts {
+		switch p := part.(type) {
+		case opencode.TextPart:
+			if p.Synthetic {
+				continue
+			}
+			b.WriteString(strings.TrimSpace(p.Text) + "\n\n")
+		case opencode.FilePart:
+			fmt.Fprintf(&b, "**Attachment:** `%s` (%s)\n\n", p.Filename, p.Mime)
+		case opencode.ToolPart:
+			writeMarkdownTool(&b, newTool(p))
+		}
+	}
+
+	if err := messageError(message.Info); err != "" {
+		fmt.Fprintf(&b, "> **Error:** %s\n\n", err)
+	}
+	return b.String()
+}
+
+func writeMarkdownTool(b *strings.Builder, t tool) {
+	fmt.Fprintf(b, "### Tool: %s\n\n", t.Name)
+	if t.Title != t.Name {
+		fmt.Fprintf(b, "%s\n\n", t.Title)
+	}
+	if input := t.inputJSON(); input != "" {
+		b.WriteString("**Input:**\n\n" + fence("json", input))
+	}
+	if t.Diff != "" {
+		b.WriteString(fence("diff", strings.TrimRight(t.Diff, "\n")))
+	}
+	if t.Content != "" {
+		fmt.Fprintf(b, "**%s:**\n\n", t.File)
+		b.WriteString(fence(language(t.File), strings.TrimRight(t.Content, "\n")))
+	}
+	for _, todo := range t.Todos {
+		switch todo.Status {
+		case "completed":
+			fmt.Fprintf(b, "- [x] %s\n", todo.Content)
+		case "cancelled":
+			fmt.Fprintf(b, "- [ ] ~~%s~~\n", todo.Content)
+		case "in_progress":
+			fmt.Fprintf(b, "- [ ] **%s**\n", todo.Content)
+		default:
+			fmt.Fprintf(b, "- [ ] %s\n", todo.Content)
+		}
+	}
+	if len(t.Todos) > 0 {
+		b.WriteString("\n")
+	}
+	if t.showOutput() {
+		b.WriteString("**Output:**\n\n" + fence("", t.Output))
+	}
+	if t.Status == opencode.ToolPartStateStatusError && t.Error != "" {
+		fmt.Fprintf(b, "> **Error:** %s\n\n", t.Error)
+	}
+}
+
+// fence wraps content in a code block whose fence is longer than any run of
+// backticks inside it
+func fence(language, content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	marker := strings.Repeat("`", max(3, longest+1))
+	return marker + language + "\n" + content + "\n" + marker + "\n\n"
+}
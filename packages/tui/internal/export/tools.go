@@ -0,0 +1,126 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/util"
+)
+
+type todo struct {
+	Content string
+	Status  string
+}
+
+// tool is the format independent view of a tool call
+type tool struct {
+	Name   string
+	Title  string
+	Status opencode.ToolPartStateStatus
+	Error  string
+	Input  map[string]any
+	Output string
+	// Diff is the unified diff of an edit
+	Diff string
+	// File and Content are set for writes
+	File    string
+	Content string
+	Todos   []todo
+}
+
+func newTool(part opencode.ToolPart) tool {
+	t := tool{
+		Name:   part.Tool,
+		Title:  part.State.Title,
+		Status: part.State.Status,
+		Error:  part.State.Error,
+		Output: strings.TrimRight(part.State.Output, "\n"),
+	}
+	t.Input, _ = part.State.Input.(map[string]any)
+	metadata, _ := part.State.Metadata.(map[string]any)
+
+	switch part.Tool {
+	case "edit":
+		t.Diff, _ = metadata["diff"].(string)
+	case "write":
+		t.File, _ = t.Input["filePath"].(string)
+		t.Content, _ = t.Input["content"].(string)
+	case "todowrite", "todoread":
+		items, _ := metadata["todos"].([]any)
+		for _, item := range items {
+			if entry, ok := item.(map[string]any); ok {
+				content, _ := entry["content"].(string)
+				status, _ := entry["status"].(string)
+				t.Todos = append(t.Todos, todo{Content: content, Status: status})
+			}
+		}
+	}
+	if t.Title == "" {
+		t.Title = t.Name
+	}
+	return t
+}
+
+// inputJSON renders the tool input, leaving out content already shown in
+// full elsewhere
+func (t tool) inputJSON() string {
+	if len(t.Input) == 0 {
+		return ""
+	}
+	input := map[string]any{}
+	for key, value := range t.Input {
+		if (t.Name == "write" && key == "content") || (t.Name == "todowrite" && key == "todos") {
+			continue
+		}
+		input[key] = value
+	}
+	encoded, err := json.MarshalIndent(input, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func (t tool) showOutput() bool {
+	// the diff, file content and todo list already describe the result
+	return t.Output != "" && t.Diff == "" && t.Content == "" && len(t.Todos) == 0
+}
+
+func roleAndTime(message opencode.MessageUnion) (string, time.Time) {
+	switch info := message.(type) {
+	case opencode.UserMessage:
+		return "User", time.UnixMilli(int64(info.Time.Created))
+	case opencode.AssistantMessage:
+		role := "Assistant"
+		if info.ModelID != "" {
+			role = fmt.Sprintf("Assistant (%s)", info.ModelID)
+		}
+		return role, time.UnixMilli(int64(info.Time.Created))
+	}
+	return "", time.Time{}
+}
+
+func messageError(message opencode.MessageUnion) string {
+	assistant, ok := message.(opencode.AssistantMessage)
+	if !ok {
+		return ""
+	}
+	switch err := assistant.Error.AsUnion().(type) {
+	case opencode.ProviderAuthError:
+		return err.Data.Message
+	case opencode.UnknownError:
+		return err.Data.Message
+	case opencode.AssistantMessageErrorMessageOutputLengthError:
+		return "Message output length exceeded"
+	case opencode.MessageAbortedError:
+		return "Request was aborted"
+	}
+	return ""
+}
+
+func language(path string) string {
+	return util.Extension(path)
+}
@@ -0,0 +1,344 @@
+// Package headless runs a single prompt against the server without a
+// terminal UI, printing the response to a writer. It is used by the
+// --headless flag for scripting and CI.
+package headless
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode/internal/app"
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case FormatText, FormatJSON:
+		return Format(value), nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected text or json", value)
+}
+
+// SessionError is returned when the server reports a session.error while the
+// prompt is running
+type SessionError struct {
+	Name    string
+	Message string
+}
+
+func (e *SessionError) Error() string {
+	if e.Message == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Message
+}
+
+// Run sends the prompt to a new session and writes the assistant response to
+// out until the session goes idle. The model is resolved the same way as in
+// the TUI unless the app already has one selected.
+func Run(ctx context.Context, a *app.App, prompt string, format Format, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if a.Provider == nil || a.Model == nil {
+		provider, model, err := a.ResolveModel(ctx)
+		if err != nil {
+			return err
+		}
+		a.Provider = provider
+		a.Model = model
+	}
+
+	events := make(chan tea.Msg, 64)
+	go app.SubscribeEvents(ctx, a.Client, func(msg tea.Msg) {
+		select {
+		case events <- msg:
+		case <-ctx.Done():
+		}
+	})
+
+	// wait for the stream so no event of the new session is missed
+	for connected := false; !connected; {
+		select {
+		case msg := <-events:
+			_, connected = msg.(app.EventStreamConnectedMsg)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	session, err := a.CreateSession(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	a.Session = session
+
+	// the parts of the prompt itself are streamed back too and left out
+	message := a.NewPrompt(prompt, nil)
+	p := newPrinter(format, out)
+	p.prompt = message.ID()
+	sent := make(chan error, 1)
+	go func() {
+		sent <- a.Prompt(ctx, message)
+	}()
+
+	for {
+		select {
+		case err := <-sent:
+			if err != nil {
+				return err
+			}
+		case msg := <-events:
+			switch msg := msg.(type) {
+			case opencode.EventListResponseEventMessagePartUpdated:
+				if msg.Properties.Part.SessionID == session.ID && msg.Properties.Part.MessageID != p.prompt {
+					p.part(msg.Properties.Part.AsUnion())
+				}
+			case opencode.EventListResponseEventPermissionUpdated:
+				if msg.Properties.SessionID != session.ID {
+					continue
+				}
+				// nobody is around to answer, so anything needing approval is refused
+				p.permission(msg.Properties)
+				_, err := a.Client.Permission.Respond(ctx, session.ID, msg.Properties.ID, opencode.PermissionRespondParams{
+					Response: opencode.F(opencode.PermissionRespondParamsResponseReject),
+				})
+				if err != nil {
+					slog.Error("Failed to reject permission", "error", err)
+				}
+			case opencode.EventListResponseEventSessionError:
+				if msg.Properties.SessionID != "" && msg.Properties.SessionID != session.ID {
+					continue
+				}
+				sessionErr := sessionError(msg.Properties.Error)
+				p.flush()
+				p.error(sessionErr)
+				return sessionErr
+			case opencode.EventListResponseEventSessionIdle:
+				if msg.Properties.SessionID != session.ID {
+					continue
+				}
+				p.flush()
+				p.done(session.ID)
+				return p.err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sessionError describes every variant of the error union the way the TUI
+// does, falling back to the error as sent by the server
+func sessionError(err opencode.EventListResponseEventSessionErrorPropertiesError) *SessionError {
+	info := app.SessionErrorInfo(err)
+	if info == nil {
+		name := string(err.Name)
+		if name == "" {
+			name = "UnknownError"
+		}
+		return &SessionError{Name: name, Message: err.JSON.RawJSON()}
+	}
+	message := info.Message
+	if message == "" {
+		message = info.Raw
+	}
+	return &SessionError{Name: info.Name, Message: message}
+}
+
+// printer writes parts as they stream in. Text is written incrementally in
+// the text format and once complete in the JSON format.
+type printer struct {
+	format Format
+	out    io.Writer
+	err    error
+	// prompt is the ID of the user message sending the prompt
+	prompt string
+	// written tracks how much of each text part has been output
+	written map[string]int
+	// order lists the text parts in the order they were first seen
+	order []string
+	texts map[string]opencode.TextPart
+	tools map[string]bool
+	// current is the text part being streamed, its line is still open
+	current string
+	started bool
+}
+
+func newPrinter(format Format, out io.Writer) *printer {
+	return &printer{
+		format:  format,
+		out:     out,
+		written: map[string]int{},
+		texts:   map[string]opencode.TextPart{},
+		tools:   map[string]bool{},
+	}
+}
+
+type record struct {
+	Type      string `json:"type"`
+	SessionID string `json:"sessionID,omitempty"`
+	MessageID string `json:"messageID,omitempty"`
+	PartID    string `json:"partID,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Input     any    `json:"input,omitempty"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+func (p *printer) write(s string) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = io.WriteString(p.out, s)
+}
+
+func (p *printer) record(r record) {
+	encoded, err := json.Marshal(r)
+	if err != nil {
+		p.err = err
+		return
+	}
+	p.write(string(encoded) + "\n")
+}
+
+// begin starts a new block of text output, separated from the previous one
+// by a blank line
+func (p *printer) begin() {
+	if p.current != "" {
+		p.write("\n")
+		p.current = ""
+	}
+	if p.started {
+		p.write("\n")
+	}
+	p.started = true
+}
+
+func (p *printer) part(part opencode.PartUnion) {
+	switch casted := part.(type) {
+	case opencode.TextPart:
+		if casted.Synthetic {
+			return
+		}
+		if _, ok := p.texts[casted.ID]; !ok {
+			p.order = append(p.order, casted.ID)
+		}
+		p.texts[casted.ID] = casted
+		if p.format == FormatText || casted.Time.End > 0 {
+			p.text(casted.ID)
+		}
+	case opencode.ToolPart:
+		status := casted.State.Status
+		if p.tools[casted.ID] ||
+			(status != opencode.ToolPartStateStatusCompleted && status != opencode.ToolPartStateStatusError) {
+			return
+		}
+		p.tools[casted.ID] = true
+		if p.format == FormatJSON {
+			p.record(record{
+				Type:      "tool",
+				MessageID: casted.MessageID,
+				PartID:    casted.ID,
+				Tool:      casted.Tool,
+				Status:    string(status),
+				Title:     casted.State.Title,
+				Input:     casted.State.Input,
+				Output:    casted.State.Output,
+				Error:     casted.State.Error,
+			})
+			return
+		}
+		p.begin()
+		if status == opencode.ToolPartStateStatusError {
+			p.write(fmt.Sprintf("[%s] failed: %s\n", casted.Tool, casted.State.Error))
+			return
+		}
+		title := casted.State.Title
+		if title == "" {
+			title = casted.Tool
+		}
+		p.write(fmt.Sprintf("[%s] %s\n", casted.Tool, title))
+		if output := strings.TrimRight(casted.State.Output, "\n"); output != "" {
+			p.write(output + "\n")
+		}
+	}
+}
+
+// text outputs what has not been written yet of a text part
+func (p *printer) text(partID string) {
+	part := p.texts[partID]
+	written := p.written[partID]
+	if len(part.Text) <= written {
+		return
+	}
+	p.written[partID] = len(part.Text)
+	if p.format == FormatJSON {
+		p.record(record{
+			Type:      "text",
+			MessageID: part.MessageID,
+			PartID:    part.ID,
+			Text:      part.Text,
+		})
+		return
+	}
+	if partID != p.current {
+		p.begin()
+		p.current = partID
+	}
+	p.write(part.Text[written:])
+}
+
+// flush outputs text parts that never reported completion and closes the
+// last line
+func (p *printer) flush() {
+	if p.format == FormatJSON {
+		for _, partID := range p.order {
+			if p.written[partID] == 0 {
+				p.text(partID)
+			}
+		}
+		return
+	}
+	if p.current != "" {
+		p.write("\n")
+		p.current = ""
+	}
+}
+
+func (p *printer) permission(permission app.Permission) {
+	if p.format == FormatJSON {
+		p.record(record{Type: "permission", Title: permission.Title, Status: "rejected"})
+		return
+	}
+	p.begin()
+	p.write(fmt.Sprintf("[permission] rejected: %s\n", permission.Title))
+}
+
+func (p *printer) error(err *SessionError) {
+	if p.format == FormatJSON {
+		p.record(record{Type: "error", Name: err.Name, Error: err.Message})
+	}
+}
+
+func (p *printer) done(sessionID string) {
+	if p.format == FormatJSON {
+		p.record(record{Type: "done", SessionID: sessionID})
+	}
+}
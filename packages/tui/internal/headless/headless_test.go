@@ -0,0 +1,183 @@
+package headless
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sst/opencode-sdk-go"
+	"github.com/sst/opencode-sdk-go/option"
+	"github.com/sst/opencode/internal/app"
+)
+
+// newTestServer stands in for the opencode server: the chat endpoint replays
+// the given events on the event stream before responding
+func newTestServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	stream := make(chan string, len(events))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /event", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case event := <-stream:
+				fmt.Fprintf(w, "data: %s\n\n", event)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("POST /session", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"ses_1","title":"test","version":"dev","time":{"created":1,"updated":1}}`)
+	})
+	mux.HandleFunc("POST /session/ses_1/message", func(w http.ResponseWriter, r *http.Request) {
+		// msg_1 in the events stands for the prompt sent by the client
+		var body struct {
+			MessageID string `json:"messageID"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid chat request: %v", err)
+		}
+		for _, event := range events {
+			stream <- strings.ReplaceAll(event, `"msg_1"`, strconv.Quote(body.MessageID))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_2","role":"assistant","sessionID":"ses_1"}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestApp(server *httptest.Server) *app.App {
+	return &app.App{
+		Client:   opencode.NewClient(option.WithBaseURL(server.URL), option.WithMaxRetries(0)),
+		Session:  &opencode.Session{},
+		Messages: []app.Message{},
+		Mode:     &opencode.Mode{Name: "build"},
+		Provider: &opencode.Provider{ID: "provider"},
+		Model:    &opencode.Model{ID: "model"},
+	}
+}
+
+func textEvent(messageID, partID, text string, end float64) string {
+	return fmt.Sprintf(
+		`{"type":"message.part.updated","properties":{"part":{"type":"text","id":%q,"messageID":%q,"sessionID":"ses_1","text":%q,"time":{"start":1,"end":%v}}}}`,
+		partID, messageID, text, end,
+	)
+}
+
+var exchange = []string{
+	`{"type":"message.updated","properties":{"info":{"id":"msg_1","role":"user","sessionID":"ses_1","time":{"created":1}}}}`,
+	textEvent("msg_1", "prt_1", "run the tests", 0),
+	`{"type":"message.updated","properties":{"info":{"id":"msg_2","role":"assistant","sessionID":"ses_1","time":{"created":1}}}}`,
+	textEvent("msg_2", "prt_2", "Running", 0),
+	textEvent("msg_2", "prt_2", "Running the tests", 2),
+	`{"type":"message.part.updated","properties":{"part":{"type":"tool","id":"prt_3","messageID":"msg_2","sessionID":"ses_1","callID":"call_1","tool":"bash","state":{"status":"completed","title":"go test","input":{"command":"go test"},"output":"ok\n","metadata":{},"time":{"start":1,"end":2}}}}}`,
+	textEvent("msg_2", "prt_4", "All tests pass.", 0),
+	`{"type":"session.idle","properties":{"sessionID":"ses_1"}}`,
+}
+
+func run(t *testing.T, format Format, events ...string) (string, error) {
+	t.Helper()
+	server := newTestServer(t, events...)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	err := Run(ctx, newTestApp(server), "run the tests", format, &out)
+	return out.String(), err
+}
+
+func TestRunText(t *testing.T) {
+	out, err := run(t, FormatText, exchange...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Running the tests\n\n[bash] go test\nok\n\nAll tests pass.\n"
+	if out != expected {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", out, expected)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	out, err := run(t, FormatJSON, exchange...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var records []record
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var r record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	types := []string{}
+	for _, r := range records {
+		types = append(types, r.Type)
+	}
+	if strings.Join(types, ",") != "text,tool,text,done" {
+		t.Fatalf("unexpected records: %v", types)
+	}
+	if records[0].Text != "Running the tests" || records[2].Text != "All tests pass." {
+		t.Errorf("unexpected text records: %+v, %+v", records[0], records[2])
+	}
+	if records[1].Tool != "bash" || records[1].Output != "ok\n" {
+		t.Errorf("unexpected tool record: %+v", records[1])
+	}
+	if records[3].SessionID != "ses_1" {
+		t.Errorf("expected the session ID in the done record, got %+v", records[3])
+	}
+}
+
+func TestRunSessionError(t *testing.T) {
+	_, err := run(t, FormatText,
+		textEvent("msg_2", "prt_2", "Partial", 0),
+		`{"type":"session.error","properties":{"sessionID":"ses_1","error":{"name":"ProviderAuthError","data":{"providerID":"provider","message":"invalid api key"}}}}`,
+	)
+	var sessionErr *SessionError
+	if !errors.As(err, &sessionErr) {
+		t.Fatalf("expected a session error, got %v", err)
+	}
+	if sessionErr.Name != "ProviderAuthError" || sessionErr.Message != "invalid api key" {
+		t.Errorf("unexpected session error: %+v", sessionErr)
+	}
+}
+
+func TestSessionErrorVariants(t *testing.T) {
+	tests := []struct {
+		raw     string
+		name    string
+		message string
+	}{
+		{`{"name":"ProviderAuthError","data":{"providerID":"p","message":"invalid api key"}}`, "ProviderAuthError", "invalid api key"},
+		{`{"name":"UnknownError","data":{"message":"overloaded"}}`, "UnknownError", "overloaded"},
+		{`{"name":"MessageOutputLengthError","data":{}}`, "MessageOutputLengthError", "The response hit the output token limit of the model"},
+		{`{"name":"MessageAbortedError","data":{}}`, "MessageAbortedError", "The request was interrupted"},
+		{`{"name":"RateLimitError","data":{"retry":10}}`, "RateLimitError", `{"name":"RateLimitError","data":{"retry":10}}`},
+	}
+	for _, test := range tests {
+		var event opencode.EventListResponse
+		raw := `{"type":"session.error","properties":{"sessionID":"ses_1","error":` + test.raw + `}}`
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			t.Fatal(err)
+		}
+		err := sessionError(event.AsUnion().(opencode.EventListResponseEventSessionError).Properties.Error)
+		if err.Name != test.name || err.Message != test.message {
+			t.Errorf("%s: unexpected session error %+v", test.name, err)
+		}
+	}
+}
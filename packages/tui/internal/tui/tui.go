@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/charmbracelet/bubbles/v2/key"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
 
 	"github.com/sst/opencode-sdk-go"
 	"github.com/sst/opencode/internal/app"
@@ -26,6 +28,7 @@ import (
 	"github.com/sst/opencode/internal/components/status"
 	"github.com/sst/opencode/internal/components/toast"
 	"github.com/sst/opencode/internal/config"
+	"github.com/sst/opencode/internal/export"
 	"github.com/sst/opencode/internal/layout"
 	"github.com/sst/opencode/internal/styles"
 	"github.com/sst/opencode/internal/theme"
@@ -57,6 +60,26 @@ const (
 const interruptDebounceTimeout = 1 * time.Second
 const exitDebounceTimeout = 1 * time.Second
 
+// queuePreviewLimit is the number of queued prompts listed above the editor
+const queuePreviewLimit = 3
+
+// serverSessionCommands act on the server session and are unavailable while
+// an imported transcript is shown
+var serverSessionCommands = []commands.CommandName{
+	commands.SessionShareCommand,
+	commands.SessionUnshareCommand,
+	commands.SessionInterruptCommand,
+	commands.SessionCompactCommand,
+	commands.SessionRetryCommand,
+	commands.SessionCompareCommand,
+	commands.MessagesRevertCommand,
+	commands.MessagesUnrevertCommand,
+	commands.MessagesEditCommand,
+	commands.MessagesReconcileCommand,
+	commands.SessionRenameCommand,
+	commands.SessionForkCommand,
+}
+
 type appModel struct {
 	width, height        int
 	app                  *app.App
@@ -106,6 +129,9 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	// keep the message stores of the background tabs current
+	a.app.UpdateTabs(msg)
+
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		keyString := msg.String()
@@ -136,6 +162,21 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, cmd
 		}
 
+		// Act on the selected message while the editor is empty
+		if index, parts, ok := a.messages.Selection(); ok &&
+			!a.app.IsLeaderSequence &&
+			a.editor.Value() == "" {
+			switch keyString {
+			case "enter":
+				a.modal = dialog.NewMessageActionsDialog(a.app, index, parts)
+				return a, nil
+			case "esc":
+				updated, cmd := a.messages.ClearSelection()
+				a.messages = updated.(chat.MessagesComponent)
+				return a, cmd
+			}
+		}
+
 		// 2. Check for commands that require leader
 		if a.app.IsLeaderSequence {
 			matches := a.app.Commands.Matches(msg, a.app.IsLeaderSequence)
@@ -277,10 +318,17 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tea.Suspend
 		}
 
-		// 10. Fallback to editor. This is for other characters like backspace, tab, etc.
+		// 10. Fallback to the transcript for scrolling keys, and to the editor
+		// for other characters like backspace, tab, etc.
+		if chat.IsTranscriptKey(msg) {
+			updatedMessages, cmd := a.messages.Update(msg)
+			a.messages = updatedMessages.(chat.MessagesComponent)
+			return a, cmd
+		}
 		updatedEditor, cmd := a.editor.Update(msg)
 		a.editor = updatedEditor.(chat.EditorComponent)
-		return a, cmd
+		cmds = append(cmds, cmd)
+		return a, tea.Batch(cmds...)
 	case tea.MouseWheelMsg:
 		if a.modal != nil {
 			u, cmd := a.modal.Update(msg)
@@ -311,10 +359,15 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case modal.CloseModalMsg:
 		a.editor.Focus()
 		var cmd tea.Cmd
+		_, closingPermissions := a.modal.(dialog.PermissionDialog)
 		if a.modal != nil {
 			cmd = a.modal.Close()
 		}
 		a.modal = nil
+		// surface permissions that arrived while another dialog was open
+		if !closingPermissions && len(a.app.Permissions) > 0 {
+			a.modal = dialog.NewPermissionDialog(a.app)
+		}
 		return a, cmd
 	case commands.ExecuteCommandMsg:
 		updated, cmd := a.executeCommand(commands.Command(msg))
@@ -330,8 +383,74 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, toast.NewErrorToast(msg.Error())
 	case app.SendMsg:
 		a.showCompletionDialog = false
+		if a.app.ReadOnly() {
+			return a, tea.Batch(
+				util.CmdHandler(app.SetEditorContentMsg{Text: msg.Text}),
+				toast.NewWarningToast("Imported transcripts are read-only, use /import <file> seed to continue in a new session"),
+			)
+		}
+		if a.app.IsCompacting() {
+			return a, toast.NewWarningToast("Wait for the session to be compacted, or interrupt to cancel")
+		}
+		// the sent prompt is no longer a draft
+		a.saveDraft()
+		if msg.Revert != "" {
+			return a, a.app.ResendPrompt(context.Background(), msg)
+		}
 		a.app, cmd = a.app.SendChatMessage(context.Background(), msg.Text, msg.Attachments)
 		cmds = append(cmds, cmd)
+	case app.PromptQueuedMsg:
+		a.app.QueuePrompt(msg.Prompt)
+	case dialog.PromptRecalledMsg:
+		a.editor.RecallPrompt(msg.Entry)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+		if dropped := len(msg.Entry.Attachments) - len(msg.Entry.ValidAttachments(util.CwdPath)); dropped > 0 {
+			cmds = append(cmds, toast.NewWarningToast(
+				fmt.Sprintf("%d attached file(s) no longer exist and were left out", dropped),
+			))
+		}
+	case app.RetryMsg:
+		if a.app.IsBusy() || a.app.IsCompacting() {
+			return a, toast.NewWarningToast("Wait for the agent to finish before retrying")
+		}
+		send, ok := a.app.RetryPrompt()
+		if !ok {
+			return a, toast.NewInfoToast("There is no prompt to retry")
+		}
+		retry := []tea.Cmd{}
+		if msg.Provider != nil && msg.Model != nil && (a.app.Provider == nil || a.app.Model == nil ||
+			msg.Provider.ID != a.app.Provider.ID || msg.Model.ID != a.app.Model.ID) {
+			retry = append(retry, util.CmdHandler(app.ModelSelectedMsg{Provider: *msg.Provider, Model: *msg.Model}))
+		}
+		return a, tea.Sequence(append(retry, util.CmdHandler(send))...)
+	case app.CompareMsg:
+		cmds = append(cmds, a.app.StartComparison(context.Background(), msg))
+	case app.ComparisonForkedMsg:
+		if strings.TrimSpace(a.editor.Value()) == msg.Comparison.Prompt {
+			a.editor.SetValue("")
+		}
+		comparisonDialog := dialog.NewComparisonDialog(a.app)
+		a.modal = comparisonDialog
+		cmds = append(cmds,
+			a.app.BeginComparison(context.Background(), msg),
+			comparisonDialog.Init(),
+		)
+	case app.EditPromptMsg:
+		a.editor.EditPrompt(msg)
+		updated, cmd := a.editor.Focus()
+		a.editor = updated.(chat.EditorComponent)
+		cmds = append(cmds, cmd)
+		index := slices.IndexFunc(a.app.Messages, func(message app.Message) bool {
+			return message.ID() == msg.MessageID
+		})
+		preview := a.app.PreviewRevert(index)
+		notice := fmt.Sprintf("Sending replaces this prompt and the %d message(s) after it", preview.Messages-1)
+		if preview.Snapshot != "" && len(preview.Files) > 0 {
+			notice += fmt.Sprintf(", restoring %d file(s)", len(preview.Files))
+		}
+		cmds = append(cmds, toast.NewInfoToast(notice, toast.WithTitle("Editing an earlier prompt")))
 	case app.SetEditorContentMsg:
 		// Set the editor content without sending
 		a.editor.SetValue(msg.Text)
@@ -340,15 +459,43 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	case dialog.CompletionDialogCloseMsg:
 		a.showCompletionDialog = false
+	case tea.FocusMsg:
+		a.app.Unfocused = false
+	case tea.BlurMsg:
+		a.app.Unfocused = true
 	case opencode.EventListResponseEventInstallationUpdated:
 		return a, toast.NewSuccessToast(
 			"opencode updated to "+msg.Properties.Version+", restart to apply.",
 			toast.WithTitle("New version installed"),
 		)
+	case opencode.EventListResponseEventPermissionUpdated:
+		a.app.AddPermission(msg.Properties)
+		if a.modal == nil {
+			a.modal = dialog.NewPermissionDialog(a.app)
+			return a, nil
+		}
+		if _, ok := a.modal.(dialog.PermissionDialog); !ok {
+			return a, toast.NewInfoToast(
+				"Permission requested: "+msg.Properties.Title,
+				toast.WithTitle("Permission required"),
+			)
+		}
+	case app.PermissionFailedMsg:
+		a.app.RestorePermission(msg.Permission)
+		if a.modal == nil {
+			a.modal = dialog.NewPermissionDialog(a.app)
+		}
+		return a, toast.NewErrorToast("Failed to respond to permission request, it is still pending")
 	case opencode.EventListResponseEventSessionDeleted:
+		a.app.RemovePermissions(msg.Properties.Info.ID)
+		delete(a.app.Queues, msg.Properties.Info.ID)
 		if a.app.Session != nil && msg.Properties.Info.ID == a.app.Session.ID {
 			a.app.Session = &opencode.Session{}
 			a.app.Messages = []app.Message{}
+			return a, tea.Batch(
+				util.CmdHandler(app.SessionClearedMsg{}),
+				toast.NewSuccessToast("Session deleted successfully"),
+			)
 		}
 		return a, toast.NewSuccessToast("Session deleted successfully")
 	case opencode.EventListResponseEventSessionUpdated:
@@ -358,77 +505,33 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case opencode.EventListResponseEventMessagePartUpdated:
 		slog.Info("message part updated", "message", msg.Properties.Part.MessageID, "part", msg.Properties.Part.ID)
 		if msg.Properties.Part.SessionID == a.app.Session.ID {
-			messageIndex := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
-				switch casted := m.Info.(type) {
-				case opencode.UserMessage:
-					return casted.ID == msg.Properties.Part.MessageID
-				case opencode.AssistantMessage:
-					return casted.ID == msg.Properties.Part.MessageID
-				}
-				return false
-			})
-			if messageIndex > -1 {
-				message := a.app.Messages[messageIndex]
-				partIndex := slices.IndexFunc(message.Parts, func(p opencode.PartUnion) bool {
-					switch casted := p.(type) {
-					case opencode.TextPart:
-						return casted.ID == msg.Properties.Part.ID
-					case opencode.FilePart:
-						return casted.ID == msg.Properties.Part.ID
-					case opencode.ToolPart:
-						return casted.ID == msg.Properties.Part.ID
-					case opencode.StepStartPart:
-						return casted.ID == msg.Properties.Part.ID
-					case opencode.StepFinishPart:
-						return casted.ID == msg.Properties.Part.ID
-					}
-					return false
-				})
-				if partIndex > -1 {
-					message.Parts[partIndex] = msg.Properties.Part.AsUnion()
-				}
-				if partIndex == -1 {
-					message.Parts = append(message.Parts, msg.Properties.Part.AsUnion())
-				}
-				a.app.Messages[messageIndex] = message
-			}
+			a.app.Messages = app.UpdatePart(a.app.Messages, msg.Properties.Part)
 		}
 	case opencode.EventListResponseEventMessageUpdated:
 		if msg.Properties.Info.SessionID == a.app.Session.ID {
-			matchIndex := slices.IndexFunc(a.app.Messages, func(m app.Message) bool {
-				switch casted := m.Info.(type) {
-				case opencode.UserMessage:
-					return casted.ID == msg.Properties.Info.ID
-				case opencode.AssistantMessage:
-					return casted.ID == msg.Properties.Info.ID
-				}
-				return false
-			})
+			a.app.Messages = app.UpdateMessage(a.app.Messages, msg.Properties.Info.AsUnion())
 
-			if matchIndex > -1 {
-				match := a.app.Messages[matchIndex]
-				a.app.Messages[matchIndex] = app.Message{
-					Info:  msg.Properties.Info.AsUnion(),
-					Parts: match.Parts,
-				}
-			}
-
-			if matchIndex == -1 {
-				a.app.Messages = append(a.app.Messages, app.Message{
-					Info:  msg.Properties.Info.AsUnion(),
-					Parts: []opencode.PartUnion{},
-				})
+			if assistant, ok := msg.Properties.Info.AsUnion().(opencode.AssistantMessage); ok && assistant.Time.Completed > 0 {
+				cmds = append(cmds, a.app.CheckContextBudget(), a.app.CheckAutoCompact())
 			}
 		}
+	case opencode.EventListResponseEventMessageRemoved:
+		if msg.Properties.SessionID == a.app.Session.ID {
+			a.app.RemoveMessage(msg.Properties.MessageID)
+		}
+	case opencode.EventListResponseEventSessionIdle:
+		cmds = append(cmds, a.app.Notify(msg), a.app.SendQueued(msg.Properties.SessionID))
 	case opencode.EventListResponseEventSessionError:
-		switch err := msg.Properties.Error.AsUnion().(type) {
-		case nil:
-		case opencode.ProviderAuthError:
-			slog.Error("Failed to authenticate with provider", "error", err.Data.Message)
-			return a, toast.NewErrorToast("Provider error: " + err.Data.Message)
-		case opencode.UnknownError:
-			slog.Error("Server error", "name", err.Name, "message", err.Data.Message)
-			return a, toast.NewErrorToast(err.Data.Message, toast.WithTitle(string(err.Name)))
+		cmds = append(cmds, a.app.Notify(msg))
+		info := app.SessionErrorInfo(msg.Properties.Error)
+		if info == nil || info.Aborted() {
+			break
+		}
+		slog.Error("Session error", "session", msg.Properties.SessionID, "name", info.Name, "message", info.Message)
+		// errors of the current session are shown inline, after the prompt
+		// that failed
+		if msg.Properties.SessionID != a.app.Session.ID {
+			cmds = append(cmds, toast.NewErrorToast(info.Message, toast.WithTitle(info.Title)))
 		}
 	case opencode.EventListResponseEventFileWatcherUpdated:
 		if a.fileViewer.HasFile() {
@@ -450,17 +553,102 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			},
 		}
 	case app.SessionSelectedMsg:
-		messages, err := a.app.ListMessages(context.Background(), msg.ID)
-		if err != nil {
-			slog.Error("Failed to list messages", "error", err.Error())
-			return a, toast.NewErrorToast("Failed to open session")
+		switching := msg.ID != a.app.Session.ID || a.app.ReadOnly()
+		if switching && a.app.Tab(msg.ID) != nil {
+			// open tabs are kept current from the event stream
+			a.saveDraft()
+			a.app.SwitchTab(msg.ID, msg)
+		} else {
+			messages, err := a.app.ListMessages(context.Background(), msg.ID)
+			if err != nil {
+				slog.Error("Failed to list messages", "error", err.Error())
+				return a, toast.NewErrorToast("Failed to open session")
+			}
+			if switching {
+				a.saveDraft()
+			}
+			a.app.ShowSession(msg, app.TrimReverted(messages, msg.Revert))
+		}
+		cmd = a.app.LoadForkSource(context.Background())
+		if switching {
+			a.restoreDraft()
+		}
+		return a, tea.Batch(util.CmdHandler(app.SessionLoadedMsg{}), cmd)
+	case app.ForkSourceLoadedMsg:
+		// a late answer for a session switched away from is dropped
+		if msg.ID == a.app.Session.Fork.SessionID {
+			a.app.ForkSource = msg
 		}
-		a.app.Session = msg
-		a.app.Messages = messages
-		return a, util.CmdHandler(app.SessionLoadedMsg{})
 	case app.SessionCreatedMsg:
 		a.app.Session = msg.Session
+		a.app.ImportedFrom = ""
+		a.app.OpenTab()
 		return a, util.CmdHandler(app.SessionLoadedMsg{})
+	case app.SessionImportedMsg:
+		a.saveDraft()
+		a.app.SaveTab()
+		a.app.Import(msg.Path, msg.Session, msg.Messages)
+		return a, tea.Batch(
+			util.CmdHandler(app.SessionLoadedMsg{}),
+			toast.NewInfoToast(
+				"Viewing read-only, use /import <file> seed to continue in a new session",
+				toast.WithTitle("Imported "+util.Relative(msg.Path)),
+			),
+		)
+	case app.CompactionTickMsg:
+		return a, a.app.CompactionTick(msg)
+	case app.SessionCompactedMsg:
+		cmds = append(cmds, a.app.FinishCompaction(msg), a.app.SendQueued(msg.SessionID))
+	case app.SessionRevertedMsg:
+		if msg.Session.ID != a.app.Session.ID {
+			break
+		}
+		a.app.Session = msg.Session
+		a.app.Messages = app.TrimReverted(a.app.Messages, msg.Session.Revert)
+		if msg.Resend != nil {
+			return a, tea.Sequence(
+				util.CmdHandler(app.SessionLoadedMsg{}),
+				util.CmdHandler(*msg.Resend),
+			)
+		}
+		return a, tea.Batch(
+			util.CmdHandler(app.SessionLoadedMsg{}),
+			toast.NewSuccessToast("Session reverted"),
+		)
+	case app.EventStreamConnectedMsg:
+		if msg.Reconnected {
+			cmds = append(cmds, a.app.ResyncSession(context.Background()), a.app.ResyncTabs(context.Background()))
+		}
+	case app.SessionResyncedMsg:
+		if a.app.Session.ID != msg.SessionID {
+			a.app.ResyncTab(msg)
+			break
+		}
+		if msg.Session == nil {
+			a.app.Session = &opencode.Session{}
+			a.app.Messages = []app.Message{}
+			return a, util.CmdHandler(app.SessionClearedMsg{})
+		}
+		drift := app.DiffMessages(a.app.Messages, msg.Messages)
+		a.app.Session = msg.Session
+		a.app.Messages = msg.Messages
+		return a, tea.Sequence(
+			util.CmdHandler(app.MessagesReconciledMsg{
+				SessionID: msg.SessionID,
+				Drift:     drift,
+				Requested: msg.Requested,
+			}),
+			util.CmdHandler(app.SessionLoadedMsg{}),
+		)
+	case app.MessagesReconciledMsg:
+		if msg.Requested {
+			cmds = append(cmds, toast.NewInfoToast(
+				"Messages "+msg.Drift.String(),
+				toast.WithTitle("Reconciled"),
+			))
+		} else if !msg.Drift.Empty() {
+			slog.Info("Reconciled messages after reconnect", "drift", msg.Drift.String())
+		}
 	case app.ModelSelectedMsg:
 		a.app.Provider = &msg.Provider
 		a.app.Model = &msg.Model
@@ -491,6 +679,13 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.editor.SetExitKeyInDebounce(false)
 	case dialog.FindSelectedMsg:
 		return a.openFile(msg.FilePath)
+	case dialog.DiffSelectedMsg:
+		a.fileViewer, cmd = a.fileViewer.SetFile(msg.FilePath, msg.Diff, true)
+		return a, cmd
+	case dialog.DiagnosticSelectedMsg:
+		return a.openFileAt(msg.FilePath, msg.Line)
+	case opencode.EventListResponseEventLspClientDiagnostics:
+		a.app.Diagnostics.Update(msg.Properties)
 	}
 
 	s, cmd := a.status.Update(msg)
@@ -560,6 +755,12 @@ func (a appModel) View() string {
 }
 
 func (a appModel) openFile(filepath string) (tea.Model, tea.Cmd) {
+	return a.openFileAt(filepath, -1)
+}
+
+// openFileAt opens a file scrolled to the zero based line, or at the top
+// when line is -1
+func (a appModel) openFileAt(filepath string, line int) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	response, err := a.app.Client.File.Read(
 		context.Background(),
@@ -576,6 +777,9 @@ func (a appModel) openFile(filepath string) (tea.Model, tea.Cmd) {
 		response.Content,
 		response.Type == "patch",
 	)
+	if line >= 0 {
+		a.fileViewer.ScrollToLine(line)
+	}
 	return a, cmd
 }
 
@@ -725,6 +929,17 @@ func (a appModel) chat() string {
 		overlayHeight := lipgloss.Height(overlay)
 		editorY := a.height - editorHeight + 1
 
+		mainLayout = layout.PlaceOverlay(
+			editorX,
+			editorY-overlayHeight,
+			overlay,
+			mainLayout,
+		)
+	} else if queued := a.app.QueuedPrompts(); len(queued) > 0 {
+		overlay := a.queue(queued, editorWidth)
+		overlayHeight := lipgloss.Height(overlay)
+		editorY := a.height - editorHeight + 1
+
 		mainLayout = layout.PlaceOverlay(
 			editorX,
 			editorY-overlayHeight,
@@ -736,11 +951,51 @@ func (a appModel) chat() string {
 	return mainLayout
 }
 
+// queue renders the prompts waiting to be sent, shown above the editor
+func (a appModel) queue(prompts []config.PromptEntry, width int) string {
+	t := theme.CurrentTheme()
+	base := styles.NewStyle().Foreground(t.Text()).Background(t.BackgroundElement())
+	muted := base.Foreground(t.TextMuted())
+	innerWidth := max(width-4, 1)
+
+	header := fmt.Sprintf("queued (%d)", len(prompts))
+	if a.app.Queue().Paused {
+		header += " paused"
+	}
+	lines := []string{base.Bold(true).Render(header) + muted.Render("  /queue to edit or remove")}
+	for i, prompt := range prompts {
+		if i == queuePreviewLimit {
+			lines = append(lines, muted.Render(fmt.Sprintf("+%d more", len(prompts)-i)))
+			break
+		}
+		text := strings.Join(strings.Fields(prompt.Text), " ")
+		if n := len(prompt.Attachments); n > 0 {
+			text += fmt.Sprintf(" (+%d files)", n)
+		}
+		line := fmt.Sprintf("%d. %s", i+1, text)
+		lines = append(lines, base.Render(truncate.StringWithTail(line, uint(innerWidth), "...")))
+	}
+	return base.
+		Width(width).
+		Padding(0, 2).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderLeft(true).
+		BorderRight(false).
+		BorderTop(false).
+		BorderBottom(false).
+		BorderForeground(t.Warning()).
+		BorderBackground(t.Background()).
+		Render(strings.Join(lines, "\n"))
+}
+
 func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	cmds := []tea.Cmd{
 		util.CmdHandler(commands.CommandExecutedMsg(command)),
 	}
+	if a.app.ReadOnly() && slices.Contains(serverSessionCommands, command.Name) {
+		return a, toast.NewWarningToast("Not available for imported transcripts")
+	}
 	switch command.Name {
 	case commands.AppHelpCommand:
 		helpDialog := dialog.NewHelpDialog(a.app)
@@ -803,12 +1058,82 @@ func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd)
 		if a.app.Session.ID == "" {
 			return a, nil
 		}
+		a.saveDraft()
+		a.app.SaveTab()
 		a.app.Session = &opencode.Session{}
 		a.app.Messages = []app.Message{}
+		a.app.ImportedFrom = ""
+		a.restoreDraft()
+		cmds = append(cmds, util.CmdHandler(app.SessionClearedMsg{}))
+	case commands.SessionTabNextCommand, commands.SessionTabPreviousCommand:
+		session := a.app.CycleTab(command.Name == commands.SessionTabNextCommand)
+		if session == nil {
+			return a, toast.NewInfoToast("No other session open, use /sessions to open one")
+		}
+		cmds = append(cmds, util.CmdHandler(app.SessionSelectedMsg(session)))
+	case commands.SessionTabCloseCommand:
+		if a.app.ActiveTab() == -1 {
+			return a, nil
+		}
+		next, _ := a.app.CloseTab(a.app.Session.ID)
+		if next != nil {
+			cmds = append(cmds, util.CmdHandler(app.SessionSelectedMsg(next)))
+			break
+		}
+		a.saveDraft()
+		a.app.Session = &opencode.Session{}
+		a.app.Messages = []app.Message{}
+		a.restoreDraft()
 		cmds = append(cmds, util.CmdHandler(app.SessionClearedMsg{}))
 	case commands.SessionListCommand:
 		sessionDialog := dialog.NewSessionDialog(a.app)
 		a.modal = sessionDialog
+		cmds = append(cmds, sessionDialog.Init())
+	case commands.SessionUsageCommand:
+		if a.app.Session.ID == "" {
+			return a, toast.NewInfoToast("No active session")
+		}
+		a.modal = dialog.NewUsageDialog(a.app)
+	case commands.SessionChildrenCommand:
+		if a.app.Session.ID == "" {
+			return a, toast.NewInfoToast("No active session")
+		}
+		a.modal = dialog.NewSubagentsDialog(a.app)
+	case commands.SessionParentCommand:
+		switch {
+		case a.app.Session.ParentID != "":
+			cmds = append(cmds, a.app.OpenSession(context.Background(), a.app.Session.ParentID))
+		case a.app.Session.Fork.SessionID != "":
+			cmds = append(cmds, a.app.OpenSession(context.Background(), a.app.Session.Fork.SessionID))
+		default:
+			return a, toast.NewInfoToast("Not in a subagent or forked session")
+		}
+	case commands.SessionForkCommand:
+		if a.app.Session.ID == "" {
+			return a, toast.NewInfoToast("No active session to fork")
+		}
+		if a.app.IsBusy() {
+			return a, toast.NewWarningToast("Wait for the agent to finish before forking")
+		}
+		a.modal = dialog.NewForkDialog(a.app)
+	case commands.SessionRenameCommand:
+		if a.app.Session.ID == "" {
+			return a, toast.NewInfoToast("No active session to rename")
+		}
+		if command.Args == "" {
+			// let the user edit the current title in place
+			return a, util.CmdHandler(app.SetEditorContentMsg{
+				Text: "/" + command.PrimaryTrigger() + " " + a.app.Session.Title,
+			})
+		}
+		title := command.Args
+		session, err := a.app.RenameSession(context.Background(), a.app.Session.ID, title)
+		if err != nil {
+			slog.Error("Failed to rename session", "error", err)
+			return a, toast.NewErrorToast("Failed to rename session: " + err.Error())
+		}
+		a.app.Session = session
+		cmds = append(cmds, toast.NewSuccessToast("Session renamed"))
 	case commands.SessionShareCommand:
 		if a.app.Session.ID == "" {
 			return a, nil
@@ -837,13 +1162,61 @@ func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd)
 			return a, nil
 		}
 		a.app.Cancel(context.Background(), a.app.Session.ID)
+		if len(a.app.QueuedPrompts()) > 0 {
+			// hold the queue back until the user chooses to keep or discard it
+			a.app.Queue().Paused = true
+			a.modal = dialog.NewQueueDialog(a.app, true)
+		}
 		return a, nil
 	case commands.SessionCompactCommand:
 		if a.app.Session.ID == "" {
 			return a, nil
 		}
-		// TODO: block until compaction is complete
-		a.app.CompactSession(context.Background())
+		if a.app.IsCompacting() {
+			return a, toast.NewInfoToast("The session is already being compacted")
+		}
+		if a.app.Compaction() != nil {
+			return a, toast.NewWarningToast("Wait for the other session to be compacted")
+		}
+		cmds = append(cmds, a.app.CompactSession(context.Background(), false))
+	case commands.SessionRetryCommand:
+		info := a.app.LastError()
+		if info == nil {
+			return a, toast.NewInfoToast("The last prompt did not fail")
+		}
+		a.modal = dialog.NewRetryDialog(a.app, info)
+	case commands.SessionCompareCommand:
+		prompt := command.Args
+		var attachments []opencode.FilePartParam
+		if prompt == "" {
+			entry := a.editor.Prompt()
+			prompt = strings.TrimSpace(entry.Text)
+			attachments = app.PromptSendMsg(entry).Attachments
+		}
+		if prompt == "" {
+			if a.app.Comparison == nil {
+				return a, toast.NewInfoToast("Type a prompt to compare, or use /compare <prompt>")
+			}
+			comparisonDialog := dialog.NewComparisonDialog(a.app)
+			a.modal = comparisonDialog
+			cmds = append(cmds, comparisonDialog.Init())
+			break
+		}
+		if a.app.IsBusy() || a.app.IsCompacting() {
+			return a, toast.NewWarningToast("Wait for the agent to finish before comparing")
+		}
+		a.modal = dialog.NewCompareDialog(a.app, prompt, attachments)
+	case commands.SessionAutoCompactCommand:
+		a.app.State.AutoCompact = !a.app.State.AutoCompact
+		a.app.SaveState()
+		if !a.app.State.AutoCompact {
+			return a, toast.NewInfoToast("Auto-compact disabled")
+		}
+		cmds = append(cmds, toast.NewInfoToast(fmt.Sprintf(
+			"Sessions are summarized once %.0f%% of the context window is used",
+			a.app.State.AutoCompactShare()*100,
+		), toast.WithTitle("Auto-compact enabled")))
+		cmds = append(cmds, a.app.CheckAutoCompact())
 	case commands.SessionExportCommand:
 		if a.app.Session.ID == "" {
 			return a, toast.NewErrorToast("No active session to export.")
@@ -855,30 +1228,44 @@ func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd)
 			return a, toast.NewInfoToast("No messages to export.")
 		}
 
-		// Format to Markdown
-		markdownContent := formatConversationToMarkdown(messages)
+		format, path := command.SplitArgs()
+		if format == "" {
+			format = "markdown"
+		}
+		exporter, err := export.Get(format)
+		if err != nil {
+			return a, toast.NewErrorToast(err.Error())
+		}
 
-		// Check if EDITOR is set
+		// Write straight to disk when a path is given or there is no editor
+		// to open the export in
 		editor := os.Getenv("EDITOR")
-		if editor == "" {
-			return a, toast.NewErrorToast("No EDITOR set, can't open editor")
+		if path != "" || editor == "" {
+			if path == "" {
+				path = a.app.Session.ID + "." + exporter.Extension()
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(util.CwdPath, path)
+			}
+			if err := exportSession(exporter, path, *a.app.Session, messages); err != nil {
+				slog.Error("Failed to export conversation", "error", err)
+				return a, toast.NewErrorToast("Failed to export conversation: " + err.Error())
+			}
+			return a, toast.NewSuccessToast("Exported to " + util.Relative(path))
 		}
 
 		// Create and write to temp file
-		tmpfile, err := os.CreateTemp("", "conversation-*.md")
+		tmpfile, err := os.CreateTemp("", "conversation-*."+exporter.Extension())
 		if err != nil {
 			slog.Error("Failed to create temp file", "error", err)
 			return a, toast.NewErrorToast("Failed to create temporary file.")
 		}
-
-		_, err = tmpfile.WriteString(markdownContent)
-		if err != nil {
+		tmpfile.Close()
+		if err := exportSession(exporter, tmpfile.Name(), *a.app.Session, messages); err != nil {
 			slog.Error("Failed to write to temp file", "error", err)
-			tmpfile.Close()
 			os.Remove(tmpfile.Name())
 			return a, toast.NewErrorToast("Failed to write conversation to file.")
 		}
-		tmpfile.Close()
 
 		// Open in editor
 		c := exec.Command(editor, tmpfile.Name())
@@ -894,6 +1281,45 @@ func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd)
 			return nil
 		})
 		cmds = append(cmds, cmd)
+	case commands.SessionImportCommand:
+		path, mode := command.SplitArgs()
+		if path == "" {
+			return a, toast.NewInfoToast("Usage: /import <file> [seed]")
+		}
+		if mode != "" && mode != "seed" {
+			return a, toast.NewErrorToast("Unknown import argument: " + mode)
+		}
+		seed := mode == "seed"
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(util.CwdPath, path)
+		}
+		document, err := importSession(path)
+		if err != nil {
+			slog.Error("Failed to import conversation", "error", err)
+			return a, toast.NewErrorToast("Failed to import conversation: " + err.Error())
+		}
+		if !seed {
+			cmds = append(cmds, util.CmdHandler(app.SessionImportedMsg{
+				Path:     path,
+				Session:  document.Session,
+				Messages: document.Messages,
+			}))
+			break
+		}
+		var transcript strings.Builder
+		if err := (export.Markdown{}).Export(&transcript, document.Session, document.Messages); err != nil {
+			return a, toast.NewErrorToast("Failed to import conversation: " + err.Error())
+		}
+		a.app, cmd = a.app.SeedSession(context.Background(), path, transcript.String())
+		cmds = append(cmds, cmd)
+	case commands.SessionPermissionsCommand:
+		if len(a.app.Permissions) == 0 {
+			return a, toast.NewInfoToast("No pending permissions")
+		}
+		a.modal = dialog.NewPermissionDialog(a.app)
+	case commands.DiagnosticsToggleCommand:
+		// the open dialog closes itself on the same key
+		a.modal = dialog.NewDiagnosticsDialog(a.app)
 	case commands.ToolDetailsCommand:
 		message := "Tool details are now visible"
 		if a.messages.ToolDetailsVisible() {
@@ -943,6 +1369,25 @@ func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd)
 		updated, cmd := a.editor.Newline()
 		a.editor = updated.(chat.EditorComponent)
 		cmds = append(cmds, cmd)
+	case commands.InputHistorySearchCommand:
+		historyDialog := dialog.NewHistoryDialog(a.app)
+		a.modal = historyDialog
+		cmds = append(cmds, historyDialog.Init())
+	case commands.InputQueueCommand:
+		if len(a.app.QueuedPrompts()) == 0 {
+			return a, toast.NewInfoToast("No prompts queued")
+		}
+		queueDialog := dialog.NewQueueDialog(a.app, false)
+		a.modal = queueDialog
+		cmds = append(cmds, queueDialog.Init())
+	case commands.MessagesPreviousCommand:
+		updated, cmd := a.messages.SelectPrevious()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
+	case commands.MessagesNextCommand:
+		updated, cmd := a.messages.SelectNext()
+		a.messages = updated.(chat.MessagesComponent)
+		cmds = append(cmds, cmd)
 	case commands.MessagesFirstCommand:
 		updated, cmd := a.messages.GotoTop()
 		a.messages = updated.(chat.MessagesComponent)
@@ -992,10 +1437,56 @@ func (a appModel) executeCommand(command commands.Command) (tea.Model, tea.Cmd)
 		a.app.State.MessagesRight = a.messagesRight
 		a.app.SaveState()
 	case commands.MessagesCopyCommand:
-		updated, cmd := a.messages.CopyLastMessage()
+		updated, cmd := a.messages.CopyMessage()
 		a.messages = updated.(chat.MessagesComponent)
 		cmds = append(cmds, cmd)
 	case commands.MessagesRevertCommand:
+		if a.app.Session.ID == "" {
+			return a, nil
+		}
+		if a.app.IsBusy() {
+			return a, toast.NewWarningToast("Wait for the agent to finish before reverting")
+		}
+		a.modal = dialog.NewRevertDialog(a.app)
+	case commands.MessagesEditCommand:
+		if a.app.Session.ID == "" {
+			return a, nil
+		}
+		index := len(a.app.Messages) - 1
+		if selected, _, ok := a.messages.Selection(); ok {
+			index = selected
+		}
+		for ; index >= 0; index-- {
+			if _, ok := a.app.Messages[index].Info.(opencode.UserMessage); ok {
+				return a, util.CmdHandler(app.EditPrompt(a.app.Messages[index]))
+			}
+		}
+		return a, toast.NewInfoToast("No prompt to edit")
+	case commands.MessagesUnrevertCommand:
+		if a.app.IsBusy() {
+			return a, toast.NewWarningToast("Wait for the agent to finish before unreverting")
+		}
+		cmds = append(cmds, a.app.UnrevertSession(context.Background()))
+	case commands.MessagesReconcileCommand:
+		if a.app.Session.ID == "" {
+			return a, nil
+		}
+		cmds = append(cmds, a.app.ReconcileMessages(context.Background()))
+	case commands.DebugCacheStatsCommand:
+		stats := a.messages.CacheStats()
+		return a, toast.NewInfoToast(
+			fmt.Sprintf(
+				"%d entries, %.1f/%.0f MB\n%d hits, %d misses (%.0f%%), %d evictions",
+				stats.Entries,
+				float64(stats.Bytes)/1024/1024,
+				float64(stats.MaxBytes)/1024/1024,
+				stats.Hits,
+				stats.Misses,
+				stats.HitRate()*100,
+				stats.Evictions,
+			),
+			toast.WithTitle("Render cache"),
+		)
 	case commands.AppExitCommand:
 		return a, tea.Quit
 	}
@@ -1034,47 +1525,54 @@ func NewModel(app *app.App) tea.Model {
 		fileViewer:           fileviewer.New(app),
 		messagesRight:        app.State.MessagesRight,
 	}
+	model.restoreDraft()
 
 	return model
 }
 
-func formatConversationToMarkdown(messages []app.Message) string {
-	var builder strings.Builder
-
-	builder.WriteString("# Conversation History\n\n")
-
-	for _, msg := range messages {
-		builder.WriteString("---\n\n")
-
-		var role string
-		var timestamp time.Time
+// saveDraft keeps the editor content as the draft of the current session
+func (a appModel) saveDraft() {
+	a.app.SaveDraft(a.editor.Prompt())
+}
 
-		switch info := msg.Info.(type) {
-		case opencode.UserMessage:
-			role = "User"
-			timestamp = time.UnixMilli(int64(info.Time.Created))
-		case opencode.AssistantMessage:
-			role = "Assistant"
-			timestamp = time.UnixMilli(int64(info.Time.Created))
-		default:
-			continue
-		}
+// restoreDraft replaces the editor content with the draft of the current
+// session, leaving it empty when there is none
+func (a appModel) restoreDraft() {
+	if draft := a.app.LoadDraft(); draft != nil {
+		a.editor.RecallPrompt(*draft)
+		return
+	}
+	a.editor.Clear()
+}
 
-		builder.WriteString(
-			fmt.Sprintf("**%s** (*%s*)\n\n", role, timestamp.Format("2006-01-02 15:04:05")),
-		)
+// SaveDraft keeps the unsent prompt of the final model when the program
+// exits, however it was asked to quit
+func SaveDraft(model tea.Model) {
+	switch a := model.(type) {
+	case appModel:
+		a.saveDraft()
+	case *appModel:
+		a.saveDraft()
+	}
+}
 
-		for _, part := range msg.Parts {
-			switch p := part.(type) {
-			case opencode.TextPart:
-				builder.WriteString(p.Text + "\n\n")
-			case opencode.FilePart:
-				builder.WriteString(fmt.Sprintf("[File: %s]\n\n", p.Filename))
-			case opencode.ToolPart:
-				builder.WriteString(fmt.Sprintf("[Tool: %s]\n\n", p.Tool))
-			}
-		}
+func importSession(path string) (*export.Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
+	return export.ReadDocument(file)
+}
 
-	return builder.String()
+func exportSession(exporter export.Exporter, path string, session opencode.Session, messages []app.Message) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Export(file, session, messages); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
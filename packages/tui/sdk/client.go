@@ -15,13 +15,14 @@ import (
 // interacting with the opencode API. You should not instantiate this client
 // directly, and instead use the [NewClient] method instead.
 type Client struct {
-	Options []option.RequestOption
-	Event   *EventService
-	App     *AppService
-	Find    *FindService
-	File    *FileService
-	Config  *ConfigService
-	Session *SessionService
+	Options    []option.RequestOption
+	Event      *EventService
+	App        *AppService
+	Find       *FindService
+	File       *FileService
+	Config     *ConfigService
+	Session    *SessionService
+	Permission *PermissionService
 }
 
 // DefaultClientOptions read from the environment (OPENCODE_BASE_URL). This should
@@ -49,6 +50,7 @@ func NewClient(opts ...option.RequestOption) (r *Client) {
 	r.File = NewFileService(opts...)
 	r.Config = NewConfigService(opts...)
 	r.Session = NewSessionService(opts...)
+	r.Permission = NewPermissionService(opts...)
 
 	return
 }
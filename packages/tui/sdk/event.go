@@ -46,6 +46,100 @@ func (r *EventService) ListStreaming(ctx context.Context, opts ...option.Request
 	return ssestream.NewStream[EventListResponse](ssestream.NewDecoder(raw), err)
 }
 
+type Diagnostic struct {
+	Message  string          `json:"message,required"`
+	Range    DiagnosticRange `json:"range,required"`
+	Severity float64         `json:"severity"`
+	Source   string          `json:"source"`
+	JSON     diagnosticJSON  `json:"-"`
+}
+
+// diagnosticJSON contains the JSON metadata for the struct [Diagnostic]
+type diagnosticJSON struct {
+	Message     apijson.Field
+	Range       apijson.Field
+	Severity    apijson.Field
+	Source      apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *Diagnostic) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r diagnosticJSON) RawJSON() string {
+	return r.raw
+}
+
+type DiagnosticRange struct {
+	End   DiagnosticRangeEnd   `json:"end,required"`
+	Start DiagnosticRangeStart `json:"start,required"`
+	JSON  diagnosticRangeJSON  `json:"-"`
+}
+
+// diagnosticRangeJSON contains the JSON metadata for the struct [DiagnosticRange]
+type diagnosticRangeJSON struct {
+	End         apijson.Field
+	Start       apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *DiagnosticRange) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r diagnosticRangeJSON) RawJSON() string {
+	return r.raw
+}
+
+type DiagnosticRangeEnd struct {
+	Character float64                `json:"character,required"`
+	Line      float64                `json:"line,required"`
+	JSON      diagnosticRangeEndJSON `json:"-"`
+}
+
+// diagnosticRangeEndJSON contains the JSON metadata for the struct
+// [DiagnosticRangeEnd]
+type diagnosticRangeEndJSON struct {
+	Character   apijson.Field
+	Line        apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *DiagnosticRangeEnd) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r diagnosticRangeEndJSON) RawJSON() string {
+	return r.raw
+}
+
+type DiagnosticRangeStart struct {
+	Character float64                  `json:"character,required"`
+	Line      float64                  `json:"line,required"`
+	JSON      diagnosticRangeStartJSON `json:"-"`
+}
+
+// diagnosticRangeStartJSON contains the JSON metadata for the struct
+// [DiagnosticRangeStart]
+type diagnosticRangeStartJSON struct {
+	Character   apijson.Field
+	Line        apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *DiagnosticRangeStart) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r diagnosticRangeStartJSON) RawJSON() string {
+	return r.raw
+}
+
 type EventListResponse struct {
 	// This field can have the runtime type of
 	// [EventListResponseEventLspClientDiagnosticsProperties],
@@ -217,14 +311,16 @@ func (r eventListResponseEventLspClientDiagnosticsJSON) RawJSON() string {
 func (r EventListResponseEventLspClientDiagnostics) implementsEventListResponse() {}
 
 type EventListResponseEventLspClientDiagnosticsProperties struct {
-	Path     string                                                   `json:"path,required"`
-	ServerID string                                                   `json:"serverID,required"`
-	JSON     eventListResponseEventLspClientDiagnosticsPropertiesJSON `json:"-"`
+	Diagnostics []Diagnostic                                             `json:"diagnostics,required"`
+	Path        string                                                   `json:"path,required"`
+	ServerID    string                                                   `json:"serverID,required"`
+	JSON        eventListResponseEventLspClientDiagnosticsPropertiesJSON `json:"-"`
 }
 
 // eventListResponseEventLspClientDiagnosticsPropertiesJSON contains the JSON
 // metadata for the struct [EventListResponseEventLspClientDiagnosticsProperties]
 type eventListResponseEventLspClientDiagnosticsPropertiesJSON struct {
+	Diagnostics apijson.Field
 	Path        apijson.Field
 	ServerID    apijson.Field
 	raw         string
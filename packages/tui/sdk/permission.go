@@ -0,0 +1,74 @@
+// File generated from our OpenAPI spec by Stainless. See CONTRIBUTING.md for details.
+
+package opencode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sst/opencode-sdk-go/internal/apijson"
+	"github.com/sst/opencode-sdk-go/internal/param"
+	"github.com/sst/opencode-sdk-go/internal/requestconfig"
+	"github.com/sst/opencode-sdk-go/option"
+)
+
+// PermissionService contains methods and other services that help with
+// interacting with the opencode API.
+//
+// Note, unlike clients, this service does not read variables from the environment
+// automatically. You should not instantiate this service directly, and instead use
+// the [NewPermissionService] method instead.
+type PermissionService struct {
+	Options []option.RequestOption
+}
+
+// NewPermissionService generates a new service that applies the given options to
+// each request. These options are applied after the parent client's options (if
+// there is one), and before any request-specific options.
+func NewPermissionService(opts ...option.RequestOption) (r *PermissionService) {
+	r = &PermissionService{}
+	r.Options = opts
+	return
+}
+
+// Respond to a pending permission request
+func (r *PermissionService) Respond(ctx context.Context, id string, permissionID string, body PermissionRespondParams, opts ...option.RequestOption) (res *bool, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	if permissionID == "" {
+		err = errors.New("missing required permissionID parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/permissions/%s", id, permissionID)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
+type PermissionRespondParams struct {
+	Response param.Field[PermissionRespondParamsResponse] `json:"response,required"`
+}
+
+func (r PermissionRespondParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type PermissionRespondParamsResponse string
+
+const (
+	PermissionRespondParamsResponseOnce   PermissionRespondParamsResponse = "once"
+	PermissionRespondParamsResponseAlways PermissionRespondParamsResponse = "always"
+	PermissionRespondParamsResponseReject PermissionRespondParamsResponse = "reject"
+)
+
+func (r PermissionRespondParamsResponse) IsKnown() bool {
+	switch r {
+	case PermissionRespondParamsResponseOnce, PermissionRespondParamsResponseAlways, PermissionRespondParamsResponseReject:
+		return true
+	}
+	return false
+}
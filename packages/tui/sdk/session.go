@@ -44,6 +44,30 @@ func (r *SessionService) New(ctx context.Context, opts ...option.RequestOption)
 	return
 }
 
+// Get a session
+func (r *SessionService) Get(ctx context.Context, id string, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodGet, path, nil, &res, opts...)
+	return
+}
+
+// Update session properties such as its title
+func (r *SessionService) Update(ctx context.Context, id string, body SessionUpdateParams, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPatch, path, body, &res, opts...)
+	return
+}
+
 // List all sessions
 func (r *SessionService) List(ctx context.Context, opts ...option.RequestOption) (res *[]Session, err error) {
 	opts = append(r.Options[:], opts...)
@@ -112,6 +136,30 @@ func (r *SessionService) Messages(ctx context.Context, id string, opts ...option
 	return
 }
 
+// Create a new session with the messages up to and including a message
+func (r *SessionService) Fork(ctx context.Context, id string, body SessionForkParams, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/fork", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
+// Revert the session to before a message, restoring files from its snapshot
+func (r *SessionService) Revert(ctx context.Context, id string, body SessionRevertParams, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/revert", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, body, &res, opts...)
+	return
+}
+
 // Share a session
 func (r *SessionService) Share(ctx context.Context, id string, opts ...option.RequestOption) (res *Session, err error) {
 	opts = append(r.Options[:], opts...)
@@ -136,6 +184,18 @@ func (r *SessionService) Summarize(ctx context.Context, id string, body SessionS
 	return
 }
 
+// Undo a revert and restore the files it replaced
+func (r *SessionService) Unrevert(ctx context.Context, id string, opts ...option.RequestOption) (res *Session, err error) {
+	opts = append(r.Options[:], opts...)
+	if id == "" {
+		err = errors.New("missing required id parameter")
+		return
+	}
+	path := fmt.Sprintf("session/%s/unrevert", id)
+	err = requestconfig.ExecuteNewRequest(ctx, http.MethodPost, path, nil, &res, opts...)
+	return
+}
+
 // Unshare the session
 func (r *SessionService) Unshare(ctx context.Context, id string, opts ...option.RequestOption) (res *Session, err error) {
 	opts = append(r.Options[:], opts...)
@@ -731,6 +791,7 @@ type Session struct {
 	Time     SessionTime   `json:"time,required"`
 	Title    string        `json:"title,required"`
 	Version  string        `json:"version,required"`
+	Fork     SessionFork   `json:"fork"`
 	ParentID string        `json:"parentID"`
 	Revert   SessionRevert `json:"revert"`
 	Share    SessionShare  `json:"share"`
@@ -743,6 +804,7 @@ type sessionJSON struct {
 	Time        apijson.Field
 	Title       apijson.Field
 	Version     apijson.Field
+	Fork        apijson.Field
 	ParentID    apijson.Field
 	Revert      apijson.Field
 	Share       apijson.Field
@@ -780,6 +842,28 @@ func (r sessionTimeJSON) RawJSON() string {
 	return r.raw
 }
 
+type SessionFork struct {
+	MessageID string          `json:"messageID,required"`
+	SessionID string          `json:"sessionID,required"`
+	JSON      sessionForkJSON `json:"-"`
+}
+
+// sessionForkJSON contains the JSON metadata for the struct [SessionFork]
+type sessionForkJSON struct {
+	MessageID   apijson.Field
+	SessionID   apijson.Field
+	raw         string
+	ExtraFields map[string]apijson.Field
+}
+
+func (r *SessionFork) UnmarshalJSON(data []byte) (err error) {
+	return apijson.UnmarshalRoot(data, r)
+}
+
+func (r sessionForkJSON) RawJSON() string {
+	return r.raw
+}
+
 type SessionRevert struct {
 	MessageID string            `json:"messageID,required"`
 	Part      float64           `json:"part,required"`
@@ -1634,6 +1718,23 @@ func (r SessionInitParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
 
+type SessionForkParams struct {
+	MessageID param.Field[string] `json:"messageID,required"`
+}
+
+func (r SessionForkParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
+type SessionRevertParams struct {
+	MessageID param.Field[string]  `json:"messageID,required"`
+	Part      param.Field[float64] `json:"part,required"`
+}
+
+func (r SessionRevertParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}
+
 type SessionSummarizeParams struct {
 	ModelID    param.Field[string] `json:"modelID,required"`
 	ProviderID param.Field[string] `json:"providerID,required"`
@@ -1642,3 +1743,11 @@ type SessionSummarizeParams struct {
 func (r SessionSummarizeParams) MarshalJSON() (data []byte, err error) {
 	return apijson.MarshalRoot(r)
 }
+
+type SessionUpdateParams struct {
+	Title param.Field[string] `json:"title"`
+}
+
+func (r SessionUpdateParams) MarshalJSON() (data []byte, err error) {
+	return apijson.MarshalRoot(r)
+}